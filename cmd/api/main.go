@@ -2,12 +2,15 @@ package main
 
 import (
 	"log"
+	"os"
 	"pos-backend/internal/config"
 	"pos-backend/internal/database"
+	"pos-backend/internal/domain"
 	"pos-backend/internal/handler"
 	"pos-backend/internal/repository"
 	"pos-backend/internal/router"
 	"pos-backend/internal/service"
+	_ "time/tzdata" // Store timezones must resolve even on images without zoneinfo
 
 	"github.com/joho/godotenv"
 )
@@ -27,43 +30,102 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Run auto migrations
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// "migrate <command>" runs schema migrations instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(db, os.Args[2:])
+		return
+	}
+
+	// "rebuild-summaries [from] [to]" recomputes the daily sales summaries
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-summaries" {
+		runRebuildSummariesCommand(db, os.Args[2:])
+		return
+	}
+
+	// Refuse to serve against a database that hasn't been migrated
+	if err := database.CheckSchemaVersion(db); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
 	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	storeRepo := repository.NewStoreRepository(db)
+	taxClassRepo := repository.NewTaxClassRepository(db)
 	productRepo := repository.NewProductRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
 	settingRepo := repository.NewSettingRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	deviceRepo := repository.NewDeviceRepository(db)
+	signingKeyRepo := repository.NewDeviceSigningKeyRepository(db)
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	heldOrderRepo := repository.NewHeldOrderRepository(db)
+	diningRepo := repository.NewDiningRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	modifierRepo := repository.NewModifierRepository(db)
+	recipeRepo := repository.NewRecipeRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	salesSummaryRepo := repository.NewSalesSummaryRepository(db)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWTSecret)
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo)
-	transactionService := service.NewTransactionService(transactionRepo, productRepo, db)
-	settingService := service.NewSettingService(settingRepo)
+	auditService := service.NewAuditService(auditLogRepo)
+	authService := service.NewAuthService(userRepo, auditService, cfg.JWTSecret, db)
+	userService := service.NewUserService(userRepo, auditService, db)
+	categoryService := service.NewCategoryService(categoryRepo, taxClassRepo, auditService, db)
+	storeService := service.NewStoreService(storeRepo, auditService, db)
+	taxClassService := service.NewTaxClassService(taxClassRepo, auditService, db)
+	settingService := service.NewSettingService(settingRepo, storeRepo, deviceRepo, auditService, db)
+	eventBus := service.NewEventBus()
+	stockAlertNotifier := service.StockAlertNotifiers{
+		service.NewLogStockAlertNotifier(),
+		service.NewEventBusStockAlertNotifier(eventBus),
+	}
+	reorderService := service.NewReorderService(productRepo, inventoryRepo, recipeRepo, settingService, stockAlertNotifier)
+	inventoryService := service.NewInventoryService(inventoryRepo, settingService, reorderService, auditService, db)
+	salesSummaryService := service.NewSalesSummaryService(salesSummaryRepo, settingService)
+	productService := service.NewProductService(productRepo, taxClassRepo, recipeRepo, inventoryService, reorderService, auditService, db)
+	modifierService := service.NewModifierService(modifierRepo, productRepo, auditService, db)
+	recipeService := service.NewRecipeService(recipeRepo, productRepo, auditService, db)
+	transactionService := service.NewTransactionService(transactionRepo, productRepo, deviceRepo, signingKeyRepo, quarantineRepo, heldOrderRepo, ticketRepo, modifierRepo, recipeRepo, inventoryService, reorderService, salesSummaryService, settingService, auditService, eventBus, db)
+	deviceService := service.NewDeviceService(deviceRepo, signingKeyRepo, storeRepo, settingService, auditService, db)
+	quarantineService := service.NewQuarantineService(quarantineRepo, transactionService, auditService, db)
+	heldOrderService := service.NewHeldOrderService(heldOrderRepo, modifierRepo, productRepo, recipeRepo, deviceRepo, transactionService, settingService, auditService, db)
+	diningService := service.NewDiningService(diningRepo, storeRepo, ticketRepo, auditService, db)
+	ticketService := service.NewTicketService(ticketRepo, diningRepo, modifierRepo, productRepo, deviceRepo, transactionService, auditService, db)
 
 	// Initialize default settings
-	if err := settingService.InitializeDefaultSettings(); err != nil {
+	if err := settingService.InitializeDefaultSettings(domain.Actor{Username: "system"}); err != nil {
 		log.Printf("Warning: Failed to initialize default settings: %v", err)
 	}
 
+	// First start after upgrading builds the summaries the reports read
+	if err := salesSummaryService.EnsureBuilt(); err != nil {
+		log.Printf("Warning: Failed to build daily sales summaries: %v", err)
+	}
+
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
+	storeHandler := handler.NewStoreHandler(storeService)
+	taxClassHandler := handler.NewTaxClassHandler(taxClassService)
 	productHandler := handler.NewProductHandler(productService)
-	transactionHandler := handler.NewTransactionHandler(transactionService)
-	reportsHandler := handler.NewReportsHandler(db)
+	modifierHandler := handler.NewModifierHandler(modifierService)
+	recipeHandler := handler.NewRecipeHandler(recipeService)
+	transactionHandler := handler.NewTransactionHandler(transactionService, settingService)
+	reportsHandler := handler.NewReportsHandler(db, settingService)
 	settingHandler := handler.NewSettingHandler(settingService)
-	dashboardHandler := handler.NewDashboardHandler(db)
+	dashboardHandler := handler.NewDashboardHandler(db, settingService, reorderService, eventBus)
+	auditHandler := handler.NewAuditHandler(auditService)
+	deviceHandler := handler.NewDeviceHandler(deviceService)
+	quarantineHandler := handler.NewQuarantineHandler(quarantineService)
+	heldOrderHandler := handler.NewHeldOrderHandler(heldOrderService)
+	diningHandler := handler.NewDiningHandler(diningService)
+	ticketHandler := handler.NewTicketHandler(ticketService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService, reorderService, settingService)
 
 	// Setup router
-	r := router.SetupRouter(cfg, authHandler, userHandler, categoryHandler, productHandler, transactionHandler, reportsHandler, settingHandler, dashboardHandler)
+	r := router.SetupRouter(cfg, authHandler, userHandler, categoryHandler, storeHandler, taxClassHandler, productHandler, modifierHandler, recipeHandler, transactionHandler, reportsHandler, settingHandler, dashboardHandler, auditHandler, deviceHandler, deviceService, quarantineHandler, heldOrderHandler, diningHandler, ticketHandler, inventoryHandler)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.ServerPort)
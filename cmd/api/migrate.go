@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"pos-backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Usage: migrate <command>
+
+Commands:
+  up              Apply all pending migrations
+  down [steps]    Revert the last applied migration, or the given number of steps
+  status          Show the current schema version and pending migrations
+  to <version>    Migrate up or down to an exact version (0 reverts everything)`
+
+// runMigrateCommand handles "migrate ..." so deployments can migrate with the same binary
+func runMigrateCommand(db *gorm.DB, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		if err := database.MigrateUp(db); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				log.Fatalf("Invalid number of steps: %s", args[1])
+			}
+			steps = n
+		}
+		if err := database.MigrateDown(db, steps); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "to":
+		if len(args) < 2 {
+			log.Fatal(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			log.Fatalf("Invalid version: %s", args[1])
+		}
+		if err := database.MigrateTo(db, uint(version)); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "status":
+		// Printed below for every command
+	default:
+		log.Fatal(migrateUsage)
+	}
+
+	status, err := database.GetMigrationStatus(db)
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+
+	fmt.Printf("Current version: %d (latest %d)\n", status.CurrentVersion, status.LatestVersion)
+	if status.Dirty {
+		fmt.Println("WARNING: database is marked dirty")
+	}
+	for _, migration := range status.Applied {
+		fmt.Printf("  [x] %06d_%s\n", migration.Version, migration.Name)
+	}
+	for _, migration := range status.Pending {
+		fmt.Printf("  [ ] %06d_%s\n", migration.Version, migration.Name)
+	}
+}
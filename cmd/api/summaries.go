@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+
+	"pos-backend/internal/database"
+	"pos-backend/internal/repository"
+	"pos-backend/internal/service"
+
+	"gorm.io/gorm"
+)
+
+const rebuildSummariesUsage = `Usage: rebuild-summaries [start_date] [end_date]
+
+Recomputes the daily sales summaries from transactions for the inclusive
+YYYY-MM-DD business dates given, or for all history when none are given.
+Run it for all history after changing the timezone or business_day_cutoff.`
+
+// runRebuildSummariesCommand handles "rebuild-summaries ..." for repairing or backfilling the report summaries
+func runRebuildSummariesCommand(db *gorm.DB, args []string) {
+	if len(args) > 2 {
+		log.Fatal(rebuildSummariesUsage)
+	}
+
+	startDate, endDate := "", ""
+	if len(args) > 0 {
+		startDate = args[0]
+	}
+	if len(args) > 1 {
+		endDate = args[1]
+	}
+
+	if err := database.CheckSchemaVersion(db); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
+	}
+
+	settingService := service.NewSettingService(
+		repository.NewSettingRepository(db),
+		repository.NewStoreRepository(db),
+		repository.NewDeviceRepository(db),
+		service.NewAuditService(repository.NewAuditLogRepository(db)),
+		db,
+	)
+	summaryService := service.NewSalesSummaryService(repository.NewSalesSummaryRepository(db), settingService)
+	if err := summaryService.Rebuild(startDate, endDate); err != nil {
+		log.Fatalf("Rebuilding summaries failed: %v", err)
+	}
+
+	log.Println("Daily sales summaries rebuilt")
+}
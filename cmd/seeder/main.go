@@ -11,6 +11,7 @@ import (
 	"pos-backend/internal/config"
 	"pos-backend/internal/database"
 	"pos-backend/internal/domain"
+	"pos-backend/pkg/money"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -31,9 +32,9 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Run migrations first
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Schema is managed by versioned migrations, see "pos-api migrate up"
+	if err := database.CheckSchemaVersion(db); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
 	}
 
 	fmt.Println("🌱 Starting product seeder...")
@@ -74,14 +75,14 @@ func main() {
 			continue
 		}
 
-		price, err := strconv.ParseFloat(record[4], 64)
+		price, err := money.Parse(record[4])
 		if err != nil {
 			log.Printf("Row %d: Invalid price '%s', skipping...", i+1, record[4])
 			errorCount++
 			continue
 		}
 
-		cost, err := strconv.ParseFloat(record[5], 64)
+		cost, err := money.Parse(record[5])
 		if err != nil {
 			log.Printf("Row %d: Invalid cost '%s', skipping...", i+1, record[5])
 			errorCount++
@@ -1,29 +1,311 @@
 package database
 
 import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
-	"pos-backend/internal/domain"
+	"sort"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running auto migrations...")
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
-	err := db.AutoMigrate(
-		&domain.User{},
-		&domain.Category{},
-		&domain.Product{},
-		&domain.Transaction{},
-		&domain.TransactionItem{},
-		&domain.InventoryMovement{},
-		&domain.Setting{},
-	)
+// migrationLockKey keeps two instances from migrating the same database at once
+const migrationLockKey = 7243000
 
+// Migration is one numbered, reversible schema change
+type Migration struct {
+	Version uint
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// MigrationStatus describes where the database is relative to the embedded migrations
+type MigrationStatus struct {
+	CurrentVersion uint        `json:"current_version"`
+	LatestVersion  uint        `json:"latest_version"`
+	Dirty          bool        `json:"dirty"`
+	Applied        []Migration `json:"-"`
+	Pending        []Migration `json:"-"`
+}
+
+// LoadMigrations reads NNNNNN_name.up.sql / NNNNNN_name.down.sql pairs embedded in the binary
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name: %s", fileName)
+		}
+		version, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", fileName, err)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: parts[1]}
+			byVersion[uint(version)] = migration
+		}
+		if direction == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" || migration.DownSQL == "" {
+			return nil, fmt.Errorf("migration %06d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration
+func MigrateUp(db *gorm.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return MigrateTo(db, migrations[len(migrations)-1].Version)
+}
+
+// MigrateDown reverts the given number of applied migrations
+func MigrateDown(db *gorm.DB, steps int) error {
+	status, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+
+	applied := status.Applied
+	if steps <= 0 || steps > len(applied) {
+		steps = len(applied)
+	}
+
+	var target uint
+	if steps < len(applied) {
+		target = applied[len(applied)-steps-1].Version
+	}
+	return MigrateTo(db, target)
+}
+
+// MigrateTo moves the schema up or down until it is exactly at version (0 means empty)
+func MigrateTo(db *gorm.DB, version uint) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && findMigration(migrations, version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	if err := ensureMigrationsTable(db); err != nil {
+		return err
+	}
+
+	for {
+		current, dirty, err := currentVersion(db)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("database is dirty at version %d, fix it manually before migrating", current)
+		}
+		if current == version {
+			return nil
+		}
+
+		if current < version {
+			next := nextMigration(migrations, current)
+			if next == nil {
+				return fmt.Errorf("no migration found after version %d", current)
+			}
+			if err := applyMigration(db, current, next.Version, next.UpSQL); err != nil {
+				return fmt.Errorf("migration %06d_%s up failed: %w", next.Version, next.Name, err)
+			}
+			log.Printf("Applied migration %06d_%s", next.Version, next.Name)
+			continue
+		}
+
+		migration := findMigration(migrations, current)
+		if migration == nil {
+			return fmt.Errorf("database is at version %d which this binary does not know about", current)
+		}
+		var previous uint
+		if prev := previousMigration(migrations, current); prev != nil {
+			previous = prev.Version
+		}
+		if err := applyMigration(db, current, previous, migration.DownSQL); err != nil {
+			return fmt.Errorf("migration %06d_%s down failed: %w", migration.Version, migration.Name, err)
+		}
+		log.Printf("Reverted migration %06d_%s", migration.Version, migration.Name)
+	}
+}
+
+// GetMigrationStatus reports the current version and which migrations are applied or pending
+func GetMigrationStatus(db *gorm.DB) (*MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := currentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{CurrentVersion: current, Dirty: dirty}
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	if len(migrations) > 0 {
+		status.LatestVersion = migrations[len(migrations)-1].Version
+	}
+
+	return status, nil
+}
+
+// CheckSchemaVersion returns an error unless every embedded migration has been applied
+func CheckSchemaVersion(db *gorm.DB) error {
+	status, err := GetMigrationStatus(db)
 	if err != nil {
 		return err
 	}
 
-	log.Println("Auto migrations completed successfully")
+	if status.Dirty {
+		return fmt.Errorf("database is dirty at version %d", status.CurrentVersion)
+	}
+	if status.CurrentVersion < status.LatestVersion {
+		return fmt.Errorf("database is at version %d but version %d is required, run \"migrate up\"", status.CurrentVersion, status.LatestVersion)
+	}
+	if status.CurrentVersion > status.LatestVersion {
+		return fmt.Errorf("database is at version %d which is newer than this binary (%d)", status.CurrentVersion, status.LatestVersion)
+	}
+
+	return nil
+}
+
+// Helper functions
+
+// ensureMigrationsTable uses the same layout as golang-migrate so either tool can be used
+func ensureMigrationsTable(db *gorm.DB) error {
+	return db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)").Error
+}
+
+func currentVersion(db *gorm.DB) (uint, bool, error) {
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	err := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&row).Error
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(row.Version), row.Dirty, nil
+}
+
+// applyMigration runs one step and records the new version in the same database transaction
+func applyMigration(db *gorm.DB, from, to uint, sql string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+
+		// Another instance may have migrated while we waited for the lock
+		current, _, err := currentVersion(tx)
+		if err != nil {
+			return err
+		}
+		if current != from {
+			return errors.New("schema version changed while migrating, retry")
+		}
+
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM schema_migrations").Error; err != nil {
+			return err
+		}
+		if to == 0 {
+			return nil
+		}
+		return tx.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)", to, false).Error
+	})
+}
+
+func findMigration(migrations []Migration, version uint) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+func nextMigration(migrations []Migration, version uint) *Migration {
+	for i := range migrations {
+		if migrations[i].Version > version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+func previousMigration(migrations []Migration, version uint) *Migration {
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version < version {
+			return &migrations[i]
+		}
+	}
 	return nil
 }
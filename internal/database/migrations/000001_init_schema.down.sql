@@ -1,23 +1,4 @@
--- Drop triggers
-DROP TRIGGER IF EXISTS update_users_updated_at ON users;
-DROP TRIGGER IF EXISTS update_categories_updated_at ON categories;
-DROP TRIGGER IF EXISTS update_products_updated_at ON products;
-DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions;
-
--- Drop function
-DROP FUNCTION IF EXISTS update_updated_at_column();
-
--- Drop indexes
-DROP INDEX IF EXISTS idx_products_category_id;
-DROP INDEX IF EXISTS idx_products_sku;
-DROP INDEX IF EXISTS idx_transactions_user_id;
-DROP INDEX IF EXISTS idx_transactions_code;
-DROP INDEX IF EXISTS idx_transactions_created_at;
-DROP INDEX IF EXISTS idx_transaction_items_transaction_id;
-DROP INDEX IF EXISTS idx_transaction_items_product_id;
-DROP INDEX IF EXISTS idx_inventory_movements_product_id;
-
--- Drop tables
+DROP TABLE IF EXISTS settings;
 DROP TABLE IF EXISTS inventory_movements;
 DROP TABLE IF EXISTS transaction_items;
 DROP TABLE IF EXISTS transactions;
@@ -1,114 +1,116 @@
+-- Baseline schema. Databases created by the old GORM AutoMigrate already have
+-- these tables, so every statement is written to be a no-op on them.
+
 -- Users table
 CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    username VARCHAR(100) UNIQUE NOT NULL,
-    email VARCHAR(255) UNIQUE NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
     password VARCHAR(255) NOT NULL,
     full_name VARCHAR(255) NOT NULL,
     role VARCHAR(50) NOT NULL DEFAULT 'cashier', -- admin, manager, cashier
     is_active BOOLEAN DEFAULT true,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
 );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
 
 -- Categories table
 CREATE TABLE IF NOT EXISTS categories (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
 );
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
 
 -- Products table
 CREATE TABLE IF NOT EXISTS products (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    category_id UUID,
     name VARCHAR(255) NOT NULL,
-    sku VARCHAR(100) UNIQUE NOT NULL,
+    sku VARCHAR(100) NOT NULL,
     description TEXT,
     price DECIMAL(15, 2) NOT NULL,
     cost DECIMAL(15, 2) DEFAULT 0,
-    stock INT DEFAULT 0,
-    min_stock INT DEFAULT 0,
+    stock BIGINT DEFAULT 0,
+    min_stock BIGINT DEFAULT 0,
+    stock_version BIGINT DEFAULT 0,
+    last_stock_update TIMESTAMPTZ,
     image_url VARCHAR(500),
     is_active BOOLEAN DEFAULT true,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
 );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at);
 
 -- Transactions table
 CREATE TABLE IF NOT EXISTS transactions (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    transaction_code VARCHAR(50) UNIQUE NOT NULL,
-    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    transaction_code VARCHAR(50) NOT NULL,
+    client_transaction_id VARCHAR(100), -- for offline sync idempotency
+    user_id UUID,
     total_amount DECIMAL(15, 2) NOT NULL,
     discount_amount DECIMAL(15, 2) DEFAULT 0,
     tax_amount DECIMAL(15, 2) DEFAULT 0,
     final_amount DECIMAL(15, 2) NOT NULL,
-    payment_method VARCHAR(50) NOT NULL, -- cash, card, qris, etc
+    payment_method VARCHAR(50) NOT NULL, -- cash, card, qris
     payment_status VARCHAR(50) DEFAULT 'pending', -- pending, completed, cancelled
     customer_name VARCHAR(255),
     notes TEXT,
-    synced BOOLEAN DEFAULT false, -- for offline sync
-    synced_at TIMESTAMP,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    synced BOOLEAN DEFAULT false,
+    synced_at TIMESTAMPTZ,
+    has_stock_issue BOOLEAN DEFAULT false,
+    stock_issue_details TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
 );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_transaction_code ON transactions(transaction_code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_client_transaction_id ON transactions(client_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at);
 
 -- Transaction items table
 CREATE TABLE IF NOT EXISTS transaction_items (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    transaction_id UUID REFERENCES transactions(id) ON DELETE CASCADE,
-    product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    transaction_id UUID NOT NULL,
+    product_id UUID,
     product_name VARCHAR(255) NOT NULL, -- denormalized for history
     product_price DECIMAL(15, 2) NOT NULL,
-    quantity INT NOT NULL,
+    quantity BIGINT NOT NULL,
     subtotal DECIMAL(15, 2) NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMPTZ
 );
 
 -- Inventory movements table
 CREATE TABLE IF NOT EXISTS inventory_movements (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL,
     movement_type VARCHAR(50) NOT NULL, -- in, out, adjustment
-    quantity INT NOT NULL,
+    quantity BIGINT NOT NULL,
     reference_type VARCHAR(50), -- transaction, purchase, adjustment
     reference_id UUID,
     notes TEXT,
-    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    user_id UUID,
+    created_at TIMESTAMPTZ
 );
 
--- Create indexes for better performance
-CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
-CREATE INDEX IF NOT EXISTS idx_products_sku ON products(sku);
-CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
-CREATE INDEX IF NOT EXISTS idx_transactions_code ON transactions(transaction_code);
-CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
-CREATE INDEX IF NOT EXISTS idx_transaction_items_transaction_id ON transaction_items(transaction_id);
-CREATE INDEX IF NOT EXISTS idx_transaction_items_product_id ON transaction_items(product_id);
-CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id ON inventory_movements(product_id);
-
--- Create updated_at trigger function
-CREATE OR REPLACE FUNCTION update_updated_at_column()
-RETURNS TRIGGER AS $$
-BEGIN
-    NEW.updated_at = CURRENT_TIMESTAMP;
-    RETURN NEW;
-END;
-$$ language 'plpgsql';
-
--- Add triggers for updated_at
-CREATE TRIGGER update_users_updated_at BEFORE UPDATE ON users
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_categories_updated_at BEFORE UPDATE ON categories
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_products_updated_at BEFORE UPDATE ON products
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_transactions_updated_at BEFORE UPDATE ON transactions
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+-- Settings table
+CREATE TABLE IF NOT EXISTS settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key VARCHAR(100) NOT NULL,
+    value TEXT,
+    category VARCHAR(50), -- store, tax, receipt, system
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settings_key ON settings(key);
+CREATE INDEX IF NOT EXISTS idx_settings_deleted_at ON settings(deleted_at);
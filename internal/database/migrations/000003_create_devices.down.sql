@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_device_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS device_id;
+DROP TABLE IF EXISTS devices;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    location VARCHAR(255),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- pending, active, revoked
+    pairing_code_hash VARCHAR(64),
+    pairing_code_expires_at TIMESTAMPTZ,
+    credential_hash VARCHAR(64),
+    paired_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ,
+    last_sync_at TIMESTAMPTZ,
+    last_sync_status VARCHAR(50), -- success, partial, failed
+    last_sync_count BIGINT DEFAULT 0,
+    revoked_at TIMESTAMPTZ,
+    revoked_by UUID,
+    created_by UUID,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_devices_pairing_code_hash ON devices(pairing_code_hash);
+CREATE INDEX IF NOT EXISTS idx_devices_deleted_at ON devices(deleted_at);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS device_id UUID;
+CREATE INDEX IF NOT EXISTS idx_transactions_device_id ON transactions(device_id);
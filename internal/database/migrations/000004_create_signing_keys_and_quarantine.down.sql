@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quarantined_transactions;
+DROP TABLE IF EXISTS device_signing_keys;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS device_signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id UUID NOT NULL,
+    algorithm VARCHAR(20) NOT NULL DEFAULT 'ed25519',
+    public_key VARCHAR(100) NOT NULL, -- base64
+    status VARCHAR(20) NOT NULL DEFAULT 'active', -- active, retired
+    retired_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_device_signing_keys_device_id ON device_signing_keys(device_id);
+
+CREATE TABLE IF NOT EXISTS quarantined_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id UUID,
+    user_id UUID,
+    client_transaction_id VARCHAR(100),
+    payload TEXT NOT NULL, -- original request as JSON
+    signature TEXT,
+    signing_key_id VARCHAR(100),
+    reason VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    review_notes TEXT,
+    transaction_id UUID,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_quarantined_transactions_device_id ON quarantined_transactions(device_id);
+CREATE INDEX IF NOT EXISTS idx_quarantined_transactions_client_transaction_id ON quarantined_transactions(client_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_quarantined_transactions_status ON quarantined_transactions(status);
@@ -0,0 +1,26 @@
+DROP INDEX IF EXISTS idx_transactions_client_transaction_id;
+CREATE UNIQUE INDEX idx_transactions_client_transaction_id ON transactions(client_transaction_id);
+
+DROP INDEX IF EXISTS idx_inventory_movements_reference;
+DROP INDEX IF EXISTS idx_inventory_movements_product_id;
+DROP INDEX IF EXISTS idx_transaction_items_product_id;
+DROP INDEX IF EXISTS idx_transaction_items_transaction_id;
+DROP INDEX IF EXISTS idx_transactions_status_created_at;
+DROP INDEX IF EXISTS idx_transactions_created_at;
+DROP INDEX IF EXISTS idx_transactions_user_id;
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE quarantined_transactions DROP CONSTRAINT IF EXISTS fk_quarantined_transactions_transaction;
+ALTER TABLE quarantined_transactions DROP CONSTRAINT IF EXISTS fk_quarantined_transactions_reviewed_by;
+ALTER TABLE quarantined_transactions DROP CONSTRAINT IF EXISTS fk_quarantined_transactions_user;
+ALTER TABLE quarantined_transactions DROP CONSTRAINT IF EXISTS fk_quarantined_transactions_device;
+ALTER TABLE device_signing_keys DROP CONSTRAINT IF EXISTS fk_device_signing_keys_device;
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS fk_devices_revoked_by;
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS fk_devices_created_by;
+ALTER TABLE inventory_movements DROP CONSTRAINT IF EXISTS fk_inventory_movements_user;
+ALTER TABLE inventory_movements DROP CONSTRAINT IF EXISTS fk_inventory_movements_product;
+ALTER TABLE transaction_items DROP CONSTRAINT IF EXISTS fk_transaction_items_product;
+ALTER TABLE transaction_items DROP CONSTRAINT IF EXISTS fk_transaction_items_transaction;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_device;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_user;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_category;
@@ -0,0 +1,130 @@
+-- Foreign keys were never created while GORM ran with
+-- DisableForeignKeyConstraintWhenMigrating. They are added NOT VALID so existing
+-- rows are not re-checked (old data may hold orphans), while every new write is.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_products_category') THEN
+        ALTER TABLE products ADD CONSTRAINT fk_products_category
+            FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transactions_user') THEN
+        ALTER TABLE transactions ADD CONSTRAINT fk_transactions_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transactions_device') THEN
+        ALTER TABLE transactions ADD CONSTRAINT fk_transactions_device
+            FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_items_transaction') THEN
+        ALTER TABLE transaction_items ADD CONSTRAINT fk_transaction_items_transaction
+            FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_items_product') THEN
+        ALTER TABLE transaction_items ADD CONSTRAINT fk_transaction_items_product
+            FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_inventory_movements_product') THEN
+        ALTER TABLE inventory_movements ADD CONSTRAINT fk_inventory_movements_product
+            FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_inventory_movements_user') THEN
+        ALTER TABLE inventory_movements ADD CONSTRAINT fk_inventory_movements_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_devices_created_by') THEN
+        ALTER TABLE devices ADD CONSTRAINT fk_devices_created_by
+            FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_devices_revoked_by') THEN
+        ALTER TABLE devices ADD CONSTRAINT fk_devices_revoked_by
+            FOREIGN KEY (revoked_by) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_device_signing_keys_device') THEN
+        ALTER TABLE device_signing_keys ADD CONSTRAINT fk_device_signing_keys_device
+            FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_quarantined_transactions_device') THEN
+        ALTER TABLE quarantined_transactions ADD CONSTRAINT fk_quarantined_transactions_device
+            FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_quarantined_transactions_user') THEN
+        ALTER TABLE quarantined_transactions ADD CONSTRAINT fk_quarantined_transactions_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_quarantined_transactions_reviewed_by') THEN
+        ALTER TABLE quarantined_transactions ADD CONSTRAINT fk_quarantined_transactions_reviewed_by
+            FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_quarantined_transactions_transaction') THEN
+        ALTER TABLE quarantined_transactions ADD CONSTRAINT fk_quarantined_transactions_transaction
+            FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL NOT VALID;
+    END IF;
+END $$;
+
+-- Indexes for foreign keys and the filters used by reports and the dashboard
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_status_created_at ON transactions(payment_status, created_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_items_transaction_id ON transaction_items(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_items_product_id ON transaction_items(product_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id ON inventory_movements(product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_reference ON inventory_movements(reference_type, reference_id);
+
+-- Online sales have no client transaction ID, so only enforce uniqueness when one is set
+DROP INDEX IF EXISTS idx_transactions_client_transaction_id;
+CREATE UNIQUE INDEX idx_transactions_client_transaction_id ON transactions(client_transaction_id)
+    WHERE client_transaction_id IS NOT NULL AND client_transaction_id <> '';
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS total_cost;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS unit_cost;
@@ -0,0 +1,11 @@
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS total_cost DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Older sales never captured their cost, the current product cost is the best estimate we have
+UPDATE transaction_items
+SET unit_cost = products.cost,
+    total_cost = products.cost * transaction_items.quantity
+FROM products
+WHERE products.id = transaction_items.product_id
+  AND transaction_items.unit_cost = 0
+  AND transaction_items.total_cost = 0;
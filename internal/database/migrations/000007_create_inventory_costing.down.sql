@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS inventory_cost_layers;
+DELETE FROM inventory_movements WHERE reference_type = 'opening' AND notes = 'Opening balance';
+DROP INDEX IF EXISTS idx_inventory_movements_product_created;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS total_cost;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS unit_cost;
@@ -0,0 +1,53 @@
+ALTER TABLE inventory_movements ADD COLUMN IF NOT EXISTS unit_cost DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE inventory_movements ADD COLUMN IF NOT EXISTS total_cost DECIMAL(15,2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_created ON inventory_movements(product_id, created_at);
+
+-- Movements recorded before costing existed are valued at the current product cost
+UPDATE inventory_movements
+SET unit_cost = products.cost,
+    total_cost = products.cost * inventory_movements.quantity
+FROM products
+WHERE products.id = inventory_movements.product_id
+  AND inventory_movements.unit_cost = 0
+  AND inventory_movements.total_cost = 0;
+
+-- Stock that was entered directly on the product has no movement yet, book it as an opening balance
+INSERT INTO inventory_movements (id, product_id, movement_type, quantity, unit_cost, total_cost, reference_type, notes, created_at)
+SELECT gen_random_uuid(), products.id, 'adjustment', products.stock - COALESCE(moved.quantity, 0),
+       products.cost, products.cost * (products.stock - COALESCE(moved.quantity, 0)),
+       'opening', 'Opening balance', COALESCE(products.created_at, NOW())
+FROM products
+LEFT JOIN (
+    SELECT product_id, SUM(quantity) AS quantity FROM inventory_movements GROUP BY product_id
+) moved ON moved.product_id = products.id
+WHERE products.deleted_at IS NULL
+  AND products.stock <> COALESCE(moved.quantity, 0);
+
+CREATE TABLE IF NOT EXISTS inventory_cost_layers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    movement_id UUID,
+    quantity BIGINT NOT NULL,
+    remaining_quantity BIGINT NOT NULL,
+    unit_cost DECIMAL(15,2) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_inventory_cost_layers_product_id ON inventory_cost_layers(product_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_cost_layers_open ON inventory_cost_layers(product_id, received_at) WHERE remaining_quantity > 0;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_inventory_cost_layers_product') THEN
+        ALTER TABLE inventory_cost_layers ADD CONSTRAINT fk_inventory_cost_layers_product
+            FOREIGN KEY (product_id) REFERENCES products(id);
+    END IF;
+END $$;
+
+-- Whatever is on hand today starts as a single layer at the current cost
+INSERT INTO inventory_cost_layers (id, product_id, quantity, remaining_quantity, unit_cost, received_at, created_at)
+SELECT gen_random_uuid(), products.id, products.stock, products.stock, products.cost, NOW(), NOW()
+FROM products
+WHERE products.deleted_at IS NULL
+  AND products.stock > 0
+  AND NOT EXISTS (SELECT 1 FROM inventory_cost_layers WHERE inventory_cost_layers.product_id = products.id);
@@ -0,0 +1,8 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_store;
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS fk_devices_store;
+DROP INDEX IF EXISTS idx_transactions_store_created;
+DROP INDEX IF EXISTS idx_transactions_store_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS store_id;
+DROP INDEX IF EXISTS idx_devices_store_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS store_id;
+DROP TABLE IF EXISTS stores;
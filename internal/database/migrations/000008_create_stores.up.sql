@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS stores (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    phone VARCHAR(50),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stores_code ON stores(code);
+CREATE INDEX IF NOT EXISTS idx_stores_deleted_at ON stores(deleted_at);
+
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS store_id UUID;
+CREATE INDEX IF NOT EXISTS idx_devices_store_id ON devices(store_id);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS store_id UUID;
+CREATE INDEX IF NOT EXISTS idx_transactions_store_id ON transactions(store_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_store_created ON transactions(store_id, created_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_devices_store') THEN
+        ALTER TABLE devices ADD CONSTRAINT fk_devices_store
+            FOREIGN KEY (store_id) REFERENCES stores(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transactions_store') THEN
+        ALTER TABLE transactions ADD CONSTRAINT fk_transactions_store
+            FOREIGN KEY (store_id) REFERENCES stores(id) ON DELETE SET NULL;
+    END IF;
+END $$;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_cancelled_by;
+DROP INDEX IF EXISTS idx_transactions_cancelled_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS cancellation_reason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS cancelled_by;
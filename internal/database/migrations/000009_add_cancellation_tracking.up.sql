@@ -0,0 +1,27 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cancelled_by UUID;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
+CREATE INDEX IF NOT EXISTS idx_transactions_cancelled_by ON transactions(cancelled_by);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transactions_cancelled_by') THEN
+        ALTER TABLE transactions ADD CONSTRAINT fk_transactions_cancelled_by
+            FOREIGN KEY (cancelled_by) REFERENCES users(id);
+    END IF;
+END $$;
+
+-- Earlier cancellations only exist in the audit trail, recover who cancelled them from there
+UPDATE transactions
+SET cancelled_by = cancel_log.actor_id,
+    cancelled_at = cancel_log.created_at
+FROM (
+    SELECT DISTINCT ON (entity_id) entity_id, actor_id, created_at
+    FROM audit_logs
+    WHERE action = 'cancel' AND entity_type = 'transaction'
+    ORDER BY entity_id, sequence DESC
+) cancel_log
+WHERE transactions.id::text = cancel_log.entity_id
+  AND transactions.payment_status = 'cancelled'
+  AND transactions.cancelled_at IS NULL
+  AND EXISTS (SELECT 1 FROM users WHERE users.id = cancel_log.actor_id);
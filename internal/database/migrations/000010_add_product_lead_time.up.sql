@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS lead_time_days INT NOT NULL DEFAULT 0;
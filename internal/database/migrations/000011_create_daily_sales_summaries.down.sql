@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS daily_product_sales;
+DROP TABLE IF EXISTS daily_sales_summaries;
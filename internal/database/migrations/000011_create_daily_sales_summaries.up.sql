@@ -0,0 +1,31 @@
+-- Per business day totals that closed-day reports read instead of scanning transactions.
+-- Sales without a store, and lines whose product is gone, are kept under the nil UUID.
+CREATE TABLE IF NOT EXISTS daily_sales_summaries (
+    business_date DATE NOT NULL,
+    store_id UUID NOT NULL,
+    payment_method VARCHAR(50) NOT NULL,
+    transaction_count BIGINT NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    revenue DECIMAL(15,2) NOT NULL DEFAULT 0,
+    items_sold BIGINT NOT NULL DEFAULT 0,
+    cancelled_count BIGINT NOT NULL DEFAULT 0,
+    cancelled_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (business_date, store_id, payment_method)
+);
+CREATE INDEX IF NOT EXISTS idx_daily_sales_summaries_store_date ON daily_sales_summaries(store_id, business_date);
+
+CREATE TABLE IF NOT EXISTS daily_product_sales (
+    business_date DATE NOT NULL,
+    store_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    revenue DECIMAL(15,2) NOT NULL DEFAULT 0,
+    cost DECIMAL(15,2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (business_date, store_id, product_id, product_name)
+);
+CREATE INDEX IF NOT EXISTS idx_daily_product_sales_product_date ON daily_product_sales(product_id, business_date);
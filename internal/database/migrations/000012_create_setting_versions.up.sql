@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS setting_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    restored_version BIGINT,
+    actor_id UUID,
+    actor_name VARCHAR(100),
+    changes TEXT,
+    snapshot TEXT,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_setting_versions_version ON setting_versions(version);
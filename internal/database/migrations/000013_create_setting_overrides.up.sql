@@ -0,0 +1,12 @@
+-- Store and device overrides of global settings; scope_id is a store or device ID depending on scope
+CREATE TABLE IF NOT EXISTS setting_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(20) NOT NULL,
+    scope_id UUID NOT NULL,
+    key VARCHAR(100) NOT NULL,
+    value TEXT,
+    category VARCHAR(50),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_setting_overrides_scope_key ON setting_overrides(scope, scope_id, key);
@@ -0,0 +1,14 @@
+ALTER TABLE transaction_items DROP CONSTRAINT IF EXISTS fk_transaction_items_tax_class;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_tax_class;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_tax_class;
+DROP INDEX IF EXISTS idx_transaction_items_tax_class_id;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS taxable_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS tax_class_id;
+DROP INDEX IF EXISTS idx_products_tax_class_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_class_id;
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,49 @@
+-- Tax classes products are sold under; rate is in percent
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    rate DECIMAL(7,4) NOT NULL DEFAULT 0,
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_classes_code ON tax_classes(code);
+CREATE INDEX IF NOT EXISTS idx_tax_classes_deleted_at ON tax_classes(deleted_at);
+
+INSERT INTO tax_classes (code, name, rate, inclusive, is_active, created_at, updated_at) VALUES
+    ('EXEMPT', 'Tax exempt', 0, FALSE, TRUE, NOW(), NOW()),
+    ('PPN', 'PPN', 11, FALSE, TRUE, NOW(), NOW()),
+    ('PB1', 'PB1 restaurant tax', 10, FALSE, TRUE, NOW(), NOW())
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_class_id UUID;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class_id UUID;
+CREATE INDEX IF NOT EXISTS idx_products_tax_class_id ON products(tax_class_id);
+
+-- Tax is computed and kept per line; sales made before tax classes carry none here
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_class_id UUID;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(7,4) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS taxable_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_transaction_items_tax_class_id ON transaction_items(tax_class_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_categories_tax_class') THEN
+        ALTER TABLE categories ADD CONSTRAINT fk_categories_tax_class
+            FOREIGN KEY (tax_class_id) REFERENCES tax_classes(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_products_tax_class') THEN
+        ALTER TABLE products ADD CONSTRAINT fk_products_tax_class
+            FOREIGN KEY (tax_class_id) REFERENCES tax_classes(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_items_tax_class') THEN
+        ALTER TABLE transaction_items ADD CONSTRAINT fk_transaction_items_tax_class
+            FOREIGN KEY (tax_class_id) REFERENCES tax_classes(id) ON DELETE SET NULL;
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS service_charge_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS rounding_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS service_charge_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS service_charge_rate;
@@ -0,0 +1,6 @@
+-- Service charge and cash rounding are kept apart from the discount and tax they sit between
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS service_charge_rate DECIMAL(7,4) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS service_charge_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS rounding_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS service_charge_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
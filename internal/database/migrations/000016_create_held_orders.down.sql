@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS held_order_items;
+DROP TABLE IF EXISTS held_orders;
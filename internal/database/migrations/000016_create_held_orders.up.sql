@@ -0,0 +1,57 @@
+-- Carts parked mid-sale, optionally reserving their stock until reserved_until
+CREATE TABLE IF NOT EXISTS held_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    label VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'held',
+    store_id UUID,
+    user_id UUID,
+    device_id UUID,
+    resumed_by UUID,
+    resumed_at TIMESTAMPTZ,
+    customer_name VARCHAR(255),
+    notes TEXT,
+    discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    reserve_stock BOOLEAN NOT NULL DEFAULT FALSE,
+    reserved_until TIMESTAMPTZ,
+    transaction_id UUID,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_held_orders_status ON held_orders(status);
+CREATE INDEX IF NOT EXISTS idx_held_orders_store_id ON held_orders(store_id);
+CREATE INDEX IF NOT EXISTS idx_held_orders_reserved_until ON held_orders(reserved_until);
+
+CREATE TABLE IF NOT EXISTS held_order_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    held_order_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    price DECIMAL(15,2) NOT NULL,
+    quantity INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_held_order_items_held_order_id ON held_order_items(held_order_id);
+CREATE INDEX IF NOT EXISTS idx_held_order_items_product_id ON held_order_items(product_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_orders_store') THEN
+        ALTER TABLE held_orders ADD CONSTRAINT fk_held_orders_store
+            FOREIGN KEY (store_id) REFERENCES stores(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_orders_user') THEN
+        ALTER TABLE held_orders ADD CONSTRAINT fk_held_orders_user
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_orders_transaction') THEN
+        ALTER TABLE held_orders ADD CONSTRAINT fk_held_orders_transaction
+            FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_order_items_held_order') THEN
+        ALTER TABLE held_order_items ADD CONSTRAINT fk_held_order_items_held_order
+            FOREIGN KEY (held_order_id) REFERENCES held_orders(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_order_items_product') THEN
+        ALTER TABLE held_order_items ADD CONSTRAINT fk_held_order_items_product
+            FOREIGN KEY (product_id) REFERENCES products(id);
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ticket_items;
+DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS dining_tables;
+DROP TABLE IF EXISTS dining_areas;
@@ -0,0 +1,100 @@
+-- Floor plan: areas of a store and the tables in them
+CREATE TABLE IF NOT EXISTS dining_areas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    store_id UUID,
+    name VARCHAR(100) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_dining_areas_store_id ON dining_areas(store_id);
+CREATE INDEX IF NOT EXISTS idx_dining_areas_deleted_at ON dining_areas(deleted_at);
+
+CREATE TABLE IF NOT EXISTS dining_tables (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    area_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    seats INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_dining_tables_area_id ON dining_tables(area_id);
+CREATE INDEX IF NOT EXISTS idx_dining_tables_deleted_at ON dining_tables(deleted_at);
+
+-- Open tabs, paid at the end as one transaction
+CREATE TABLE IF NOT EXISTS tickets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    table_id UUID,
+    store_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    guests INTEGER NOT NULL DEFAULT 0,
+    customer_name VARCHAR(255),
+    notes TEXT,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    opened_by UUID,
+    closed_at TIMESTAMPTZ,
+    transaction_id UUID,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_tickets_table_id ON tickets(table_id);
+CREATE INDEX IF NOT EXISTS idx_tickets_store_id ON tickets(store_id);
+CREATE INDEX IF NOT EXISTS idx_tickets_status ON tickets(status);
+-- At most one open ticket per table
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tickets_open_table ON tickets(table_id) WHERE status = 'open' AND table_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ticket_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL,
+    round INTEGER NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    price DECIMAL(15,2) NOT NULL,
+    quantity INTEGER NOT NULL,
+    notes TEXT,
+    kitchen_status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    sent_at TIMESTAMPTZ,
+    status_changed_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_ticket_items_ticket_id ON ticket_items(ticket_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_items_kitchen_status ON ticket_items(kitchen_status);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_dining_areas_store') THEN
+        ALTER TABLE dining_areas ADD CONSTRAINT fk_dining_areas_store
+            FOREIGN KEY (store_id) REFERENCES stores(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_dining_tables_area') THEN
+        ALTER TABLE dining_tables ADD CONSTRAINT fk_dining_tables_area
+            FOREIGN KEY (area_id) REFERENCES dining_areas(id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_tickets_table') THEN
+        ALTER TABLE tickets ADD CONSTRAINT fk_tickets_table
+            FOREIGN KEY (table_id) REFERENCES dining_tables(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_tickets_store') THEN
+        ALTER TABLE tickets ADD CONSTRAINT fk_tickets_store
+            FOREIGN KEY (store_id) REFERENCES stores(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_tickets_opened_by') THEN
+        ALTER TABLE tickets ADD CONSTRAINT fk_tickets_opened_by
+            FOREIGN KEY (opened_by) REFERENCES users(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_tickets_transaction') THEN
+        ALTER TABLE tickets ADD CONSTRAINT fk_tickets_transaction
+            FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_ticket_items_ticket') THEN
+        ALTER TABLE ticket_items ADD CONSTRAINT fk_ticket_items_ticket
+            FOREIGN KEY (ticket_id) REFERENCES tickets(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_ticket_items_product') THEN
+        ALTER TABLE ticket_items ADD CONSTRAINT fk_ticket_items_product
+            FOREIGN KEY (product_id) REFERENCES products(id);
+    END IF;
+END $$;
@@ -0,0 +1,7 @@
+ALTER TABLE ticket_items DROP COLUMN IF EXISTS modifiers;
+ALTER TABLE held_order_items DROP COLUMN IF EXISTS modifiers;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS modifier_amount;
+DROP TABLE IF EXISTS transaction_item_modifiers;
+DROP TABLE IF EXISTS product_modifier_groups;
+DROP TABLE IF EXISTS modifier_options;
+DROP TABLE IF EXISTS modifier_groups;
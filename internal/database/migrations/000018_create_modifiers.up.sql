@@ -0,0 +1,84 @@
+-- Modifier groups offered with products (milk type, extra shot, toppings)
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    min_selections INTEGER NOT NULL DEFAULT 0,
+    max_selections INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_deleted_at ON modifier_groups(deleted_at);
+
+CREATE TABLE IF NOT EXISTS modifier_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(15,2) NOT NULL DEFAULT 0,
+    ingredient_id UUID,
+    ingredient_quantity INTEGER NOT NULL DEFAULT 0,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_modifier_options_group_id ON modifier_options(group_id);
+CREATE INDEX IF NOT EXISTS idx_modifier_options_deleted_at ON modifier_options(deleted_at);
+
+CREATE TABLE IF NOT EXISTS product_modifier_groups (
+    product_id UUID NOT NULL,
+    modifier_group_id UUID NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, modifier_group_id)
+);
+CREATE INDEX IF NOT EXISTS idx_product_modifier_groups_group_id ON product_modifier_groups(modifier_group_id);
+
+-- Modifiers as sold, names and prices copied so later edits don't change history
+CREATE TABLE IF NOT EXISTS transaction_item_modifiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_item_id UUID NOT NULL,
+    modifier_option_id UUID,
+    group_name VARCHAR(100) NOT NULL,
+    option_name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(15,2) NOT NULL DEFAULT 0,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    ingredient_id UUID,
+    ingredient_quantity INTEGER NOT NULL DEFAULT 0,
+    ingredient_cost DECIMAL(15,2) NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_modifiers_item_id ON transaction_item_modifiers(transaction_item_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_modifiers_option_id ON transaction_item_modifiers(modifier_option_id);
+
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS modifier_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE held_order_items ADD COLUMN IF NOT EXISTS modifiers TEXT;
+ALTER TABLE ticket_items ADD COLUMN IF NOT EXISTS modifiers TEXT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_modifier_options_group') THEN
+        ALTER TABLE modifier_options ADD CONSTRAINT fk_modifier_options_group
+            FOREIGN KEY (group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_modifier_options_ingredient') THEN
+        ALTER TABLE modifier_options ADD CONSTRAINT fk_modifier_options_ingredient
+            FOREIGN KEY (ingredient_id) REFERENCES products(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_product_modifier_groups_product') THEN
+        ALTER TABLE product_modifier_groups ADD CONSTRAINT fk_product_modifier_groups_product
+            FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_product_modifier_groups_group') THEN
+        ALTER TABLE product_modifier_groups ADD CONSTRAINT fk_product_modifier_groups_group
+            FOREIGN KEY (modifier_group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_item_modifiers_item') THEN
+        ALTER TABLE transaction_item_modifiers ADD CONSTRAINT fk_transaction_item_modifiers_item
+            FOREIGN KEY (transaction_item_id) REFERENCES transaction_items(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_item_modifiers_option') THEN
+        ALTER TABLE transaction_item_modifiers ADD CONSTRAINT fk_transaction_item_modifiers_option
+            FOREIGN KEY (modifier_option_id) REFERENCES modifier_options(id) ON DELETE SET NULL;
+    END IF;
+END $$;
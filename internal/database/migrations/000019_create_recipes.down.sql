@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transaction_item_components;
+DROP TABLE IF EXISTS recipe_components;
+DROP TABLE IF EXISTS recipes;
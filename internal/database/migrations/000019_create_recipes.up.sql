@@ -0,0 +1,57 @@
+-- Bills of materials: selling a composite product takes its components from stock
+CREATE TABLE IF NOT EXISTS recipes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    yield_quantity INTEGER NOT NULL DEFAULT 1,
+    waste_percent DECIMAL(7,4) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipes_product_id ON recipes(product_id);
+
+CREATE TABLE IF NOT EXISTS recipe_components (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL,
+    component_id UUID NOT NULL,
+    quantity INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_recipe_components_recipe_id ON recipe_components(recipe_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_components_component_id ON recipe_components(component_id);
+
+-- Stock each sold line took from other products, restored when the sale is cancelled
+CREATE TABLE IF NOT EXISTS transaction_item_components (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_item_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL,
+    total_cost DECIMAL(15,2) NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_components_item_id ON transaction_item_components(transaction_item_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_components_product_id ON transaction_item_components(product_id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_recipes_product') THEN
+        ALTER TABLE recipes ADD CONSTRAINT fk_recipes_product
+            FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_recipe_components_recipe') THEN
+        ALTER TABLE recipe_components ADD CONSTRAINT fk_recipe_components_recipe
+            FOREIGN KEY (recipe_id) REFERENCES recipes(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_recipe_components_component') THEN
+        ALTER TABLE recipe_components ADD CONSTRAINT fk_recipe_components_component
+            FOREIGN KEY (component_id) REFERENCES products(id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_item_components_item') THEN
+        ALTER TABLE transaction_item_components ADD CONSTRAINT fk_transaction_item_components_item
+            FOREIGN KEY (transaction_item_id) REFERENCES transaction_items(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_transaction_item_components_product') THEN
+        ALTER TABLE transaction_item_components ADD CONSTRAINT fk_transaction_item_components_product
+            FOREIGN KEY (product_id) REFERENCES products(id);
+    END IF;
+END $$;
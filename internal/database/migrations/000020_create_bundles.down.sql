@@ -0,0 +1,4 @@
+ALTER TABLE transaction_item_components DROP COLUMN IF EXISTS revenue;
+ALTER TABLE transaction_item_components DROP COLUMN IF EXISTS list_value;
+DROP TABLE IF EXISTS bundle_items;
+ALTER TABLE products DROP COLUMN IF EXISTS is_bundle;
@@ -0,0 +1,27 @@
+-- Bundles are sold as one SKU and take their components from stock
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS bundle_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bundle_id UUID NOT NULL,
+    component_id UUID NOT NULL,
+    quantity INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_bundle_items_bundle_id ON bundle_items(bundle_id);
+CREATE INDEX IF NOT EXISTS idx_bundle_items_component_id ON bundle_items(component_id);
+
+-- A bundle's net sales are allocated to its components by their list value
+ALTER TABLE transaction_item_components ADD COLUMN IF NOT EXISTS list_value DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_item_components ADD COLUMN IF NOT EXISTS revenue DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_bundle_items_bundle') THEN
+        ALTER TABLE bundle_items ADD CONSTRAINT fk_bundle_items_bundle
+            FOREIGN KEY (bundle_id) REFERENCES products(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_bundle_items_component') THEN
+        ALTER TABLE bundle_items ADD CONSTRAINT fk_bundle_items_component
+            FOREIGN KEY (component_id) REFERENCES products(id);
+    END IF;
+END $$;
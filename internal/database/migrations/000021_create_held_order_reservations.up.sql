@@ -0,0 +1,31 @@
+-- Stock a held cart keeps out of other sales: bundle items, recipe components
+-- and modifier ingredients, as well as products sold as themselves
+CREATE TABLE IF NOT EXISTS held_order_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    held_order_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    quantity INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_held_order_reservations_held_order_id ON held_order_reservations(held_order_id);
+CREATE INDEX IF NOT EXISTS idx_held_order_reservations_product_id ON held_order_reservations(product_id);
+
+-- Carts held before this reserved their lines' own products
+INSERT INTO held_order_reservations (held_order_id, product_id, quantity)
+SELECT held_order_items.held_order_id, held_order_items.product_id, SUM(held_order_items.quantity)
+FROM held_order_items
+JOIN held_orders ON held_orders.id = held_order_items.held_order_id
+WHERE held_orders.status = 'held' AND held_orders.reserve_stock
+    AND NOT EXISTS (SELECT 1 FROM held_order_reservations WHERE held_order_reservations.held_order_id = held_orders.id)
+GROUP BY held_order_items.held_order_id, held_order_items.product_id;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_order_reservations_held_order') THEN
+        ALTER TABLE held_order_reservations ADD CONSTRAINT fk_held_order_reservations_held_order
+            FOREIGN KEY (held_order_id) REFERENCES held_orders(id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_held_order_reservations_product') THEN
+        ALTER TABLE held_order_reservations ADD CONSTRAINT fk_held_order_reservations_product
+            FOREIGN KEY (product_id) REFERENCES products(id);
+    END IF;
+END $$;
@@ -0,0 +1 @@
+ALTER TABLE setting_versions DROP COLUMN IF EXISTS override_snapshot;
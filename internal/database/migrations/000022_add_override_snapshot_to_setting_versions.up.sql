@@ -0,0 +1,2 @@
+-- Snapshot store and device overrides with each settings version
+ALTER TABLE setting_versions ADD COLUMN IF NOT EXISTS override_snapshot TEXT;
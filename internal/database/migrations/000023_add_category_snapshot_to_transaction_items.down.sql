@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transaction_items_category_id;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS category_name;
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS category_id;
@@ -0,0 +1,13 @@
+-- Keep the category a line was sold under so moving a product doesn't rewrite category reports
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS category_id UUID;
+ALTER TABLE transaction_items ADD COLUMN IF NOT EXISTS category_name VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_transaction_items_category_id ON transaction_items(category_id);
+
+-- Older sales never captured it, the product's current category is the best we have
+UPDATE transaction_items
+SET category_id = categories.id,
+    category_name = categories.name
+FROM products
+JOIN categories ON categories.id = products.category_id
+WHERE products.id = transaction_items.product_id
+  AND transaction_items.category_id IS NULL;
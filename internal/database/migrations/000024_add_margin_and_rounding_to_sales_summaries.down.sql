@@ -0,0 +1,12 @@
+ALTER TABLE daily_sales_summaries DROP COLUMN IF EXISTS rounding_loss;
+ALTER TABLE daily_sales_summaries DROP COLUMN IF EXISTS rounding_gain;
+ALTER TABLE daily_sales_summaries DROP COLUMN IF EXISTS rounded_count;
+
+-- Rows that differ only by category can't share the old key, so start over
+DELETE FROM daily_product_sales;
+DELETE FROM daily_sales_summaries;
+ALTER TABLE daily_product_sales DROP CONSTRAINT IF EXISTS daily_product_sales_pkey;
+ALTER TABLE daily_product_sales DROP COLUMN IF EXISTS below_cost_lines;
+ALTER TABLE daily_product_sales DROP COLUMN IF EXISTS category_name;
+ALTER TABLE daily_product_sales DROP COLUMN IF EXISTS category_id;
+ALTER TABLE daily_product_sales ADD PRIMARY KEY (business_date, store_id, product_id, product_name);
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Actor identifies who performed a write operation and where it came from
+type Actor struct {
+	UserID    uuid.UUID
+	Username  string
+	DeviceID  *uuid.UUID
+	IPAddress string
+	RequestID string
+}
+
+// AuditLog is an append-only, hash-chained record of a write operation
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Sequence   int64      `gorm:"autoIncrement;uniqueIndex;not null" json:"sequence"`
+	ActorID    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id"`
+	ActorName  string     `gorm:"size:100" json:"actor_name"`
+	Action     string     `gorm:"not null;size:50;index" json:"action"`      // create, update, delete, cancel
+	EntityType string     `gorm:"not null;size:50;index" json:"entity_type"` // user, product, category, setting, transaction
+	EntityID   string     `gorm:"size:100;index" json:"entity_id"`
+	Before     string     `gorm:"type:text" json:"before"`
+	After      string     `gorm:"type:text" json:"after"`
+	Diff       string     `gorm:"type:text" json:"diff"`
+	IPAddress  string     `gorm:"size:64" json:"ip_address"`
+	RequestID  string     `gorm:"size:100" json:"request_id"`
+	PrevHash   string     `gorm:"size:64" json:"prev_hash"`
+	Hash       string     `gorm:"size:64;not null" json:"hash"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+}
+
+// ComputeHash returns the SHA-256 of the entry content chained to PrevHash
+func (a *AuditLog) ComputeHash() string {
+	actorID := ""
+	if a.ActorID != nil {
+		actorID = a.ActorID.String()
+	}
+
+	content := strings.Join([]string{
+		a.PrevHash,
+		actorID,
+		a.ActorName,
+		a.Action,
+		a.EntityType,
+		a.EntityID,
+		a.Before,
+		a.After,
+		a.Diff,
+		a.IPAddress,
+		a.RequestID,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}, "|")
+
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+type AuditLogRepository interface {
+	// Append links the entry to the last one in the chain and stores it
+	Append(entry *AuditLog) error
+	FindAll(page, limit int, filters AuditLogFilters) ([]AuditLog, int64, error)
+	FindAfterSequence(sequence int64, limit int) ([]AuditLog, error)
+	WithTx(tx *gorm.DB) AuditLogRepository
+}
+
+type AuditLogFilters struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	StartDate  *time.Time
+	EndDate    *time.Time
+}
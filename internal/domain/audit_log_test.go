@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditLogComputeHash(t *testing.T) {
+	actorID := uuid.New()
+	base := AuditLog{
+		ActorID:    &actorID,
+		ActorName:  "admin",
+		Action:     "update",
+		EntityType: "product",
+		EntityID:   uuid.NewString(),
+		Before:     `{"price":"10.00"}`,
+		After:      `{"price":"12.00"}`,
+		Diff:       `{"price":{"before":"10.00","after":"12.00"}}`,
+		IPAddress:  "10.0.0.1",
+		RequestID:  "req-1",
+		PrevHash:   "abc123",
+		CreatedAt:  time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+	}
+	hash := base.ComputeHash()
+	if len(hash) != 64 {
+		t.Fatalf("hash %q is not a hex SHA-256", hash)
+	}
+	if again := base.ComputeHash(); again != hash {
+		t.Fatalf("hash changed between calls: %s then %s", hash, again)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(a *AuditLog)
+		changed bool
+	}{
+		{"previous hash", func(a *AuditLog) { a.PrevHash = "def456" }, true},
+		{"actor", func(a *AuditLog) { other := uuid.New(); a.ActorID = &other }, true},
+		{"no actor", func(a *AuditLog) { a.ActorID = nil }, true},
+		{"actor name", func(a *AuditLog) { a.ActorName = "cashier" }, true},
+		{"action", func(a *AuditLog) { a.Action = "delete" }, true},
+		{"entity type", func(a *AuditLog) { a.EntityType = "category" }, true},
+		{"entity ID", func(a *AuditLog) { a.EntityID = uuid.NewString() }, true},
+		{"before", func(a *AuditLog) { a.Before = `{"price":"11.00"}` }, true},
+		{"after", func(a *AuditLog) { a.After = `{"price":"13.00"}` }, true},
+		{"diff", func(a *AuditLog) { a.Diff = "" }, true},
+		{"IP address", func(a *AuditLog) { a.IPAddress = "10.0.0.2" }, true},
+		{"request ID", func(a *AuditLog) { a.RequestID = "req-2" }, true},
+		{"time", func(a *AuditLog) { a.CreatedAt = a.CreatedAt.Add(time.Nanosecond) }, true},
+		{"same time in another zone", func(a *AuditLog) { a.CreatedAt = a.CreatedAt.In(time.FixedZone("WIB", 7*60*60)) }, false},
+		{"stored hash", func(a *AuditLog) { a.Hash = "tampered" }, false},
+		{"sequence", func(a *AuditLog) { a.Sequence = 42 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := base
+			tt.change(&entry)
+			if got := entry.ComputeHash(); (got != hash) != tt.changed {
+				t.Errorf("hash changed = %v, want %v", got != hash, tt.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "github.com/google/uuid"
+
+// BundleItem is one component of a bundle product, such as the items in a
+// gift hamper. Selling the bundle takes its components out of stock.
+type BundleItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BundleID    uuid.UUID `gorm:"type:uuid;not null;index" json:"bundle_id"`
+	ComponentID uuid.UUID `gorm:"type:uuid;not null;index" json:"component_id"`
+	Component   *Product  `gorm:"foreignKey:ComponentID" json:"component,omitempty"`
+	Quantity    int       `gorm:"not null" json:"quantity"` // Units of the component in one bundle
+}
+
+// AvailableStock is the product's own stock or, for a bundle, how many whole
+// bundles the stock of its components makes. Bundle items must be loaded
+// with their components.
+func (p *Product) AvailableStock() int {
+	if !p.IsBundle {
+		return p.Stock
+	}
+	if len(p.BundleItems) == 0 {
+		return 0
+	}
+
+	available := -1
+	for _, item := range p.BundleItems {
+		if item.Component == nil || item.Quantity <= 0 {
+			return 0
+		}
+		bundles := item.Component.Stock / item.Quantity
+		if item.Component.Stock < 0 {
+			bundles = 0
+		}
+		if available < 0 || bundles < available {
+			available = bundles
+		}
+	}
+	return available
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestProductAvailableStock(t *testing.T) {
+	component := func(stock, quantity int) BundleItem {
+		return BundleItem{Component: &Product{Stock: stock}, Quantity: quantity}
+	}
+
+	tests := []struct {
+		name    string
+		product Product
+		want    int
+	}{
+		{name: "plain product uses its own stock", product: Product{Stock: 7}, want: 7},
+		{name: "plain product may be oversold", product: Product{Stock: -3}, want: -3},
+		{name: "bundle without items", product: Product{IsBundle: true, Stock: 4}, want: 0},
+		{name: "scarcest component limits the bundle", product: Product{IsBundle: true, BundleItems: []BundleItem{component(10, 2), component(7, 1)}}, want: 5},
+		{name: "only whole bundles count", product: Product{IsBundle: true, BundleItems: []BundleItem{component(5, 2)}}, want: 2},
+		{name: "oversold component", product: Product{IsBundle: true, BundleItems: []BundleItem{component(10, 1), component(-2, 1)}}, want: 0},
+		{name: "component not loaded", product: Product{IsBundle: true, BundleItems: []BundleItem{{Quantity: 1}}}, want: 0},
+		{name: "item without a quantity", product: Product{IsBundle: true, BundleItems: []BundleItem{component(10, 0)}}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.product.AvailableStock(); got != tt.want {
+				t.Errorf("AvailableStock = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ type Category struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string         `gorm:"not null;size:255" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
+	TaxClassID  *uuid.UUID     `gorm:"type:uuid" json:"tax_class_id"` // Default tax class for its products
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -23,4 +24,5 @@ type CategoryRepository interface {
 	Update(category *Category) error
 	Delete(id uuid.UUID) error
 	FindAll(page, limit int) ([]Category, int64, error)
+	WithTx(tx *gorm.DB) CategoryRepository
 }
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Device is a registered POS terminal that authenticates with its own credentials
+type Device struct {
+	ID                   uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name                 string         `gorm:"not null;size:255" json:"name"`
+	Location             string         `gorm:"size:255" json:"location"`
+	StoreID              *uuid.UUID     `gorm:"type:uuid;index" json:"store_id"`
+	Status               string         `gorm:"not null;size:50;default:pending" json:"status"` // pending, active, revoked
+	PairingCodeHash      string         `gorm:"size:64;index" json:"-"`
+	PairingCodeExpiresAt *time.Time     `json:"pairing_code_expires_at"`
+	CredentialHash       string         `gorm:"size:64" json:"-"`
+	PairedAt             *time.Time     `json:"paired_at"`
+	LastSeenAt           *time.Time     `json:"last_seen_at"`
+	LastSyncAt           *time.Time     `json:"last_sync_at"`
+	LastSyncStatus       string         `gorm:"size:50" json:"last_sync_status"` // success, partial, failed
+	LastSyncCount        int            `gorm:"default:0" json:"last_sync_count"`
+	RevokedAt            *time.Time     `json:"revoked_at"`
+	RevokedBy            *uuid.UUID     `gorm:"type:uuid" json:"revoked_by"`
+	CreatedBy            *uuid.UUID     `gorm:"type:uuid" json:"created_by"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type DeviceRepository interface {
+	Create(device *Device) error
+	FindByID(id uuid.UUID) (*Device, error)
+	FindByPairingCodeHash(hash string) (*Device, error)
+	Update(device *Device) error
+	UpdateLastSeen(id uuid.UUID, seenAt time.Time) error
+	UpdateSyncStatus(id uuid.UUID, status string, count int, syncedAt time.Time) error
+	FindAll(page, limit int) ([]Device, int64, error)
+	WithTx(tx *gorm.DB) DeviceRepository
+}
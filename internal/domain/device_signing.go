@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeviceSigningKey is an Ed25519 public key a device uses to sign offline transactions
+type DeviceSigningKey struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DeviceID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"device_id"`
+	Algorithm string     `gorm:"not null;size:20;default:ed25519" json:"algorithm"`
+	PublicKey string     `gorm:"not null;size:100" json:"public_key"`           // base64
+	Status    string     `gorm:"not null;size:20;default:active" json:"status"` // active, retired
+	RetiredAt *time.Time `json:"retired_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type DeviceSigningKeyRepository interface {
+	Create(key *DeviceSigningKey) error
+	FindByID(id uuid.UUID) (*DeviceSigningKey, error)
+	FindByDevice(deviceID uuid.UUID) ([]DeviceSigningKey, error)
+	// RetireActive marks every active key of the device as retired
+	RetireActive(deviceID uuid.UUID, retiredAt time.Time) error
+	WithTx(tx *gorm.DB) DeviceSigningKeyRepository
+}
+
+// QuarantinedTransaction holds an offline sale whose signature could not be verified
+type QuarantinedTransaction struct {
+	ID                  uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DeviceID            *uuid.UUID `gorm:"type:uuid;index" json:"device_id"`
+	UserID              *uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	ClientTransactionID string     `gorm:"size:100;index" json:"client_transaction_id"`
+	Payload             string     `gorm:"type:text;not null" json:"payload"` // Original request as JSON
+	Signature           string     `gorm:"type:text" json:"signature"`
+	SigningKeyID        string     `gorm:"size:100" json:"signing_key_id"`
+	Reason              string     `gorm:"size:255;not null" json:"reason"`
+	Status              string     `gorm:"size:50;not null;default:pending;index" json:"status"` // pending, approved, rejected
+	ReviewedBy          *uuid.UUID `gorm:"type:uuid" json:"reviewed_by"`
+	ReviewedAt          *time.Time `json:"reviewed_at"`
+	ReviewNotes         string     `gorm:"type:text" json:"review_notes"`
+	TransactionID       *uuid.UUID `gorm:"type:uuid" json:"transaction_id"` // Set when approved and posted
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type QuarantineRepository interface {
+	CreateTx(tx *gorm.DB, entry *QuarantinedTransaction) error
+	FindByID(id uuid.UUID) (*QuarantinedTransaction, error)
+	FindPendingByClientTransactionID(clientTxID string) (*QuarantinedTransaction, error)
+	FindAll(page, limit int, status string) ([]QuarantinedTransaction, int64, error)
+	// ReviewTx saves a review, failing when the entry is no longer pending
+	ReviewTx(tx *gorm.DB, entry *QuarantinedTransaction) error
+}
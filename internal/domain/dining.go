@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DiningArea groups tables, e.g. indoor, terrace or bar
+type DiningArea struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StoreID   *uuid.UUID     `gorm:"type:uuid;index" json:"store_id"`
+	Name      string         `gorm:"not null;size:100" json:"name"`
+	SortOrder int            `gorm:"not null;default:0" json:"sort_order"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type DiningTable struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AreaID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"area_id"`
+	Area      *DiningArea    `gorm:"foreignKey:AreaID" json:"area,omitempty"`
+	Name      string         `gorm:"not null;size:50" json:"name"`
+	Seats     int            `gorm:"not null;default:0" json:"seats"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type DiningRepository interface {
+	CreateArea(area *DiningArea) error
+	FindAreaByID(id uuid.UUID) (*DiningArea, error)
+	FindAreas(storeID *uuid.UUID) ([]DiningArea, error)
+	UpdateArea(area *DiningArea) error
+	DeleteArea(id uuid.UUID) error
+	CreateTable(table *DiningTable) error
+	FindTableByID(id uuid.UUID) (*DiningTable, error)
+	// FindTables lists tables with their area, optionally of one store or area
+	FindTables(storeID, areaID *uuid.UUID) ([]DiningTable, error)
+	UpdateTable(table *DiningTable) error
+	DeleteTable(id uuid.UUID) error
+	// CountTables counts the tables left in an area
+	CountTables(areaID uuid.UUID) (int64, error)
+	WithTx(tx *gorm.DB) DiningRepository
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Held order statuses
+const (
+	HeldOrderStatusHeld      = "held"
+	HeldOrderStatusCompleted = "completed"
+	HeldOrderStatusCancelled = "cancelled"
+)
+
+// HeldOrder is a cart parked mid-sale. It doesn't touch stock; when it reserves
+// stock, the reserved quantities are kept out of other sales' availability
+// until ReservedUntil passes.
+type HeldOrder struct {
+	ID             uuid.UUID              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Label          string                 `gorm:"size:100" json:"label"` // e.g. the customer's name or a table, to find it again
+	Status         string                 `gorm:"size:20;not null;default:held;index" json:"status"`
+	StoreID        *uuid.UUID             `gorm:"type:uuid;index" json:"store_id"`
+	UserID         *uuid.UUID             `gorm:"type:uuid" json:"user_id"`
+	User           *User                  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	DeviceID       *uuid.UUID             `gorm:"type:uuid" json:"device_id"`  // Device that parked it
+	ResumedBy      *uuid.UUID             `gorm:"type:uuid" json:"resumed_by"` // Device that last picked it up
+	ResumedAt      *time.Time             `json:"resumed_at"`
+	CustomerName   string                 `gorm:"size:255" json:"customer_name"`
+	Notes          string                 `gorm:"type:text" json:"notes"`
+	DiscountAmount money.Money            `gorm:"type:decimal(15,2);not null;default:0" json:"discount_amount"`
+	ReserveStock   bool                   `gorm:"not null;default:false" json:"reserve_stock"`
+	ReservedUntil  *time.Time             `gorm:"index" json:"reserved_until"`
+	TransactionID  *uuid.UUID             `gorm:"type:uuid" json:"transaction_id"` // Set when completed
+	Items          []HeldOrderItem        `gorm:"foreignKey:HeldOrderID" json:"items,omitempty"`
+	Reservations   []HeldOrderReservation `gorm:"foreignKey:HeldOrderID" json:"-"` // Only while ReserveStock is set
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+type HeldOrderItem struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	HeldOrderID uuid.UUID         `gorm:"type:uuid;not null;index" json:"held_order_id"`
+	ProductID   uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductName string            `gorm:"not null;size:255" json:"product_name"`
+	Price       money.Money       `gorm:"type:decimal(15,2);not null" json:"price"`
+	Quantity    int               `gorm:"not null" json:"quantity"`
+	Modifiers   SelectedModifiers `gorm:"type:text" json:"modifiers"`
+}
+
+// HeldOrderReservation is stock a held cart keeps out of other sales, taken
+// from the same products its sale would take: bundle items, recipe components
+// and modifier ingredients as well as products sold as themselves
+type HeldOrderReservation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	HeldOrderID uuid.UUID `gorm:"type:uuid;not null;index" json:"held_order_id"`
+	ProductID   uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+}
+
+type HeldOrderFilters struct {
+	Status  string
+	StoreID *uuid.UUID
+}
+
+type HeldOrderRepository interface {
+	CreateTx(tx *gorm.DB, order *HeldOrder) error
+	FindByID(id uuid.UUID) (*HeldOrder, error)
+	FindAll(page, limit int, filters HeldOrderFilters) ([]HeldOrder, int64, error)
+	Update(order *HeldOrder) error
+	// ReplaceTx saves the order and swaps its items and reservations for
+	// order.Items and order.Reservations, failing when it is no longer held
+	ReplaceTx(tx *gorm.DB, order *HeldOrder) error
+	// ReservedQuantityTx sums what open, unexpired held orders reserve of a
+	// product, leaving out the held order exclude
+	ReservedQuantityTx(tx *gorm.DB, productID uuid.UUID, exclude *uuid.UUID) (int, error)
+	// CompleteTx marks a held order completed by transactionID, failing when
+	// it is no longer held or its cart changed since updatedAt
+	CompleteTx(tx *gorm.DB, id uuid.UUID, transactionID uuid.UUID, updatedAt time.Time) error
+	WithTx(tx *gorm.DB) HeldOrderRepository
+}
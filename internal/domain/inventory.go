@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Costing methods selectable with the "costing_method" setting
+const (
+	CostingMethodAverage = "average"
+	CostingMethodFIFO    = "fifo"
+)
+
+// InventoryCostLayer is a batch of stock received at one unit cost. Layers are
+// consumed oldest first whichever costing method is active, so switching to FIFO
+// later still has accurate layers to work from.
+type InventoryCostLayer struct {
+	ID                uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID         uuid.UUID   `gorm:"type:uuid;not null;index" json:"product_id"`
+	MovementID        *uuid.UUID  `gorm:"type:uuid" json:"movement_id"`
+	Quantity          int         `gorm:"not null" json:"quantity"`
+	RemainingQuantity int         `gorm:"not null" json:"remaining_quantity"`
+	UnitCost          money.Money `gorm:"type:decimal(15,2);not null" json:"unit_cost"`
+	ReceivedAt        time.Time   `gorm:"not null" json:"received_at"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+// InventoryValuation is the quantity and value of one product's stock at a point in time
+type InventoryValuation struct {
+	ProductID   uuid.UUID
+	ProductName string
+	SKU         string
+	Quantity    int64
+	Value       money.Money
+}
+
+// ProductSoldQuantity is the net quantity of a product sold over a period
+type ProductSoldQuantity struct {
+	ProductID uuid.UUID
+	Quantity  int64
+}
+
+type InventoryRepository interface {
+	FindMovements(page, limit int, filters InventoryMovementFilters) ([]InventoryMovement, int64, error)
+	// Valuation sums the signed quantity and cost of every movement up to asOf
+	Valuation(asOf time.Time, productID *uuid.UUID) ([]InventoryValuation, error)
+	// SoldQuantities nets sale movements against cancellations since the given time
+	SoldQuantities(since time.Time, productID *uuid.UUID) ([]ProductSoldQuantity, error)
+}
+
+type InventoryMovementFilters struct {
+	ProductID     *uuid.UUID
+	MovementType  string
+	ReferenceType string
+	StartDate     *time.Time
+	EndDate       *time.Time // Exclusive
+}
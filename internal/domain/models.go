@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"pos-backend/pkg/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,17 +12,21 @@ type Product struct {
 	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	CategoryID      *uuid.UUID     `gorm:"type:uuid" json:"category_id"`
 	Category        *Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	TaxClassID      *uuid.UUID     `gorm:"type:uuid;index" json:"tax_class_id"` // Empty falls back to the category's tax class
 	Name            string         `gorm:"not null;size:255" json:"name"`
 	SKU             string         `gorm:"uniqueIndex;not null;size:100" json:"sku"`
 	Description     string         `gorm:"type:text" json:"description"`
-	Price           float64        `gorm:"type:decimal(15,2);not null" json:"price"`
-	Cost            float64        `gorm:"type:decimal(15,2);default:0" json:"cost"`
+	Price           money.Money    `gorm:"type:decimal(15,2);not null" json:"price"`
+	Cost            money.Money    `gorm:"type:decimal(15,2);default:0" json:"cost"`
 	Stock           int            `gorm:"default:0" json:"stock"`
 	MinStock        int            `gorm:"default:0" json:"min_stock"`
+	LeadTimeDays    int            `gorm:"not null;default:0" json:"lead_time_days"` // 0 uses the reorder_lead_time_days setting
 	StockVersion    int            `gorm:"default:0" json:"stock_version"`
 	LastStockUpdate *time.Time     `json:"last_stock_update"`
 	ImageURL        string         `gorm:"size:500" json:"image_url"`
 	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	IsBundle        bool           `gorm:"not null;default:false" json:"is_bundle"` // Sold as one SKU, stock comes from BundleItems
+	BundleItems     []BundleItem   `gorm:"foreignKey:BundleID" json:"bundle_items,omitempty"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -33,10 +38,16 @@ type Transaction struct {
 	ClientTransactionID string            `gorm:"uniqueIndex;size:100" json:"client_transaction_id"` // For idempotency
 	UserID              *uuid.UUID        `gorm:"type:uuid" json:"user_id"`
 	User                *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	TotalAmount         float64           `gorm:"type:decimal(15,2);not null" json:"total_amount"`
-	DiscountAmount      float64           `gorm:"type:decimal(15,2);default:0" json:"discount_amount"`
-	TaxAmount           float64           `gorm:"type:decimal(15,2);default:0" json:"tax_amount"`
-	FinalAmount         float64           `gorm:"type:decimal(15,2);not null" json:"final_amount"`
+	DeviceID            *uuid.UUID        `gorm:"type:uuid;index" json:"device_id"`
+	Device              *Device           `gorm:"foreignKey:DeviceID" json:"device,omitempty"`
+	StoreID             *uuid.UUID        `gorm:"type:uuid;index" json:"store_id"`
+	TotalAmount         money.Money       `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	DiscountAmount      money.Money       `gorm:"type:decimal(15,2);default:0" json:"discount_amount"`
+	TaxAmount           money.Money       `gorm:"type:decimal(15,2);default:0" json:"tax_amount"`                  // Includes tax already inside inclusive prices
+	ServiceChargeRate   float64           `gorm:"type:decimal(7,4);not null;default:0" json:"service_charge_rate"` // Percent
+	ServiceChargeAmount money.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"service_charge_amount"`
+	RoundingAmount      money.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"rounding_amount"` // Cash rounding, positive when rounded up in the store's favour
+	FinalAmount         money.Money       `gorm:"type:decimal(15,2);not null" json:"final_amount"`
 	PaymentMethod       string            `gorm:"not null;size:50" json:"payment_method"`        // cash, card, qris
 	PaymentStatus       string            `gorm:"size:50;default:pending" json:"payment_status"` // pending, completed, cancelled
 	CustomerName        string            `gorm:"size:255" json:"customer_name"`
@@ -45,6 +56,9 @@ type Transaction struct {
 	SyncedAt            *time.Time        `json:"synced_at"`
 	HasStockIssue       bool              `gorm:"default:false" json:"has_stock_issue"`
 	StockIssueDetails   string            `gorm:"type:text" json:"stock_issue_details"`
+	CancelledBy         *uuid.UUID        `gorm:"type:uuid;index" json:"cancelled_by"`
+	CancelledAt         *time.Time        `json:"cancelled_at"`
+	CancellationReason  string            `gorm:"type:text" json:"cancellation_reason"`
 	Items               []TransactionItem `gorm:"foreignKey:TransactionID" json:"items,omitempty"`
 	CreatedAt           time.Time         `json:"created_at"`
 	UpdatedAt           time.Time         `json:"updated_at"`
@@ -52,27 +66,43 @@ type Transaction struct {
 }
 
 type TransactionItem struct {
-	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	TransactionID uuid.UUID  `gorm:"type:uuid;not null" json:"transaction_id"`
-	ProductID     *uuid.UUID `gorm:"type:uuid" json:"product_id"`
-	Product       *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
-	ProductName   string     `gorm:"not null;size:255" json:"product_name"`
-	ProductPrice  float64    `gorm:"type:decimal(15,2);not null" json:"product_price"`
-	Quantity      int        `gorm:"not null" json:"quantity"`
-	Subtotal      float64    `gorm:"type:decimal(15,2);not null" json:"subtotal"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID                  uuid.UUID                  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TransactionID       uuid.UUID                  `gorm:"type:uuid;not null" json:"transaction_id"`
+	ProductID           *uuid.UUID                 `gorm:"type:uuid" json:"product_id"`
+	Product             *Product                   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	ProductName         string                     `gorm:"not null;size:255" json:"product_name"`
+	CategoryID          *uuid.UUID                 `gorm:"type:uuid;index" json:"category_id"` // Category at the time of sale, empty when uncategorized
+	CategoryName        string                     `gorm:"size:255" json:"category_name"`
+	ProductPrice        money.Money                `gorm:"type:decimal(15,2);not null" json:"product_price"`
+	Quantity            int                        `gorm:"not null" json:"quantity"`
+	ModifierAmount      money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"modifier_amount"` // Added to ProductPrice by the chosen modifiers, per unit
+	Subtotal            money.Money                `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	UnitCost            money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"unit_cost"`  // Cost of goods sold per unit at the time of sale
+	TotalCost           money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"total_cost"` // Cost of goods sold for the line
+	TaxClassID          *uuid.UUID                 `gorm:"type:uuid;index" json:"tax_class_id"`
+	TaxRate             float64                    `gorm:"type:decimal(7,4);not null;default:0" json:"tax_rate"` // Percent, as charged at the time of sale
+	TaxInclusive        bool                       `gorm:"not null;default:false" json:"tax_inclusive"`
+	DiscountAmount      money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"discount_amount"`       // Share of the transaction discount
+	ServiceChargeAmount money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"service_charge_amount"` // Share of the transaction service charge
+	TaxableAmount       money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"taxable_amount"`        // Base the tax was charged on: the line after discount and without tax, plus any taxable service charge
+	TaxAmount           money.Money                `gorm:"type:decimal(15,2);not null;default:0" json:"tax_amount"`
+	Modifiers           []TransactionItemModifier  `gorm:"foreignKey:TransactionItemID" json:"modifiers,omitempty"`
+	Components          []TransactionItemComponent `gorm:"foreignKey:TransactionItemID" json:"components,omitempty"` // Set when the line took its stock from other products instead of itself
+	CreatedAt           time.Time                  `json:"created_at"`
 }
 
 type InventoryMovement struct {
-	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	ProductID     uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
-	Product       *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
-	MovementType  string     `gorm:"not null;size:50" json:"movement_type"` // in, out, adjustment
-	Quantity      int        `gorm:"not null" json:"quantity"`
-	ReferenceType string     `gorm:"size:50" json:"reference_type"` // transaction, purchase, adjustment
-	ReferenceID   *uuid.UUID `gorm:"type:uuid" json:"reference_id"`
-	Notes         string     `gorm:"type:text" json:"notes"`
-	UserID        *uuid.UUID `gorm:"type:uuid" json:"user_id"`
-	User          *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID     uuid.UUID   `gorm:"type:uuid;not null" json:"product_id"`
+	Product       *Product    `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	MovementType  string      `gorm:"not null;size:50" json:"movement_type"` // in, out, adjustment
+	Quantity      int         `gorm:"not null" json:"quantity"`
+	UnitCost      money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	TotalCost     money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"total_cost"` // Signed like Quantity, the value added to or removed from stock
+	ReferenceType string      `gorm:"size:50" json:"reference_type"`                           // transaction, transaction_cancel, purchase, adjustment, opening
+	ReferenceID   *uuid.UUID  `gorm:"type:uuid" json:"reference_id"`
+	Notes         string      `gorm:"type:text" json:"notes"`
+	UserID        *uuid.UUID  `gorm:"type:uuid" json:"user_id"`
+	User          *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
 }
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModifierGroup is a set of choices offered with a product, e.g. milk type or toppings
+type ModifierGroup struct {
+	ID            uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name          string           `gorm:"not null;size:100" json:"name"`
+	MinSelections int              `gorm:"not null;default:0" json:"min_selections"` // 0 makes the group optional
+	MaxSelections int              `gorm:"not null;default:0" json:"max_selections"` // 0 means no limit
+	IsActive      bool             `gorm:"default:true" json:"is_active"`
+	Options       []ModifierOption `gorm:"foreignKey:GroupID" json:"options,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+type ModifierOption struct {
+	ID                 uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	GroupID            uuid.UUID      `gorm:"type:uuid;not null;index" json:"group_id"`
+	Name               string         `gorm:"not null;size:100" json:"name"`
+	PriceDelta         money.Money    `gorm:"type:decimal(15,2);not null;default:0" json:"price_delta"` // Added to the unit price, may be negative
+	IngredientID       *uuid.UUID     `gorm:"type:uuid" json:"ingredient_id"`                           // Product taken from stock when chosen
+	Ingredient         *Product       `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+	IngredientQuantity int            `gorm:"not null;default:0" json:"ingredient_quantity"` // Units of the ingredient per item sold
+	SortOrder          int            `gorm:"not null;default:0" json:"sort_order"`
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ProductModifierGroup attaches a modifier group to a product
+type ProductModifierGroup struct {
+	ProductID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"product_id"`
+	ModifierGroupID uuid.UUID `gorm:"type:uuid;primaryKey" json:"modifier_group_id"`
+	SortOrder       int       `gorm:"not null;default:0" json:"sort_order"`
+}
+
+// TransactionItemModifier is a modifier as sold on a transaction line
+type TransactionItemModifier struct {
+	ID                 uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TransactionItemID  uuid.UUID   `gorm:"type:uuid;not null;index" json:"transaction_item_id"`
+	ModifierOptionID   *uuid.UUID  `gorm:"type:uuid" json:"modifier_option_id"`
+	GroupName          string      `gorm:"not null;size:100" json:"group_name"`
+	OptionName         string      `gorm:"not null;size:100" json:"option_name"`
+	PriceDelta         money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"price_delta"`
+	Quantity           int         `gorm:"not null;default:1" json:"quantity"` // Times chosen per item
+	IngredientID       *uuid.UUID  `gorm:"type:uuid" json:"ingredient_id"`
+	IngredientQuantity int         `gorm:"not null;default:0" json:"ingredient_quantity"`                // Units taken from stock for the whole line
+	IngredientCost     money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"ingredient_cost"` // Cost of those units, part of the line's TotalCost
+}
+
+// SelectedModifier is a modifier chosen on a cart line that hasn't been sold yet
+type SelectedModifier struct {
+	OptionID   uuid.UUID   `json:"option_id"`
+	GroupName  string      `json:"group_name"`
+	OptionName string      `json:"option_name"`
+	PriceDelta money.Money `json:"price_delta"`
+	Quantity   int         `json:"quantity"`
+}
+
+// SelectedModifiers is stored as a JSON column on held order and ticket items
+type SelectedModifiers []SelectedModifier
+
+// UnitAmount is what the modifiers add to the price of one item
+func (m SelectedModifiers) UnitAmount() money.Money {
+	var amount money.Money
+	for _, modifier := range m {
+		amount = amount.Add(modifier.PriceDelta.Mul(int64(modifier.Quantity)))
+	}
+	return amount
+}
+
+// OptionIDs lists the chosen options, repeated as often as each was chosen
+func (m SelectedModifiers) OptionIDs() []string {
+	var ids []string
+	for _, modifier := range m {
+		for i := 0; i < modifier.Quantity; i++ {
+			ids = append(ids, modifier.OptionID.String())
+		}
+	}
+	return ids
+}
+
+func (m SelectedModifiers) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *SelectedModifiers) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return errors.New("unsupported type for selected modifiers")
+	}
+	return json.Unmarshal(data, m)
+}
+
+type ModifierRepository interface {
+	Create(group *ModifierGroup) error
+	FindByID(id uuid.UUID) (*ModifierGroup, error)
+	FindAll(page, limit int) ([]ModifierGroup, int64, error)
+	// Update saves the group and its options, removing options left out
+	Update(group *ModifierGroup) error
+	Delete(id uuid.UUID) error
+	// FindByProduct lists the groups attached to a product, with their active options
+	FindByProduct(productID uuid.UUID) ([]ModifierGroup, error)
+	FindByProductTx(tx *gorm.DB, productID uuid.UUID) ([]ModifierGroup, error)
+	// SetProductGroups replaces the groups attached to a product, in order
+	SetProductGroups(productID uuid.UUID, groupIDs []uuid.UUID) error
+	IsInUse(id uuid.UUID) (bool, error)
+	WithTx(tx *gorm.DB) ModifierRepository
+}
@@ -1,14 +1,28 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type ProductRepository interface {
 	Create(product *Product) error
 	FindByID(id uuid.UUID) (*Product, error)
 	FindBySKU(sku string) (*Product, error)
+	// Update saves the product's details. Stock and cost only change through
+	// inventory movements, so they are left as stored.
 	Update(product *Product) error
 	Delete(id uuid.UUID) error
 	FindAll(page, limit int) ([]Product, int64, error)
 	FindAllWithFilter(search string, categoryID *uuid.UUID, page, limit int) ([]Product, int64, error)
 	FindByCategory(categoryID uuid.UUID, page, limit int) ([]Product, int64, error)
+	// FindAllActive returns every active product, optionally in one category, without paging
+	FindAllActive(categoryID *uuid.UUID) ([]Product, error)
+	FindBundles(page, limit int) ([]Product, int64, error)
+	// SetBundleItems replaces the components of a bundle
+	SetBundleItems(bundleID uuid.UUID, items []BundleItem) error
+	FindBundleItemsTx(tx *gorm.DB, bundleID uuid.UUID) ([]BundleItem, error)
+	// IsBundleComponent reports whether a product is in any bundle
+	IsBundleComponent(productID uuid.UUID) (bool, error)
+	WithTx(tx *gorm.DB) ProductRepository
 }
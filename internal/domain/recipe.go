@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"math"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recipe is the bill of materials of a composite product. Selling the product
+// takes its components out of stock instead of the product itself.
+type Recipe struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID     uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex" json:"product_id"`
+	Product       *Product          `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	YieldQuantity int               `gorm:"not null;default:1" json:"yield_quantity"`                  // Portions one batch of the components makes
+	WastePercent  float64           `gorm:"type:decimal(7,4);not null;default:0" json:"waste_percent"` // Extra taken for trimming and spillage
+	IsActive      bool              `gorm:"default:true" json:"is_active"`
+	Notes         string            `gorm:"type:text" json:"notes"`
+	Components    []RecipeComponent `gorm:"foreignKey:RecipeID" json:"components,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+type RecipeComponent struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RecipeID    uuid.UUID `gorm:"type:uuid;not null;index" json:"recipe_id"`
+	ComponentID uuid.UUID `gorm:"type:uuid;not null" json:"component_id"`
+	Component   *Product  `gorm:"foreignKey:ComponentID" json:"component,omitempty"`
+	Quantity    int       `gorm:"not null" json:"quantity"` // Units of the component per batch
+}
+
+// Consumption is how many units of component making portions takes, after
+// yield and waste, rounded up to whole units. Each sold line rounds on its own,
+// so recipes are only accepted with at least one unit of every component per
+// portion, see PortionQuantity.
+func (r *Recipe) Consumption(component RecipeComponent, portions int) int {
+	yield := r.YieldQuantity
+	if yield <= 0 {
+		yield = 1
+	}
+	units := float64(component.Quantity*portions) * (1 + r.WastePercent/100) / float64(yield)
+	// Keep float noise such as 210.00000000000003 from costing a whole unit
+	return int(math.Ceil(units - 1e-9))
+}
+
+// PortionQuantity is the exact units of component one portion takes, after
+// yield and waste
+func (r *Recipe) PortionQuantity(component RecipeComponent) float64 {
+	yield := r.YieldQuantity
+	if yield <= 0 {
+		yield = 1
+	}
+	return float64(component.Quantity) * (1 + r.WastePercent/100) / float64(yield)
+}
+
+// TransactionItemComponent is stock a sold line took from another product,
+// such as the ingredients of a recipe or the contents of a bundle
+type TransactionItemComponent struct {
+	ID                uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TransactionItemID uuid.UUID   `gorm:"type:uuid;not null;index" json:"transaction_item_id"`
+	ProductID         uuid.UUID   `gorm:"type:uuid;not null;index" json:"product_id"`
+	ProductName       string      `gorm:"not null;size:255" json:"product_name"`
+	Quantity          int         `gorm:"not null" json:"quantity"`                                // Units taken for the whole line
+	TotalCost         money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"total_cost"` // Cost of those units, part of the line's TotalCost
+	ListValue         money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"list_value"` // Bundles only: the component's own price for those units
+	Revenue           money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"revenue"`    // Bundles only: share of the line's net sales, by list value
+}
+
+type RecipeRepository interface {
+	// Save creates or replaces a product's recipe with its components
+	Save(recipe *Recipe) error
+	FindByProduct(productID uuid.UUID) (*Recipe, error)
+	// FindActiveByProductTx returns the product's active recipe, or nil when it has none
+	FindActiveByProductTx(tx *gorm.DB, productID uuid.UUID) (*Recipe, error)
+	FindAll(page, limit int) ([]Recipe, int64, error)
+	Delete(productID uuid.UUID) error
+	// IsComponent reports whether a product is used in any recipe
+	IsComponent(productID uuid.UUID) (bool, error)
+	// ActiveProductIDs lists the products that have an active recipe
+	ActiveProductIDs() ([]uuid.UUID, error)
+	WithTx(tx *gorm.DB) RecipeRepository
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecipeConsumption(t *testing.T) {
+	tests := []struct {
+		name     string
+		yield    int
+		waste    float64
+		quantity int
+		portions int
+		want     int
+	}{
+		{name: "one portion per batch", yield: 1, quantity: 2, portions: 3, want: 6},
+		{name: "partial units round up", yield: 4, quantity: 10, portions: 1, want: 3},
+		{name: "whole batches are exact", yield: 4, quantity: 10, portions: 4, want: 10},
+		{name: "waste is added", yield: 2, waste: 10, quantity: 3, portions: 4, want: 7},
+		{name: "float noise doesn't cost a unit", yield: 1, waste: 5, quantity: 200, portions: 1, want: 210},
+		{name: "missing yield counts as one", yield: 0, quantity: 3, portions: 2, want: 6},
+		{name: "nothing sold", yield: 1, quantity: 5, portions: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipe := &Recipe{YieldQuantity: tt.yield, WastePercent: tt.waste}
+			if got := recipe.Consumption(RecipeComponent{Quantity: tt.quantity}, tt.portions); got != tt.want {
+				t.Errorf("Consumption = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecipePortionQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		yield    int
+		waste    float64
+		quantity int
+		want     float64
+	}{
+		{name: "one portion per batch", yield: 1, quantity: 2, want: 2},
+		{name: "batch split into portions", yield: 4, quantity: 10, want: 2.5},
+		{name: "less than a unit per portion", yield: 4, quantity: 2, want: 0.5},
+		{name: "waste is added", yield: 1, waste: 50, quantity: 2, want: 3},
+		{name: "missing yield counts as one", yield: 0, quantity: 3, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipe := &Recipe{YieldQuantity: tt.yield, WastePercent: tt.waste}
+			if got := recipe.PortionQuantity(RecipeComponent{Quantity: tt.quantity}); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PortionQuantity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"pos-backend/pkg/businessday"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DailySalesSummary totals one business day of sales for a store and payment
+// method. Sales without a store are kept under uuid.Nil. Cancelled sales are
+// taken out of the completed totals of the day they were sold and counted in
+// the cancelled columns instead.
+type DailySalesSummary struct {
+	BusinessDate     time.Time   `gorm:"type:date;primaryKey" json:"business_date"`
+	StoreID          uuid.UUID   `gorm:"type:uuid;primaryKey" json:"store_id"`
+	PaymentMethod    string      `gorm:"size:50;primaryKey" json:"payment_method"`
+	TransactionCount int64       `gorm:"not null;default:0" json:"transaction_count"`
+	TotalAmount      money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"total_amount"`
+	DiscountAmount   money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"discount_amount"`
+	TaxAmount        money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"tax_amount"`
+	Revenue          money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"revenue"` // Sum of final amounts
+	ItemsSold        int64       `gorm:"not null;default:0" json:"items_sold"`
+	CancelledCount   int64       `gorm:"not null;default:0" json:"cancelled_count"`
+	CancelledAmount  money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"cancelled_amount"`
+	RoundedCount     int64       `gorm:"not null;default:0" json:"rounded_count"`                    // Sales whose cash total was rounded
+	RoundingGain     money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"rounding_gain"` // Rounded up in the store's favour
+	RoundingLoss     money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"rounding_loss"` // Rounded down, as a positive amount
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// DailyProductSales totals one business day of completed sales for a store,
+// product and the category the lines were sold under. Lines whose product row
+// is gone are kept under uuid.Nil and told apart by the name they were sold
+// under; uncategorized lines have a nil CategoryID.
+type DailyProductSales struct {
+	BusinessDate   time.Time   `gorm:"type:date;primaryKey" json:"business_date"`
+	StoreID        uuid.UUID   `gorm:"type:uuid;primaryKey" json:"store_id"`
+	ProductID      uuid.UUID   `gorm:"type:uuid;primaryKey" json:"product_id"`
+	ProductName    string      `gorm:"size:255;primaryKey" json:"product_name"`
+	CategoryID     uuid.UUID   `gorm:"type:uuid;primaryKey" json:"category_id"`
+	CategoryName   string      `gorm:"size:255;primaryKey" json:"category_name"`
+	Quantity       int64       `gorm:"not null;default:0" json:"quantity"`
+	Revenue        money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"revenue"` // Sum of line subtotals
+	Cost           money.Money `gorm:"type:decimal(15,2);not null;default:0" json:"cost"`
+	BelowCostLines int64       `gorm:"not null;default:0" json:"below_cost_lines"` // Lines sold for less than their unit cost
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func (DailyProductSales) TableName() string {
+	return "daily_product_sales"
+}
+
+type SalesSummaryRepository interface {
+	// AddTx adds the figures to the matching rows, creating them as needed,
+	// inside the caller's transaction. Negative figures take a sale back out.
+	AddTx(tx *gorm.DB, summary DailySalesSummary, products []DailyProductSales) error
+	// Rebuild recomputes the business days in [start, end) from transactions; nil means unbounded
+	Rebuild(calendar businessday.Calendar, start, end *time.Time) error
+	IsEmpty() (bool, error)
+}
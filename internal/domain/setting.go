@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +12,86 @@ type Setting struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Key       string         `gorm:"uniqueIndex;not null;size:100" json:"key"`
 	Value     string         `gorm:"type:text" json:"value"`
-	Category  string         `gorm:"size:50" json:"category"` // store, tax, receipt, system
+	Category  string         `gorm:"size:50" json:"category"` // store, tax, inventory, receipt, system
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Setting version actions
+const (
+	SettingVersionBaseline   = "baseline" // Settings as found when versioning started
+	SettingVersionInitialize = "initialize"
+	SettingVersionUpdate     = "update"
+	SettingVersionRollback   = "rollback"
+	SettingVersionOverride   = "override" // Store or device overrides changed
+)
+
+// SettingVersion is an immutable record of one change to the settings, with
+// the full set of settings as they were right after it
+type SettingVersion struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Version         int64      `gorm:"uniqueIndex;not null" json:"version"`
+	Action          string     `gorm:"not null;size:50" json:"action"`
+	RestoredVersion *int64     `json:"restored_version"` // Set on rollbacks
+	ActorID         *uuid.UUID `gorm:"type:uuid" json:"actor_id"`
+	ActorName       string     `gorm:"size:100" json:"actor_name"`
+	Changes         string     `gorm:"type:text" json:"changes"`  // JSON array of SettingChange
+	Snapshot        string     `gorm:"type:text" json:"snapshot"` // JSON SettingSnapshot
+	// JSON array of SettingOverrideSnapshotEntry; empty on versions saved
+	// before overrides were versioned
+	OverrideSnapshot string    `gorm:"type:text" json:"override_snapshot"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// SettingChange is one key changed by a version; Before is empty for new keys.
+// Override changes name their scope, and a null After removes the override.
+type SettingChange struct {
+	Scope    string          `json:"scope,omitempty"`
+	ScopeID  *uuid.UUID      `json:"scope_id,omitempty"`
+	Key      string          `json:"key"`
+	Category string          `json:"category"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after"`
+}
+
+// SettingSnapshotEntry is one setting's category and JSON value in a snapshot
+type SettingSnapshotEntry struct {
+	Category string          `json:"category"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// SettingSnapshot holds every setting by key
+type SettingSnapshot map[string]SettingSnapshotEntry
+
+// SettingOverrideSnapshotEntry is one store or device override in a snapshot
+type SettingOverrideSnapshotEntry struct {
+	Scope    string          `json:"scope"`
+	ScopeID  uuid.UUID       `json:"scope_id"`
+	Key      string          `json:"key"`
+	Category string          `json:"category"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Levels a setting can be overridden at, each inheriting from the one before:
+// global settings, then the store, then the device
+const (
+	SettingScopeStore  = "store"
+	SettingScopeDevice = "device"
+)
+
+// SettingOverride replaces a global setting for one store or device
+type SettingOverride struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Scope     string    `gorm:"not null;size:20;uniqueIndex:idx_setting_overrides_scope_key" json:"scope"`
+	ScopeID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_setting_overrides_scope_key" json:"scope_id"`
+	Key       string    `gorm:"not null;size:100;uniqueIndex:idx_setting_overrides_scope_key" json:"key"`
+	Value     string    `gorm:"type:text" json:"value"`
+	Category  string    `gorm:"size:50" json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type SettingRepository interface {
 	GetByKey(key string) (*Setting, error)
 	GetByCategory(category string) ([]Setting, error)
@@ -24,4 +99,19 @@ type SettingRepository interface {
 	Upsert(setting *Setting) error
 	BulkUpsert(settings []Setting) error
 	Delete(key string) error
+
+	// SaveVersion upserts settings and appends version, numbering it and
+	// snapshotting the resulting settings and overrides, in one database
+	// transaction
+	SaveVersion(settings []Setting, version *SettingVersion) error
+	FindVersion(version int64) (*SettingVersion, error)
+	FindVersions(page, limit int) ([]SettingVersion, int64, error)
+	HasVersions() (bool, error)
+
+	FindOverrides(scope string, scopeID uuid.UUID) ([]SettingOverride, error)
+	FindAllOverrides() ([]SettingOverride, error)
+	FindOverride(scope string, scopeID uuid.UUID, key string) (*SettingOverride, error)
+	UpsertOverride(override *SettingOverride) error
+	DeleteOverride(scope string, scopeID uuid.UUID, key string) error
+	WithTx(tx *gorm.DB) SettingRepository
 }
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Store is a physical outlet. Devices belong to a store and stamp it on every sale.
+type Store struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code      string         `gorm:"uniqueIndex;not null;size:50" json:"code"`
+	Name      string         `gorm:"not null;size:255" json:"name"`
+	Address   string         `gorm:"type:text" json:"address"`
+	Phone     string         `gorm:"size:50" json:"phone"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type StoreRepository interface {
+	Create(store *Store) error
+	FindByID(id uuid.UUID) (*Store, error)
+	FindByCode(code string) (*Store, error)
+	Update(store *Store) error
+	Delete(id uuid.UUID) error
+	FindAll(page, limit int) ([]Store, int64, error)
+	WithTx(tx *gorm.DB) StoreRepository
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxClass is a tax products are sold under, e.g. exempt, PPN or PB1. With
+// Inclusive pricing the tax is already part of the selling price; otherwise it
+// is added on top.
+type TaxClass struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code      string         `gorm:"uniqueIndex;not null;size:20" json:"code"`
+	Name      string         `gorm:"not null;size:100" json:"name"`
+	Rate      float64        `gorm:"type:decimal(7,4);not null;default:0" json:"rate"` // Percent
+	Inclusive bool           `gorm:"not null;default:false" json:"inclusive"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type TaxClassRepository interface {
+	Create(taxClass *TaxClass) error
+	FindByID(id uuid.UUID) (*TaxClass, error)
+	FindByCode(code string) (*TaxClass, error)
+	Update(taxClass *TaxClass) error
+	Delete(id uuid.UUID) error
+	FindAll(page, limit int) ([]TaxClass, int64, error)
+	// IsInUse reports whether any product or category is assigned the class
+	IsInUse(id uuid.UUID) (bool, error)
+	WithTx(tx *gorm.DB) TaxClassRepository
+}
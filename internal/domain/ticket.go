@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ticket statuses
+const (
+	TicketStatusOpen    = "open"
+	TicketStatusSettled = "settled"
+	TicketStatusVoid    = "void"
+)
+
+// Kitchen statuses of a ticket item, in the order an item moves through them
+const (
+	KitchenStatusQueued    = "queued"
+	KitchenStatusPreparing = "preparing"
+	KitchenStatusReady     = "ready"
+	KitchenStatusServed    = "served"
+)
+
+// Ticket is an open tab for a table. Items are sent to the kitchen in rounds
+// and the whole ticket is paid at the end as one sale.
+type Ticket struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TableID       *uuid.UUID   `gorm:"type:uuid;index" json:"table_id"` // Empty for counter or takeaway tickets
+	Table         *DiningTable `gorm:"foreignKey:TableID" json:"table,omitempty"`
+	StoreID       *uuid.UUID   `gorm:"type:uuid;index" json:"store_id"`
+	Status        string       `gorm:"size:20;not null;default:open;index" json:"status"`
+	Guests        int          `gorm:"not null;default:0" json:"guests"`
+	CustomerName  string       `gorm:"size:255" json:"customer_name"`
+	Notes         string       `gorm:"type:text" json:"notes"`
+	Rounds        int          `gorm:"not null;default:0" json:"rounds"` // Rounds sent to the kitchen so far
+	OpenedBy      *uuid.UUID   `gorm:"type:uuid" json:"opened_by"`
+	User          *User        `gorm:"foreignKey:OpenedBy" json:"user,omitempty"`
+	ClosedAt      *time.Time   `json:"closed_at"`
+	TransactionID *uuid.UUID   `gorm:"type:uuid" json:"transaction_id"` // Set when settled
+	Items         []TicketItem `gorm:"foreignKey:TicketID" json:"items,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+type TicketItem struct {
+	ID              uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TicketID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"ticket_id"`
+	Ticket          *Ticket           `gorm:"foreignKey:TicketID" json:"ticket,omitempty"`
+	Round           int               `gorm:"not null" json:"round"`
+	ProductID       uuid.UUID         `gorm:"type:uuid;not null" json:"product_id"`
+	ProductName     string            `gorm:"not null;size:255" json:"product_name"`
+	Price           money.Money       `gorm:"type:decimal(15,2);not null" json:"price"`
+	Quantity        int               `gorm:"not null" json:"quantity"`
+	Modifiers       SelectedModifiers `gorm:"type:text" json:"modifiers"`
+	Notes           string            `gorm:"type:text" json:"notes"` // For the kitchen, e.g. "no ice"
+	KitchenStatus   string            `gorm:"size:20;not null;default:queued;index" json:"kitchen_status"`
+	SentAt          time.Time         `json:"sent_at"`
+	StatusChangedAt time.Time         `json:"status_changed_at"`
+}
+
+type TicketFilters struct {
+	Status  string
+	StoreID *uuid.UUID
+	TableID *uuid.UUID
+}
+
+type KitchenQueueFilters struct {
+	StoreID  *uuid.UUID
+	Statuses []string
+}
+
+type TicketRepository interface {
+	Create(ticket *Ticket) error
+	FindByID(id uuid.UUID) (*Ticket, error)
+	// FindOpenByTable returns the table's open ticket
+	FindOpenByTable(tableID uuid.UUID) (*Ticket, error)
+	// OpenTicketsByTable maps each occupied table to its open ticket
+	OpenTicketsByTable() (map[uuid.UUID]uuid.UUID, error)
+	FindAll(page, limit int, filters TicketFilters) ([]Ticket, int64, error)
+	Update(ticket *Ticket) error
+	// AddRoundTx numbers the next round of an open ticket and saves its items,
+	// locking the ticket so rounds sent at the same time don't share a number
+	AddRoundTx(tx *gorm.DB, ticketID uuid.UUID, items []TicketItem) (int, error)
+	FindItem(id uuid.UUID) (*TicketItem, error)
+	UpdateItem(item *TicketItem) error
+	DeleteItem(id uuid.UUID) error
+	// FindKitchenQueue lists items of open tickets oldest first, with their ticket and table
+	FindKitchenQueue(filters KitchenQueueFilters) ([]TicketItem, error)
+	// SettleTx marks an open ticket settled by transactionID, failing when it
+	// is no longer open or changed since updatedAt
+	SettleTx(tx *gorm.DB, id uuid.UUID, transactionID uuid.UUID, updatedAt time.Time) error
+	WithTx(tx *gorm.DB) TicketRepository
+}
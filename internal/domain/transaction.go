@@ -12,15 +12,18 @@ type TransactionRepository interface {
 	FindByTransactionCode(code string) (*Transaction, error)
 	FindByClientTransactionID(clientTxID string) (*Transaction, error)
 	FindAll(page, limit int, filters TransactionFilters) ([]Transaction, int64, error)
+	FindAllInBatches(filters TransactionFilters, batchSize int, fn func(transactions []Transaction) error) error
 	Update(transaction *Transaction) error
 	Delete(id uuid.UUID) error
 }
 
 type TransactionFilters struct {
 	UserID        *uuid.UUID
+	DeviceID      *uuid.UUID
+	StoreID       *uuid.UUID
 	PaymentMethod string
 	PaymentStatus string
 	StartDate     *time.Time
-	EndDate       *time.Time
+	EndDate       *time.Time // Exclusive
 	HasStockIssue *bool
 }
@@ -28,4 +28,5 @@ type UserRepository interface {
 	Update(user *User) error
 	Delete(id uuid.UUID) error
 	FindAll(page, limit int) ([]User, int64, error)
+	WithTx(tx *gorm.DB) UserRepository
 }
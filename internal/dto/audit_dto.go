@@ -0,0 +1,28 @@
+package dto
+
+import "encoding/json"
+
+type AuditLogResponse struct {
+	ID         string          `json:"id"`
+	Sequence   int64           `json:"sequence"`
+	ActorID    string          `json:"actor_id,omitempty"`
+	ActorName  string          `json:"actor_name,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Diff       json.RawMessage `json:"diff,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type AuditVerifyResponse struct {
+	Valid          bool   `json:"valid"`
+	CheckedEntries int64  `json:"checked_entries"`
+	BrokenSequence int64  `json:"broken_sequence,omitempty"`
+	Message        string `json:"message"`
+}
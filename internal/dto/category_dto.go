@@ -6,17 +6,20 @@ type CategoryResponse struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	TaxClassID  string    `json:"tax_class_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
 type CreateCategoryRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description" validate:"required"`
+	TaxClassID  string `json:"tax_class_id"` // Default tax class for its products
 }
 
 type UpdateCategoryRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description" validate:"required"`
+	TaxClassID  string `json:"tax_class_id"` // Default tax class for its products
 }
 
 type DeleteCategoryRequest struct {
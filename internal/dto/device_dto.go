@@ -0,0 +1,55 @@
+package dto
+
+type CreateDeviceRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=255"`
+	Location string `json:"location" binding:"omitempty,max=255"`
+	StoreID  string `json:"store_id"`
+}
+
+type PairDeviceRequest struct {
+	PairingCode string `json:"pairing_code" binding:"required"`
+}
+
+type DeviceResponse struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	Location             string `json:"location,omitempty"`
+	StoreID              string `json:"store_id,omitempty"`
+	Status               string `json:"status"`
+	PairingCode          string `json:"pairing_code,omitempty"` // Only returned when the code is generated
+	PairingCodeExpiresAt string `json:"pairing_code_expires_at,omitempty"`
+	PairedAt             string `json:"paired_at,omitempty"`
+	LastSeenAt           string `json:"last_seen_at,omitempty"`
+	LastSyncAt           string `json:"last_sync_at,omitempty"`
+	LastSyncStatus       string `json:"last_sync_status,omitempty"`
+	LastSyncCount        int    `json:"last_sync_count"`
+	RevokedAt            string `json:"revoked_at,omitempty"`
+	CreatedAt            string `json:"created_at"`
+}
+
+type DeviceCredentialsResponse struct {
+	DeviceID    string `json:"device_id"`
+	DeviceToken string `json:"device_token"` // Send as X-Device-Token header
+}
+
+type DeviceConfigResponse struct {
+	DeviceID     string                 `json:"device_id"`
+	DeviceName   string                 `json:"device_name"`
+	Store        map[string]interface{} `json:"store"`
+	Receipt      map[string]interface{} `json:"receipt"`
+	SyncInterval interface{}            `json:"sync_interval"`
+}
+
+type RegisterSigningKeyRequest struct {
+	PublicKey string `json:"public_key" binding:"required"` // Base64 Ed25519 public key
+}
+
+type SigningKeyResponse struct {
+	ID        string `json:"id"`
+	DeviceID  string `json:"device_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Status    string `json:"status"`
+	RetiredAt string `json:"retired_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+type DiningAreaResponse struct {
+	ID        string `json:"id"`
+	StoreID   string `json:"store_id,omitempty"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+}
+
+type DiningTableResponse struct {
+	ID        string `json:"id"`
+	AreaID    string `json:"area_id"`
+	AreaName  string `json:"area_name,omitempty"`
+	StoreID   string `json:"store_id,omitempty"`
+	Name      string `json:"name"`
+	Seats     int    `json:"seats"`
+	IsActive  bool   `json:"is_active"`
+	Occupied  bool   `json:"occupied"`            // Whether the table has an open ticket
+	TicketID  string `json:"ticket_id,omitempty"` // The open ticket, if any
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateDiningAreaRequest struct {
+	StoreID   string `json:"store_id"`
+	Name      string `json:"name" binding:"required,max=100"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type UpdateDiningAreaRequest struct {
+	StoreID   string `json:"store_id"`
+	Name      string `json:"name" binding:"required,max=100"`
+	SortOrder int    `json:"sort_order"`
+	IsActive  bool   `json:"is_active"`
+}
+
+type CreateDiningTableRequest struct {
+	AreaID string `json:"area_id" binding:"required"`
+	Name   string `json:"name" binding:"required,max=50"`
+	Seats  int    `json:"seats" binding:"gte=0"`
+}
+
+type UpdateDiningTableRequest struct {
+	AreaID   string `json:"area_id" binding:"required"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Seats    int    `json:"seats" binding:"gte=0"`
+	IsActive bool   `json:"is_active"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+// TransactionEvent is the payload of transaction.created and transaction.cancelled events
+type TransactionEvent struct {
+	TransactionID      string      `json:"transaction_id"`
+	TransactionCode    string      `json:"transaction_code"`
+	StoreID            string      `json:"store_id,omitempty"`
+	UserID             string      `json:"user_id,omitempty"`
+	FinalAmount        money.Money `json:"final_amount"`
+	PaymentMethod      string      `json:"payment_method"`
+	PaymentStatus      string      `json:"payment_status"`
+	ItemCount          int         `json:"item_count"`
+	HasStockIssue      bool        `json:"has_stock_issue"`
+	CancelledBy        string      `json:"cancelled_by,omitempty"`
+	CancellationReason string      `json:"cancellation_reason,omitempty"`
+}
+
+// StockIssueEvent is the payload of transaction.stock_issue events
+type StockIssueEvent struct {
+	TransactionID   string         `json:"transaction_id"`
+	TransactionCode string         `json:"transaction_code"`
+	Warnings        []StockWarning `json:"warnings"`
+}
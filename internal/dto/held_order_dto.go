@@ -0,0 +1,59 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+type HeldOrderItemResponse struct {
+	ProductID   string                     `json:"product_id"`
+	ProductName string                     `json:"product_name"`
+	Price       money.Money                `json:"price"`
+	Quantity    int                        `json:"quantity"`
+	Modifiers   []SelectedModifierResponse `json:"modifiers,omitempty"`
+	Subtotal    money.Money                `json:"subtotal"`
+}
+
+type HeldOrderResponse struct {
+	ID             string                  `json:"id"`
+	Label          string                  `json:"label,omitempty"`
+	Status         string                  `json:"status"`
+	StoreID        string                  `json:"store_id,omitempty"`
+	UserID         string                  `json:"user_id,omitempty"`
+	Username       string                  `json:"username,omitempty"`
+	DeviceID       string                  `json:"device_id,omitempty"`
+	ResumedBy      string                  `json:"resumed_by,omitempty"`
+	ResumedAt      string                  `json:"resumed_at,omitempty"`
+	CustomerName   string                  `json:"customer_name,omitempty"`
+	Notes          string                  `json:"notes,omitempty"`
+	Items          []HeldOrderItemResponse `json:"items"`
+	TotalAmount    money.Money             `json:"total_amount"`
+	DiscountAmount money.Money             `json:"discount_amount"`
+	ReserveStock   bool                    `json:"reserve_stock"`
+	ReservedUntil  string                  `json:"reserved_until,omitempty"`
+	Reserved       bool                    `json:"reserved"` // Whether the reservation is still in force
+	TransactionID  string                  `json:"transaction_id,omitempty"`
+	CreatedAt      string                  `json:"created_at"`
+	UpdatedAt      string                  `json:"updated_at"`
+}
+
+type CreateHeldOrderRequest struct {
+	Label          string                   `json:"label" binding:"max=100"`
+	Items          []TransactionItemRequest `json:"items" binding:"required,min=1"`
+	CustomerName   string                   `json:"customer_name"`
+	DiscountAmount money.Money              `json:"discount_amount"`
+	Notes          string                   `json:"notes"`
+	ReserveStock   bool                     `json:"reserve_stock"`      // Keep the items out of other sales until the reservation expires
+	StoreID        string                   `json:"store_id,omitempty"` // Only used without a device, device holds go to the device's store
+}
+
+type UpdateHeldOrderRequest struct {
+	Label          string                   `json:"label" binding:"max=100"`
+	Items          []TransactionItemRequest `json:"items" binding:"required,min=1"`
+	CustomerName   string                   `json:"customer_name"`
+	DiscountAmount money.Money              `json:"discount_amount"`
+	Notes          string                   `json:"notes"`
+	ReserveStock   bool                     `json:"reserve_stock"` // Saving starts a new reservation period
+}
+
+type CompleteHeldOrderRequest struct {
+	ClientTransactionID string `json:"client_transaction_id"` // Defaults to one derived from the held order, so retries can't sell it twice
+	PaymentMethod       string `json:"payment_method" binding:"required,oneof=cash card qris"`
+}
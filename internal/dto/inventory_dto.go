@@ -0,0 +1,64 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+type ReceiveStockRequest struct {
+	ProductID string      `json:"product_id" binding:"required"`
+	Quantity  int         `json:"quantity" binding:"required,gt=0"`
+	UnitCost  money.Money `json:"unit_cost"`
+	Notes     string      `json:"notes"`
+}
+
+type StockAdjustmentRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"` // Positive adds stock, negative removes it
+	Notes     string `json:"notes"`
+}
+
+type InventoryMovementResponse struct {
+	ID            string      `json:"id"`
+	ProductID     string      `json:"product_id"`
+	ProductName   string      `json:"product_name,omitempty"`
+	MovementType  string      `json:"movement_type"`
+	Quantity      int         `json:"quantity"`
+	UnitCost      money.Money `json:"unit_cost"`
+	TotalCost     money.Money `json:"total_cost"`
+	ReferenceType string      `json:"reference_type"`
+	ReferenceID   string      `json:"reference_id,omitempty"`
+	Notes         string      `json:"notes,omitempty"`
+	UserID        string      `json:"user_id,omitempty"`
+	CreatedAt     string      `json:"created_at"`
+}
+
+type InventoryValuationItem struct {
+	ProductID   string      `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	SKU         string      `json:"sku"`
+	Quantity    int64       `json:"quantity"`
+	UnitCost    money.Money `json:"unit_cost"`
+	Value       money.Money `json:"value"`
+}
+
+type InventoryValuationResponse struct {
+	AsOf          string                   `json:"as_of"`
+	CostingMethod string                   `json:"costing_method"`
+	TotalQuantity int64                    `json:"total_quantity"`
+	TotalValue    money.Money              `json:"total_value"`
+	Items         []InventoryValuationItem `json:"items"`
+}
+
+type ReorderSuggestionResponse struct {
+	ProductID         string      `json:"product_id"`
+	ProductName       string      `json:"product_name"`
+	SKU               string      `json:"sku"`
+	Stock             int         `json:"stock"`
+	MinStock          int         `json:"min_stock"`
+	DailyVelocity     float64     `json:"daily_velocity"` // Average units sold per day
+	DaysOfCover       *float64    `json:"days_of_cover"`  // Null when the product isn't selling
+	LeadTimeDays      int         `json:"lead_time_days"`
+	SafetyStock       int         `json:"safety_stock"`
+	ReorderPoint      int         `json:"reorder_point"`
+	SuggestedQuantity int         `json:"suggested_quantity"`
+	EstimatedCost     money.Money `json:"estimated_cost"`
+	Status            string      `json:"status"` // out_of_stock, below_min, reorder, ok
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+type ModifierOptionResponse struct {
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	PriceDelta         money.Money `json:"price_delta"`
+	IngredientID       string      `json:"ingredient_id,omitempty"`
+	IngredientQuantity int         `json:"ingredient_quantity,omitempty"`
+	SortOrder          int         `json:"sort_order"`
+	IsActive           bool        `json:"is_active"`
+}
+
+type ModifierGroupResponse struct {
+	ID            string                   `json:"id"`
+	Name          string                   `json:"name"`
+	MinSelections int                      `json:"min_selections"`
+	MaxSelections int                      `json:"max_selections"` // 0 means no limit
+	IsActive      bool                     `json:"is_active"`
+	Options       []ModifierOptionResponse `json:"options"`
+	CreatedAt     string                   `json:"created_at"`
+}
+
+type ModifierOptionRequest struct {
+	ID                 string      `json:"id"` // Empty adds a new option
+	Name               string      `json:"name" binding:"required,max=100"`
+	PriceDelta         money.Money `json:"price_delta"`
+	IngredientID       string      `json:"ingredient_id"` // Product taken from stock when the option is chosen
+	IngredientQuantity int         `json:"ingredient_quantity" binding:"gte=0"`
+	IsActive           *bool       `json:"is_active"` // Defaults to true
+}
+
+type CreateModifierGroupRequest struct {
+	Name          string                  `json:"name" binding:"required,max=100"`
+	MinSelections int                     `json:"min_selections" binding:"gte=0"`
+	MaxSelections int                     `json:"max_selections" binding:"gte=0"` // 0 means no limit
+	Options       []ModifierOptionRequest `json:"options" binding:"required,min=1,dive"`
+}
+
+type UpdateModifierGroupRequest struct {
+	Name          string                  `json:"name" binding:"required,max=100"`
+	MinSelections int                     `json:"min_selections" binding:"gte=0"`
+	MaxSelections int                     `json:"max_selections" binding:"gte=0"` // 0 means no limit
+	IsActive      bool                    `json:"is_active"`
+	Options       []ModifierOptionRequest `json:"options" binding:"required,min=1,dive"` // Options left out are removed
+}
+
+type SetProductModifierGroupsRequest struct {
+	GroupIDs []string `json:"group_ids"` // In display order, empty detaches all groups
+}
+
+// SelectedModifierResponse is a modifier chosen on a line, as printed on receipts and kitchen tickets
+type SelectedModifierResponse struct {
+	OptionID   string      `json:"option_id,omitempty"`
+	GroupName  string      `json:"group_name"`
+	OptionName string      `json:"option_name"`
+	PriceDelta money.Money `json:"price_delta"`
+	Quantity   int         `json:"quantity"`
+}
@@ -1,44 +1,70 @@
 package dto
 
+import "pos-backend/pkg/money"
+
 type ProductResponse struct {
-	ID              string  `json:"id"`
-	CategoryID      string  `json:"category_id,omitempty"`
-	CategoryName    string  `json:"category_name,omitempty"`
-	Name            string  `json:"name"`
-	SKU             string  `json:"sku"`
-	Description     string  `json:"description"`
-	Price           float64 `json:"price"`
-	Cost            float64 `json:"cost"`
-	Stock           int     `json:"stock"`
-	MinStock        int     `json:"min_stock"`
-	StockVersion    int     `json:"stock_version"`
-	LastStockUpdate string  `json:"last_stock_update,omitempty"`
-	ImageURL        string  `json:"image_url,omitempty"`
-	IsActive        bool    `json:"is_active"`
+	ID              string               `json:"id"`
+	CategoryID      string               `json:"category_id,omitempty"`
+	CategoryName    string               `json:"category_name,omitempty"`
+	TaxClassID      string               `json:"tax_class_id,omitempty"` // Empty when the category's tax class applies
+	Name            string               `json:"name"`
+	SKU             string               `json:"sku"`
+	Description     string               `json:"description"`
+	Price           money.Money          `json:"price"`
+	Cost            money.Money          `json:"cost"`
+	Stock           int                  `json:"stock"` // For bundles, how many the components' stock makes
+	MinStock        int                  `json:"min_stock"`
+	LeadTimeDays    int                  `json:"lead_time_days"`
+	StockVersion    int                  `json:"stock_version"`
+	LastStockUpdate string               `json:"last_stock_update,omitempty"`
+	ImageURL        string               `json:"image_url,omitempty"`
+	IsActive        bool                 `json:"is_active"`
+	IsBundle        bool                 `json:"is_bundle"`
+	BundleItems     []BundleItemResponse `json:"bundle_items,omitempty"`
+}
+
+type BundleItemResponse struct {
+	ComponentID   string      `json:"component_id"`
+	ComponentName string      `json:"component_name,omitempty"`
+	Quantity      int         `json:"quantity"` // Units in one bundle
+	Price         money.Money `json:"price"`    // The component's own price, used to allocate bundle revenue
+	Stock         int         `json:"stock"`
+}
+
+type BundleItemRequest struct {
+	ComponentID string `json:"component_id" validate:"required"`
+	Quantity    int    `json:"quantity" validate:"required,min=1"`
 }
 
 type CreateProductRequest struct {
-	CategoryID  string  `json:"category_id"`
-	Name        string  `json:"name" validate:"required"`
-	SKU         string  `json:"sku" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gte=0"`
-	Cost        float64 `json:"cost" validate:"gte=0"`
-	Stock       int     `json:"stock" validate:"gte=0"`
-	MinStock    int     `json:"min_stock" validate:"gte=0"`
-	ImageURL    string  `json:"image_url"`
-	IsActive    bool    `json:"is_active"`
+	CategoryID   string              `json:"category_id"`
+	TaxClassID   string              `json:"tax_class_id"` // Empty uses the category's tax class
+	Name         string              `json:"name" validate:"required"`
+	SKU          string              `json:"sku" validate:"required"`
+	Description  string              `json:"description"`
+	Price        money.Money         `json:"price" validate:"required,gte=0"`
+	Cost         money.Money         `json:"cost" validate:"gte=0"`
+	Stock        int                 `json:"stock" validate:"gte=0"`
+	MinStock     int                 `json:"min_stock" validate:"gte=0"`
+	LeadTimeDays int                 `json:"lead_time_days" validate:"gte=0"` // 0 uses the store default
+	ImageURL     string              `json:"image_url"`
+	IsActive     bool                `json:"is_active"`
+	IsBundle     bool                `json:"is_bundle"`    // Stock is ignored, it comes from the bundle items
+	BundleItems  []BundleItemRequest `json:"bundle_items"` // Required for bundles
 }
 
 type UpdateProductRequest struct {
-	CategoryID  string  `json:"category_id"`
-	Name        string  `json:"name" validate:"required"`
-	SKU         string  `json:"sku" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gte=0"`
-	Cost        float64 `json:"cost" validate:"gte=0"`
-	Stock       int     `json:"stock" validate:"gte=0"`
-	MinStock    int     `json:"min_stock" validate:"gte=0"`
-	ImageURL    string  `json:"image_url"`
-	IsActive    bool    `json:"is_active"`
+	CategoryID   string              `json:"category_id"`
+	TaxClassID   string              `json:"tax_class_id"` // Empty uses the category's tax class
+	Name         string              `json:"name" validate:"required"`
+	SKU          string              `json:"sku" validate:"required"`
+	Description  string              `json:"description"`
+	Price        money.Money         `json:"price" validate:"required,gte=0"`
+	Stock        int                 `json:"stock" validate:"gte=0"`
+	MinStock     int                 `json:"min_stock" validate:"gte=0"`
+	LeadTimeDays int                 `json:"lead_time_days" validate:"gte=0"` // 0 uses the store default
+	ImageURL     string              `json:"image_url"`
+	IsActive     bool                `json:"is_active"`
+	IsBundle     *bool               `json:"is_bundle"`    // Left out keeps the product as it is
+	BundleItems  []BundleItemRequest `json:"bundle_items"` // Replaces a bundle's items, left out keeps them
 }
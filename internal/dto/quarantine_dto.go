@@ -0,0 +1,23 @@
+package dto
+
+import "encoding/json"
+
+type QuarantinedTransactionResponse struct {
+	ID                  string          `json:"id"`
+	DeviceID            string          `json:"device_id,omitempty"`
+	UserID              string          `json:"user_id,omitempty"`
+	ClientTransactionID string          `json:"client_transaction_id,omitempty"`
+	Payload             json.RawMessage `json:"payload"`
+	SigningKeyID        string          `json:"signing_key_id,omitempty"`
+	Reason              string          `json:"reason"`
+	Status              string          `json:"status"`
+	ReviewedBy          string          `json:"reviewed_by,omitempty"`
+	ReviewedAt          string          `json:"reviewed_at,omitempty"`
+	ReviewNotes         string          `json:"review_notes,omitempty"`
+	TransactionID       string          `json:"transaction_id,omitempty"`
+	CreatedAt           string          `json:"created_at"`
+}
+
+type ReviewQuarantineRequest struct {
+	Notes string `json:"notes"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+type RecipeComponentResponse struct {
+	ComponentID   string      `json:"component_id"`
+	ComponentName string      `json:"component_name,omitempty"`
+	Quantity      int         `json:"quantity"`     // Units per batch
+	UnitCost      money.Money `json:"unit_cost"`    // Current cost of one unit of the component
+	PortionCost   money.Money `json:"portion_cost"` // Share of one portion, after yield and waste
+	Stock         int         `json:"stock"`        // Current stock of the component
+}
+
+type RecipeResponse struct {
+	ID                string                    `json:"id"`
+	ProductID         string                    `json:"product_id"`
+	ProductName       string                    `json:"product_name,omitempty"`
+	YieldQuantity     int                       `json:"yield_quantity"`
+	WastePercent      float64                   `json:"waste_percent"`
+	IsActive          bool                      `json:"is_active"`
+	Notes             string                    `json:"notes,omitempty"`
+	Components        []RecipeComponentResponse `json:"components"`
+	EstimatedUnitCost money.Money               `json:"estimated_unit_cost"` // Cost of one portion at current component costs, sales are costed from the stock actually taken
+	CreatedAt         string                    `json:"created_at"`
+	UpdatedAt         string                    `json:"updated_at"`
+}
+
+type RecipeComponentRequest struct {
+	ComponentID string `json:"component_id" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"` // Units per batch
+}
+
+type SaveRecipeRequest struct {
+	YieldQuantity int                      `json:"yield_quantity" binding:"omitempty,min=1"` // Portions per batch, defaults to 1
+	WastePercent  float64                  `json:"waste_percent" binding:"gte=0,lte=100"`
+	IsActive      *bool                    `json:"is_active"` // Defaults to true
+	Notes         string                   `json:"notes"`
+	Components    []RecipeComponentRequest `json:"components" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,88 @@
+package dto
+
+import "encoding/json"
+
+type SettingDefinitionResponse struct {
+	Key         string      `json:"key"`
+	Category    string      `json:"category"`
+	Type        string      `json:"type"` // string, int, number, bool
+	Default     interface{} `json:"default"`
+	Min         *float64    `json:"min,omitempty"` // Length limits for strings
+	Max         *float64    `json:"max,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	EditRoles   []string    `json:"edit_roles"`
+	Scopes      []string    `json:"scopes"` // Levels that may override it: store, device
+	Description string      `json:"description"`
+}
+
+// SettingFieldError explains why one submitted setting was rejected
+type SettingFieldError struct {
+	Field   string `json:"field"` // category.key as submitted, or scope/scope_id/key for overrides
+	Code    string `json:"code"`  // unknown_key, wrong_category, not_overridable, forbidden, invalid
+	Message string `json:"message"`
+}
+
+type SettingChangeResponse struct {
+	Scope    string          `json:"scope,omitempty"` // Set on override changes
+	ScopeID  string          `json:"scope_id,omitempty"`
+	Key      string          `json:"key"`
+	Category string          `json:"category"`
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+}
+
+type SettingVersionResponse struct {
+	Version         int64                   `json:"version"`
+	Action          string                  `json:"action"`
+	RestoredVersion *int64                  `json:"restored_version,omitempty"`
+	ActorID         string                  `json:"actor_id,omitempty"`
+	ActorName       string                  `json:"actor_name,omitempty"`
+	Changes         []SettingChangeResponse `json:"changes"`
+	CreatedAt       string                  `json:"created_at"`
+}
+
+type SettingVersionDetailResponse struct {
+	SettingVersionResponse
+	Settings  map[string]map[string]json.RawMessage `json:"settings"`  // By category, then key
+	Overrides []SettingOverrideEntry                `json:"overrides"` // Empty on versions saved before overrides were versioned
+}
+
+type SettingOverrideEntry struct {
+	Scope    string          `json:"scope"`
+	ScopeID  string          `json:"scope_id"`
+	Key      string          `json:"key"`
+	Category string          `json:"category"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// SettingDiffResponse compares the settings at two versions; From 0 is before any version
+type SettingDiffResponse struct {
+	From    int64              `json:"from"`
+	To      int64              `json:"to"`
+	Changes []SettingDiffEntry `json:"changes"`
+}
+
+type SettingDiffEntry struct {
+	Scope    string          `json:"scope,omitempty"` // Set on override changes
+	ScopeID  string          `json:"scope_id,omitempty"`
+	Key      string          `json:"key"`
+	Category string          `json:"category"`
+	Status   string          `json:"status"` // added, removed, changed
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+}
+
+// ResolvedSettingResponse is a setting's effective value and where it came from
+type ResolvedSettingResponse struct {
+	Key      string      `json:"key"`
+	Category string      `json:"category"`
+	Value    interface{} `json:"value"`
+	Source   string      `json:"source"`              // default, global, store, device
+	SourceID string      `json:"source_id,omitempty"` // Store or device ID for overrides
+}
+
+type ResolvedSettingsResponse struct {
+	StoreID  string                    `json:"store_id,omitempty"`
+	DeviceID string                    `json:"device_id,omitempty"`
+	Settings []ResolvedSettingResponse `json:"settings"`
+}
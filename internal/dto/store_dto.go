@@ -0,0 +1,30 @@
+package dto
+
+type StoreResponse struct {
+	ID        string `json:"id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Address   string `json:"address,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateStoreRequest struct {
+	Code    string `json:"code" binding:"required,max=50"`
+	Name    string `json:"name" binding:"required,max=255"`
+	Address string `json:"address"`
+	Phone   string `json:"phone" binding:"omitempty,max=50"`
+}
+
+type UpdateStoreRequest struct {
+	Code     string `json:"code" binding:"required,max=50"`
+	Name     string `json:"name" binding:"required,max=255"`
+	Address  string `json:"address"`
+	Phone    string `json:"phone" binding:"omitempty,max=50"`
+	IsActive bool   `json:"is_active"`
+}
+
+type AssignDeviceStoreRequest struct {
+	StoreID string `json:"store_id"` // Empty removes the device from its store
+}
@@ -0,0 +1,26 @@
+package dto
+
+type TaxClassResponse struct {
+	ID        string  `json:"id"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	IsActive  bool    `json:"is_active"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type CreateTaxClassRequest struct {
+	Code      string  `json:"code" binding:"required,max=20"`
+	Name      string  `json:"name" binding:"required,max=100"`
+	Rate      float64 `json:"rate" binding:"gte=0,lte=100"` // Percent
+	Inclusive bool    `json:"inclusive"`                    // Prices already include the tax
+}
+
+type UpdateTaxClassRequest struct {
+	Code      string  `json:"code" binding:"required,max=20"`
+	Name      string  `json:"name" binding:"required,max=100"`
+	Rate      float64 `json:"rate" binding:"gte=0,lte=100"` // Percent
+	Inclusive bool    `json:"inclusive"`                    // Prices already include the tax
+	IsActive  bool    `json:"is_active"`
+}
@@ -0,0 +1,91 @@
+package dto
+
+import "pos-backend/pkg/money"
+
+type TicketItemResponse struct {
+	ID              string                     `json:"id"`
+	Round           int                        `json:"round"`
+	ProductID       string                     `json:"product_id"`
+	ProductName     string                     `json:"product_name"`
+	Price           money.Money                `json:"price"`
+	Quantity        int                        `json:"quantity"`
+	Modifiers       []SelectedModifierResponse `json:"modifiers,omitempty"`
+	Subtotal        money.Money                `json:"subtotal"`
+	Notes           string                     `json:"notes,omitempty"`
+	KitchenStatus   string                     `json:"kitchen_status"`
+	SentAt          string                     `json:"sent_at"`
+	StatusChangedAt string                     `json:"status_changed_at"`
+}
+
+type TicketResponse struct {
+	ID            string               `json:"id"`
+	TableID       string               `json:"table_id,omitempty"`
+	TableName     string               `json:"table_name,omitempty"`
+	StoreID       string               `json:"store_id,omitempty"`
+	Status        string               `json:"status"`
+	Guests        int                  `json:"guests"`
+	CustomerName  string               `json:"customer_name,omitempty"`
+	Notes         string               `json:"notes,omitempty"`
+	Rounds        int                  `json:"rounds"`
+	OpenedBy      string               `json:"opened_by,omitempty"`
+	Username      string               `json:"username,omitempty"`
+	Items         []TicketItemResponse `json:"items"`
+	TotalAmount   money.Money          `json:"total_amount"`
+	ClosedAt      string               `json:"closed_at,omitempty"`
+	TransactionID string               `json:"transaction_id,omitempty"`
+	CreatedAt     string               `json:"created_at"`
+	UpdatedAt     string               `json:"updated_at"`
+}
+
+// KitchenItemResponse is one line on the kitchen display
+type KitchenItemResponse struct {
+	ID              string   `json:"id"`
+	TicketID        string   `json:"ticket_id"`
+	TableName       string   `json:"table_name,omitempty"`
+	Round           int      `json:"round"`
+	ProductID       string   `json:"product_id"`
+	ProductName     string   `json:"product_name"`
+	Quantity        int      `json:"quantity"`
+	Modifiers       []string `json:"modifiers,omitempty"` // Option names, e.g. "Oat milk"
+	Notes           string   `json:"notes,omitempty"`
+	KitchenStatus   string   `json:"kitchen_status"`
+	SentAt          string   `json:"sent_at"`
+	StatusChangedAt string   `json:"status_changed_at"`
+}
+
+type OpenTicketRequest struct {
+	TableID      string `json:"table_id"` // Empty for counter or takeaway tickets
+	Guests       int    `json:"guests" validate:"gte=0"`
+	CustomerName string `json:"customer_name"`
+	Notes        string `json:"notes"`
+	StoreID      string `json:"store_id,omitempty"` // Only used without a table or device
+}
+
+type UpdateTicketRequest struct {
+	TableID      string `json:"table_id"` // Moves the ticket to another free table
+	Guests       int    `json:"guests" validate:"gte=0"`
+	CustomerName string `json:"customer_name"`
+	Notes        string `json:"notes"`
+}
+
+type TicketItemRequest struct {
+	ProductID         string      `json:"product_id" validate:"required"`
+	Quantity          int         `json:"quantity" validate:"required,min=1"`
+	Price             money.Money `json:"price"`
+	ModifierOptionIDs []string    `json:"modifier_option_ids"` // Repeat an option to choose it more than once
+	Notes             string      `json:"notes"`
+}
+
+type SendRoundRequest struct {
+	Items []TicketItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type UpdateKitchenStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=queued preparing ready served"`
+}
+
+type SettleTicketRequest struct {
+	ClientTransactionID string      `json:"client_transaction_id"` // Defaults to one derived from the ticket, so retries can't sell it twice
+	PaymentMethod       string      `json:"payment_method" validate:"required,oneof=cash card qris"`
+	DiscountAmount      money.Money `json:"discount_amount"`
+}
@@ -1,18 +1,43 @@
 package dto
 
+import (
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type TransactionItemRequest struct {
-	ProductID string  `json:"product_id" validate:"required"`
-	Quantity  int     `json:"quantity" validate:"required,gt=0"`
-	Price     float64 `json:"price" validate:"required,gte=0"`
+	ProductID         string      `json:"product_id" validate:"required"`
+	Quantity          int         `json:"quantity" validate:"required,gt=0"`
+	Price             money.Money `json:"price" validate:"required,gte=0"` // Base price, modifiers are priced by the server
+	ModifierOptionIDs []string    `json:"modifier_option_ids,omitempty"`   // Repeat an option to choose it more than once
 }
 
 type TransactionItemResponse struct {
-	ID           string  `json:"id"`
-	ProductID    string  `json:"product_id,omitempty"`
-	ProductName  string  `json:"product_name"`
-	ProductPrice float64 `json:"product_price"`
-	Quantity     int     `json:"quantity"`
-	Subtotal     float64 `json:"subtotal"`
+	ID                  string                             `json:"id"`
+	ProductID           string                             `json:"product_id,omitempty"`
+	ProductName         string                             `json:"product_name"`
+	ProductPrice        money.Money                        `json:"product_price"`
+	Quantity            int                                `json:"quantity"`
+	ModifierAmount      money.Money                        `json:"modifier_amount"` // Added to the product price per unit
+	Modifiers           []SelectedModifierResponse         `json:"modifiers,omitempty"`
+	Components          []TransactionItemComponentResponse `json:"components,omitempty"` // Stock taken from other products, e.g. a recipe's ingredients
+	Subtotal            money.Money                        `json:"subtotal"`
+	DiscountAmount      money.Money                        `json:"discount_amount"`       // Share of the transaction discount
+	ServiceChargeAmount money.Money                        `json:"service_charge_amount"` // Share of the transaction service charge
+	TaxClassID          string                             `json:"tax_class_id,omitempty"`
+	TaxRate             float64                            `json:"tax_rate"`
+	TaxInclusive        bool                               `json:"tax_inclusive"`
+	TaxableAmount       money.Money                        `json:"taxable_amount"`
+	TaxAmount           money.Money                        `json:"tax_amount"`
+}
+
+type TransactionItemComponentResponse struct {
+	ProductID   string      `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Quantity    int         `json:"quantity"`
+	Revenue     money.Money `json:"revenue,omitempty"` // Bundles only: the item's share of the line's sales after discount
 }
 
 type CreateTransactionRequest struct {
@@ -20,9 +45,21 @@ type CreateTransactionRequest struct {
 	Items               []TransactionItemRequest `json:"items" validate:"required,min=1,dive"`
 	PaymentMethod       string                   `json:"payment_method" validate:"required,oneof=cash card qris"`
 	CustomerName        string                   `json:"customer_name"`
-	DiscountAmount      float64                  `json:"discount_amount" validate:"gte=0"`
-	TaxAmount           float64                  `json:"tax_amount" validate:"gte=0"`
+	DiscountAmount      money.Money              `json:"discount_amount" validate:"gte=0"`
+	TaxAmount           money.Money              `json:"tax_amount" validate:"gte=0"` // Ignored, the server computes the tax per line
 	Notes               string                   `json:"notes"`
+	StoreID             string                   `json:"store_id,omitempty"`       // Only used for sales without a device, device sales go to the device's store
+	FinalAmount         *money.Money             `json:"final_amount,omitempty"`   // What the device charged, required on synced sales so the server's total can be checked
+	Signature           string                   `json:"signature,omitempty"`      // Ed25519 signature (base64) over the canonical payload, required for device sync
+	SigningKeyID        string                   `json:"signing_key_id,omitempty"` // Device signing key used to produce Signature
+	HeldOrderID         *uuid.UUID               `json:"-"`                        // Set when completing a held order, which is closed with the sale
+	HeldOrderUpdatedAt  time.Time                `json:"-"`                        // The cart as it was read, an edit since then fails the sale
+	TicketID            *uuid.UUID               `json:"-"`                        // Set when settling a ticket, which is closed with the sale
+	TicketUpdatedAt     time.Time                `json:"-"`                        // The ticket as it was read, a round or void since then fails the sale
+}
+
+type CancelTransactionRequest struct {
+	Reason string `json:"reason"`
 }
 
 type BulkSyncTransactionRequest struct {
@@ -30,37 +67,47 @@ type BulkSyncTransactionRequest struct {
 }
 
 type StockWarning struct {
-	ProductID       string `json:"product_id"`
-	ProductName     string `json:"product_name"`
-	SoldQuantity    int    `json:"sold_quantity"`
-	AvailableStock  int    `json:"available_stock"`
-	Shortage        int    `json:"shortage"`
-	Message         string `json:"message"`
+	ProductID      string `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	SoldQuantity   int    `json:"sold_quantity"`
+	AvailableStock int    `json:"available_stock"`
+	Shortage       int    `json:"shortage"`
+	Message        string `json:"message"`
 }
 
 type TransactionResponse struct {
-	ID                string                    `json:"id"`
-	TransactionCode   string                    `json:"transaction_code"`
-	UserID            string                    `json:"user_id,omitempty"`
-	Username          string                    `json:"username,omitempty"`
-	Items             []TransactionItemResponse `json:"items"`
-	TotalAmount       float64                   `json:"total_amount"`
-	DiscountAmount    float64                   `json:"discount_amount"`
-	TaxAmount         float64                   `json:"tax_amount"`
-	FinalAmount       float64                   `json:"final_amount"`
-	PaymentMethod     string                    `json:"payment_method"`
-	PaymentStatus     string                    `json:"payment_status"`
-	CustomerName      string                    `json:"customer_name,omitempty"`
-	Notes             string                    `json:"notes,omitempty"`
-	HasStockIssue     bool                      `json:"has_stock_issue"`
-	StockIssueDetails string                    `json:"stock_issue_details,omitempty"`
-	CreatedAt         string                    `json:"created_at"`
+	ID                  string                    `json:"id"`
+	TransactionCode     string                    `json:"transaction_code"`
+	UserID              string                    `json:"user_id,omitempty"`
+	Username            string                    `json:"username,omitempty"`
+	DeviceID            string                    `json:"device_id,omitempty"`
+	StoreID             string                    `json:"store_id,omitempty"`
+	Items               []TransactionItemResponse `json:"items"`
+	TotalAmount         money.Money               `json:"total_amount"`
+	DiscountAmount      money.Money               `json:"discount_amount"`
+	TaxAmount           money.Money               `json:"tax_amount"`
+	ServiceChargeRate   float64                   `json:"service_charge_rate"`
+	ServiceChargeAmount money.Money               `json:"service_charge_amount"`
+	RoundingAmount      money.Money               `json:"rounding_amount"` // Cash rounding, positive when rounded up
+	FinalAmount         money.Money               `json:"final_amount"`
+	PaymentMethod       string                    `json:"payment_method"`
+	PaymentStatus       string                    `json:"payment_status"`
+	CustomerName        string                    `json:"customer_name,omitempty"`
+	Notes               string                    `json:"notes,omitempty"`
+	HasStockIssue       bool                      `json:"has_stock_issue"`
+	StockIssueDetails   string                    `json:"stock_issue_details,omitempty"`
+	CancelledBy         string                    `json:"cancelled_by,omitempty"`
+	CancelledAt         string                    `json:"cancelled_at,omitempty"`
+	CancellationReason  string                    `json:"cancellation_reason,omitempty"`
+	CreatedAt           string                    `json:"created_at"`
 }
 
 type BulkSyncResponse struct {
-	SuccessCount int                   `json:"success_count"`
-	FailedCount  int                   `json:"failed_count"`
-	Warnings     []StockWarning        `json:"warnings,omitempty"`
-	Errors       []string              `json:"errors,omitempty"`
-	Transactions []TransactionResponse `json:"transactions"`
+	SuccessCount     int                   `json:"success_count"`
+	FailedCount      int                   `json:"failed_count"`
+	QuarantinedCount int                   `json:"quarantined_count"`
+	Warnings         []StockWarning        `json:"warnings,omitempty"`
+	Errors           []string              `json:"errors,omitempty"`
+	Quarantined      []string              `json:"quarantined,omitempty"` // Client transaction IDs held for review
+	Transactions     []TransactionResponse `json:"transactions"`
 }
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"pos-backend/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// actorFromContext builds the audit actor from the JWT claims and request metadata
+func actorFromContext(c *gin.Context) domain.Actor {
+	actor := domain.Actor{
+		Username:  c.GetString("username"),
+		IPAddress: c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}
+
+	if userID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		actor.UserID = userID
+	}
+
+	// Set by DeviceAuthMiddleware when the request came from a registered terminal
+	if deviceID, err := uuid.Parse(c.GetString("device_id")); err == nil {
+		actor.DeviceID = &deviceID
+	}
+
+	return actor
+}
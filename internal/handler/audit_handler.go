@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	// Build filters
+	filters := domain.AuditLogFilters{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+
+	// Filter by actor
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := uuid.Parse(actorIDStr)
+		if err == nil {
+			filters.ActorID = &actorID
+		}
+	}
+
+	// Filter by date range
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			// Set to end of day
+			endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+			filters.EndDate = &endDate
+		}
+	}
+
+	logs, total, err := h.auditService.GetAll(page, limit, filters)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get audit logs", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Audit logs retrieved successfully", logs, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+// Verify recomputes the hash chain to detect tampered or deleted entries
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.auditService.Verify()
+	if err != nil {
+		response.InternalServerError(c, "Failed to verify audit logs", err.Error())
+		return
+	}
+
+	response.Success(c, "Audit log verification completed", result)
+}
@@ -25,7 +25,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	result, err := h.authService.Register(&req)
+	result, err := h.authService.Register(&req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -73,7 +73,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.Create(&req)
+	category, err := h.categoryService.Create(&req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -91,7 +91,7 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.Update(id, &req)
+	category, err := h.categoryService.Update(id, &req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -103,7 +103,7 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 func (h *CategoryHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.categoryService.Delete(id); err != nil {
+	if err := h.categoryService.Delete(id, actorFromContext(c)); err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
@@ -1,80 +1,107 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/money"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type DashboardHandler struct {
-	db *gorm.DB
+	db             *gorm.DB
+	settingService *service.SettingService
+	reorderService service.ReorderService
+	eventBus       *service.EventBus
 }
 
-func NewDashboardHandler(db *gorm.DB) *DashboardHandler {
-	return &DashboardHandler{db: db}
+// Live stream tuning
+const (
+	streamBufferSize        = 64
+	streamHeartbeatInterval = 25 * time.Second // Keeps proxies from closing idle connections
+)
+
+func NewDashboardHandler(db *gorm.DB, settingService *service.SettingService, reorderService service.ReorderService, eventBus *service.EventBus) *DashboardHandler {
+	return &DashboardHandler{db: db, settingService: settingService, reorderService: reorderService, eventBus: eventBus}
 }
 
 // DashboardStats represents dashboard statistics
 type DashboardStats struct {
-	TodaySales          float64 `json:"today_sales"`
-	YesterdaySales      float64 `json:"yesterday_sales"`
-	SalesChange         float64 `json:"sales_change"`
-	TodayTransactions   int64   `json:"today_transactions"`
-	YesterdayTxns       int64   `json:"yesterday_transactions"`
-	TransactionsChange  float64 `json:"transactions_change"`
-	TodayProductsSold   int64   `json:"today_products_sold"`
-	YesterdayProdsSold  int64   `json:"yesterday_products_sold"`
-	ProductsSoldChange  float64 `json:"products_sold_change"`
-	AvgPerTransaction   float64 `json:"avg_per_transaction"`
-	YesterdayAvg        float64 `json:"yesterday_avg"`
-	AvgChange           float64 `json:"avg_change"`
+	TodaySales         money.Money `json:"today_sales"`
+	YesterdaySales     money.Money `json:"yesterday_sales"`
+	SalesChange        float64     `json:"sales_change"`
+	TodayTransactions  int64       `json:"today_transactions"`
+	YesterdayTxns      int64       `json:"yesterday_transactions"`
+	TransactionsChange float64     `json:"transactions_change"`
+	TodayProductsSold  int64       `json:"today_products_sold"`
+	YesterdayProdsSold int64       `json:"yesterday_products_sold"`
+	ProductsSoldChange float64     `json:"products_sold_change"`
+	AvgPerTransaction  money.Money `json:"avg_per_transaction"`
+	YesterdayAvg       money.Money `json:"yesterday_avg"`
+	AvgChange          float64     `json:"avg_change"`
 }
 
 // RecentTransaction represents a recent transaction summary
 type RecentTransaction struct {
-	ID              string    `json:"id"`
-	TransactionCode string    `json:"transaction_code"`
-	CustomerName    string    `json:"customer_name"`
-	FinalAmount     float64   `json:"final_amount"`
-	PaymentStatus   string    `json:"payment_status"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              string      `json:"id"`
+	TransactionCode string      `json:"transaction_code"`
+	CustomerName    string      `json:"customer_name"`
+	FinalAmount     money.Money `json:"final_amount"`
+	PaymentStatus   string      `json:"payment_status"`
+	CreatedAt       time.Time   `json:"created_at"`
 }
 
 // LowStockProduct represents a product with low stock
 type LowStockProduct struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	SKU   string `json:"sku"`
-	Stock int    `json:"stock"`
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	SKU               string   `json:"sku"`
+	Stock             int      `json:"stock"`
+	MinStock          int      `json:"min_stock"`
+	DaysOfCover       *float64 `json:"days_of_cover"`
+	SuggestedQuantity int      `json:"suggested_quantity"`
+	Status            string   `json:"status"`
 }
 
 // GetDashboardStats returns dashboard statistics
 func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
-	now := time.Now()
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	todayEnd := todayStart.Add(24 * time.Hour)
-	yesterdayStart := todayStart.Add(-24 * time.Hour)
-	yesterdayEnd := todayStart
+	// "Today" is the current business day in the store timezone
+	calendar := h.settingService.BusinessCalendar()
+	todayStart, todayEnd := calendar.Today()
+	yesterday := calendar.Format(todayStart.Add(-time.Minute))
 
 	stats := DashboardStats{}
 
+	// Yesterday is a closed business day, so its figures come from the daily summaries
+	var yesterdayStats struct {
+		Revenue          money.Money
+		TransactionCount int64
+		ItemsSold        int64
+	}
+	h.db.Table("daily_sales_summaries").
+		Where("business_date = ?", yesterday).
+		Select("COALESCE(SUM(revenue), 0) as revenue, COALESCE(SUM(transaction_count), 0) as transaction_count, COALESCE(SUM(items_sold), 0) as items_sold").
+		Scan(&yesterdayStats)
+	stats.YesterdaySales = yesterdayStats.Revenue
+	stats.YesterdayTxns = yesterdayStats.TransactionCount
+	stats.YesterdayProdsSold = yesterdayStats.ItemsSold
+
 	// Today's sales
 	h.db.Table("transactions").
 		Where("created_at >= ? AND created_at < ? AND payment_status = ?", todayStart, todayEnd, "completed").
 		Select("COALESCE(SUM(final_amount), 0)").
 		Scan(&stats.TodaySales)
 
-	// Yesterday's sales
-	h.db.Table("transactions").
-		Where("created_at >= ? AND created_at < ? AND payment_status = ?", yesterdayStart, yesterdayEnd, "completed").
-		Select("COALESCE(SUM(final_amount), 0)").
-		Scan(&stats.YesterdaySales)
-
 	// Calculate sales change percentage
 	if stats.YesterdaySales > 0 {
-		stats.SalesChange = ((stats.TodaySales - stats.YesterdaySales) / stats.YesterdaySales) * 100
+		stats.SalesChange = (stats.TodaySales.Sub(stats.YesterdaySales).Float64() / stats.YesterdaySales.Float64()) * 100
 	}
 
 	// Today's transactions count
@@ -82,11 +109,6 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 		Where("created_at >= ? AND created_at < ? AND payment_status = ?", todayStart, todayEnd, "completed").
 		Count(&stats.TodayTransactions)
 
-	// Yesterday's transactions count
-	h.db.Table("transactions").
-		Where("created_at >= ? AND created_at < ? AND payment_status = ?", yesterdayStart, yesterdayEnd, "completed").
-		Count(&stats.YesterdayTxns)
-
 	// Calculate transactions change percentage
 	if stats.YesterdayTxns > 0 {
 		stats.TransactionsChange = ((float64(stats.TodayTransactions) - float64(stats.YesterdayTxns)) / float64(stats.YesterdayTxns)) * 100
@@ -99,13 +121,6 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 		Select("COALESCE(SUM(quantity), 0)").
 		Scan(&stats.TodayProductsSold)
 
-	// Yesterday's products sold
-	h.db.Table("transaction_items").
-		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
-		Where("transactions.created_at >= ? AND transactions.created_at < ? AND transactions.payment_status = ?", yesterdayStart, yesterdayEnd, "completed").
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&stats.YesterdayProdsSold)
-
 	// Calculate products sold change percentage
 	if stats.YesterdayProdsSold > 0 {
 		stats.ProductsSoldChange = ((float64(stats.TodayProductsSold) - float64(stats.YesterdayProdsSold)) / float64(stats.YesterdayProdsSold)) * 100
@@ -113,17 +128,17 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 
 	// Average per transaction (today)
 	if stats.TodayTransactions > 0 {
-		stats.AvgPerTransaction = stats.TodaySales / float64(stats.TodayTransactions)
+		stats.AvgPerTransaction = stats.TodaySales.Div(stats.TodayTransactions, money.RoundHalfUp)
 	}
 
 	// Average per transaction (yesterday)
 	if stats.YesterdayTxns > 0 {
-		stats.YesterdayAvg = stats.YesterdaySales / float64(stats.YesterdayTxns)
+		stats.YesterdayAvg = stats.YesterdaySales.Div(stats.YesterdayTxns, money.RoundHalfUp)
 	}
 
 	// Calculate average change percentage
 	if stats.YesterdayAvg > 0 {
-		stats.AvgChange = ((stats.AvgPerTransaction - stats.YesterdayAvg) / stats.YesterdayAvg) * 100
+		stats.AvgChange = (stats.AvgPerTransaction.Sub(stats.YesterdayAvg).Float64() / stats.YesterdayAvg.Float64()) * 100
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -134,8 +149,7 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 
 // GetRecentTransactions returns recent transactions (today)
 func (h *DashboardHandler) GetRecentTransactions(c *gin.Context) {
-	now := time.Now()
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	todayStart, _ := h.settingService.BusinessCalendar().Today()
 
 	var transactions []RecentTransaction
 
@@ -161,17 +175,10 @@ func (h *DashboardHandler) GetRecentTransactions(c *gin.Context) {
 	})
 }
 
-// GetLowStockProducts returns products with low stock (stock <= 10)
+// GetLowStockProducts returns the ten most urgent products to reorder, based on
+// their MinStock and how fast they sell
 func (h *DashboardHandler) GetLowStockProducts(c *gin.Context) {
-	var products []LowStockProduct
-
-	err := h.db.Table("products").
-		Where("stock <= ? AND deleted_at IS NULL", 10).
-		Order("stock ASC").
-		Limit(10).
-		Select("id, name, sku, stock").
-		Scan(&products).Error
-
+	suggestions, err := h.reorderService.GetSuggestions(nil, true)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -181,8 +188,92 @@ func (h *DashboardHandler) GetLowStockProducts(c *gin.Context) {
 		return
 	}
 
+	products := []LowStockProduct{}
+	for _, suggestion := range suggestions {
+		if len(products) == 10 {
+			break
+		}
+		products = append(products, LowStockProduct{
+			ID:                suggestion.ProductID,
+			Name:              suggestion.ProductName,
+			SKU:               suggestion.SKU,
+			Stock:             suggestion.Stock,
+			MinStock:          suggestion.MinStock,
+			DaysOfCover:       suggestion.DaysOfCover,
+			SuggestedQuantity: suggestion.SuggestedQuantity,
+			Status:            suggestion.Status,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    products,
 	})
 }
+
+// Stream pushes sales, cancellations, stock issues and low-stock alerts as
+// Server-Sent Events. Pass store_id to only receive one store's activity.
+// Admins and managers see everything; cashiers only see their own sales plus
+// alerts that aren't tied to a cashier.
+func (h *DashboardHandler) Stream(c *gin.Context) {
+	var storeID *uuid.UUID
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		id, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return
+		}
+		storeID = &id
+	}
+
+	role := c.GetString("role")
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+
+	sub := h.eventBus.Subscribe(func(event service.Event) bool {
+		if storeID != nil && event.StoreID != nil && *event.StoreID != *storeID {
+			return false
+		}
+		if role != "admin" && role != "manager" && event.UserID != nil && *event.UserID != userID {
+			return false
+		}
+		return true
+	}, streamBufferSize)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Tell the client it's connected before the first event arrives
+	fmt.Fprint(c.Writer, "retry: 5000\nevent: ready\ndata: {}\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Warning: failed to encode %s event: %v", event.Type, err)
+				return true
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DeviceHandler struct {
+	deviceService service.DeviceService
+}
+
+func NewDeviceHandler(deviceService service.DeviceService) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: deviceService,
+	}
+}
+
+func (h *DeviceHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	devices, total, err := h.deviceService.GetAll(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get devices", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Devices retrieved successfully", devices, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *DeviceHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	device, err := h.deviceService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Device retrieved successfully", device)
+}
+
+func (h *DeviceHandler) Register(c *gin.Context) {
+	var req dto.CreateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	device, err := h.deviceService.Register(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Device registered successfully", device)
+}
+
+func (h *DeviceHandler) RegeneratePairingCode(c *gin.Context) {
+	id := c.Param("id")
+
+	device, err := h.deviceService.RegeneratePairingCode(id, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Pairing code generated successfully", device)
+}
+
+func (h *DeviceHandler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+
+	device, err := h.deviceService.Revoke(id, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Device revoked successfully", device)
+}
+
+// AssignStore moves a device to a store, an empty store_id detaches it
+func (h *DeviceHandler) AssignStore(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.AssignDeviceStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	device, err := h.deviceService.AssignStore(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Device store updated successfully", device)
+}
+
+// Pair is called by the terminal itself to exchange its pairing code for credentials
+func (h *DeviceHandler) Pair(c *gin.Context) {
+	var req dto.PairDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	credentials, err := h.deviceService.Pair(&req, c.ClientIP())
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Device paired successfully", credentials)
+}
+
+// GetConfig returns the configuration for the authenticated device
+func (h *DeviceHandler) GetConfig(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.GetString("device_id"))
+	if err != nil {
+		response.Unauthorized(c, "Invalid device")
+		return
+	}
+
+	config, err := h.deviceService.GetConfig(deviceID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get device config", err.Error())
+		return
+	}
+
+	response.Success(c, "Device config retrieved successfully", config)
+}
+
+// RegisterSigningKey lets the authenticated device rotate the key it signs offline sales with
+func (h *DeviceHandler) RegisterSigningKey(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.GetString("device_id"))
+	if err != nil {
+		response.Unauthorized(c, "Invalid device")
+		return
+	}
+
+	var req dto.RegisterSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	key, err := h.deviceService.RegisterSigningKey(deviceID, &req, c.ClientIP())
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Signing key registered successfully", key)
+}
+
+func (h *DeviceHandler) GetSigningKeys(c *gin.Context) {
+	id := c.Param("id")
+
+	keys, err := h.deviceService.GetSigningKeys(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Signing keys retrieved successfully", keys)
+}
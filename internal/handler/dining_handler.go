@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DiningHandler struct {
+	diningService service.DiningService
+}
+
+func NewDiningHandler(diningService service.DiningService) *DiningHandler {
+	return &DiningHandler{
+		diningService: diningService,
+	}
+}
+
+func (h *DiningHandler) GetAreas(c *gin.Context) {
+	storeID, ok := optionalUUIDQuery(c, "store_id")
+	if !ok {
+		return
+	}
+
+	areas, err := h.diningService.GetAreas(storeID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get dining areas", err.Error())
+		return
+	}
+
+	response.Success(c, "Dining areas retrieved successfully", areas)
+}
+
+func (h *DiningHandler) CreateArea(c *gin.Context) {
+	var req dto.CreateDiningAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	area, err := h.diningService.CreateArea(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Dining area created successfully", area)
+}
+
+func (h *DiningHandler) UpdateArea(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateDiningAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	area, err := h.diningService.UpdateArea(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Dining area updated successfully", area)
+}
+
+func (h *DiningHandler) DeleteArea(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.diningService.DeleteArea(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Dining area deleted successfully", nil)
+}
+
+func (h *DiningHandler) GetTables(c *gin.Context) {
+	storeID, ok := optionalUUIDQuery(c, "store_id")
+	if !ok {
+		return
+	}
+	areaID, ok := optionalUUIDQuery(c, "area_id")
+	if !ok {
+		return
+	}
+
+	tables, err := h.diningService.GetTables(storeID, areaID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get dining tables", err.Error())
+		return
+	}
+
+	response.Success(c, "Dining tables retrieved successfully", tables)
+}
+
+func (h *DiningHandler) GetTableByID(c *gin.Context) {
+	id := c.Param("id")
+
+	table, err := h.diningService.GetTableByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Dining table retrieved successfully", table)
+}
+
+func (h *DiningHandler) CreateTable(c *gin.Context) {
+	var req dto.CreateDiningTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	table, err := h.diningService.CreateTable(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Dining table created successfully", table)
+}
+
+func (h *DiningHandler) UpdateTable(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateDiningTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	table, err := h.diningService.UpdateTable(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Dining table updated successfully", table)
+}
+
+func (h *DiningHandler) DeleteTable(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.diningService.DeleteTable(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Dining table deleted successfully", nil)
+}
+
+// optionalUUIDQuery parses an optional ID filter, answering 400 when it is malformed
+func optionalUUIDQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format", nil)
+		return nil, false
+	}
+	return &id, true
+}
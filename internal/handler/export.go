@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"pos-backend/pkg/export"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat picks csv or xlsx from ?format= or the Accept header. An empty
+// format means the caller wants the normal JSON response.
+func exportFormat(c *gin.Context) (export.Format, error) {
+	if value := c.Query("format"); value != "" && value != "json" {
+		return export.ParseFormat(value)
+	}
+
+	for _, mediaType := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+		if format, ok := export.FormatFromContentType(mediaType); ok {
+			return format, nil
+		}
+	}
+
+	return "", nil
+}
+
+// streamExport sends the download headers, writes the report header block and
+// lets write stream the rows. Once headers are sent the status is committed, so
+// failures past that point can only be logged.
+func streamExport(c *gin.Context, format export.Format, fileName string, block export.HeaderBlock, write func(w export.Writer) error) {
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, fileName, time.Now().Format("20060102-150405"), format.Extension()))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, block.Title)
+	if err == nil {
+		block.GeneratedAt = time.Now()
+		err = export.WriteHeaderBlock(writer, block)
+	}
+	if err == nil {
+		err = write(writer)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Printf("Warning: export %s failed while streaming: %v", fileName, err)
+	}
+}
+
+// settingString reads a JSON-encoded string setting value
+func settingString(value string) string {
+	var s string
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return value
+	}
+	return s
+}
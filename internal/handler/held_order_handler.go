@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HeldOrderHandler struct {
+	heldOrderService service.HeldOrderService
+}
+
+func NewHeldOrderHandler(heldOrderService service.HeldOrderService) *HeldOrderHandler {
+	return &HeldOrderHandler{
+		heldOrderService: heldOrderService,
+	}
+}
+
+func (h *HeldOrderHandler) Hold(c *gin.Context) {
+	var req dto.CreateHeldOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	order, err := h.heldOrderService.Hold(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Order held successfully", order)
+}
+
+func (h *HeldOrderHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	// Open orders unless asked otherwise
+	filters := domain.HeldOrderFilters{Status: c.DefaultQuery("status", domain.HeldOrderStatusHeld)}
+	if filters.Status == "all" {
+		filters.Status = ""
+	}
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		storeID, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid store_id format", nil)
+			return
+		}
+		filters.StoreID = &storeID
+	}
+
+	orders, total, err := h.heldOrderService.GetAll(page, limit, filters)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get held orders", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Held orders retrieved successfully", orders, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *HeldOrderHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	order, err := h.heldOrderService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Held order retrieved successfully", order)
+}
+
+func (h *HeldOrderHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateHeldOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	order, err := h.heldOrderService.Update(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Held order updated successfully", order)
+}
+
+func (h *HeldOrderHandler) Resume(c *gin.Context) {
+	id := c.Param("id")
+
+	order, err := h.heldOrderService.Resume(id, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Held order resumed", order)
+}
+
+func (h *HeldOrderHandler) Complete(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.CompleteHeldOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	actor := actorFromContext(c)
+	if actor.UserID == uuid.Nil {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	transaction, warnings, err := h.heldOrderService.Complete(id, &req, actor)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	if len(warnings) > 0 {
+		response.SuccessWithWarnings(c, "Held order completed with stock warnings", transaction, warnings)
+		return
+	}
+
+	response.Success(c, "Held order completed successfully", transaction)
+}
+
+func (h *HeldOrderHandler) Cancel(c *gin.Context) {
+	id := c.Param("id")
+
+	order, err := h.heldOrderService.Cancel(id, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Held order cancelled", order)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+	reorderService   service.ReorderService
+	settingService   *service.SettingService
+}
+
+func NewInventoryHandler(inventoryService service.InventoryService, reorderService service.ReorderService, settingService *service.SettingService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		reorderService:   reorderService,
+		settingService:   settingService,
+	}
+}
+
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	// Build filters
+	filters := domain.InventoryMovementFilters{
+		MovementType:  c.Query("movement_type"),
+		ReferenceType: c.Query("reference_type"),
+	}
+
+	// Filter by product
+	if productIDStr := c.Query("product_id"); productIDStr != "" {
+		productID, err := uuid.Parse(productIDStr)
+		if err == nil {
+			filters.ProductID = &productID
+		}
+	}
+
+	// Filter by business-day range in the store timezone
+	startDate, endDate, err := h.settingService.BusinessCalendar().Range(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+	filters.StartDate = startDate
+	filters.EndDate = endDate
+
+	movements, total, err := h.inventoryService.GetMovements(page, limit, filters)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get inventory movements", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Inventory movements retrieved successfully", movements, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+// Receive books goods received from a supplier at their purchase cost
+func (h *InventoryHandler) Receive(c *gin.Context) {
+	var req dto.ReceiveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	movement, err := h.inventoryService.Receive(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Stock received successfully", movement)
+}
+
+func (h *InventoryHandler) Adjustment(c *gin.Context) {
+	var req dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	movement, err := h.inventoryService.Adjust(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Stock adjusted successfully", movement)
+}
+
+// GetValuation values stock as of a date (end of that day) or now
+func (h *InventoryHandler) GetValuation(c *gin.Context) {
+	asOf := time.Now()
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		_, end, err := h.settingService.BusinessCalendar().Range("", asOfStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid as_of date, use YYYY-MM-DD", nil)
+			return
+		}
+		// Everything before the next business day starts
+		asOf = end.Add(-time.Microsecond)
+	}
+
+	var productID *uuid.UUID
+	if productIDStr := c.Query("product_id"); productIDStr != "" {
+		id, err := uuid.Parse(productIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid product ID format", nil)
+			return
+		}
+		productID = &id
+	}
+
+	valuation, err := h.inventoryService.GetValuation(asOf, productID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get inventory valuation", err.Error())
+		return
+	}
+
+	response.Success(c, "Inventory valuation retrieved successfully", valuation)
+}
+
+// GetReorderSuggestions lists products with their sales velocity, days of cover
+// and suggested order quantity. Pass all=true to include products that are fine.
+func (h *InventoryHandler) GetReorderSuggestions(c *gin.Context) {
+	var categoryID *uuid.UUID
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		id, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid category ID format", nil)
+			return
+		}
+		categoryID = &id
+	}
+
+	suggestions, err := h.reorderService.GetSuggestions(categoryID, c.Query("all") != "true")
+	if err != nil {
+		response.InternalServerError(c, "Failed to get reorder suggestions", err.Error())
+		return
+	}
+
+	response.Success(c, "Reorder suggestions retrieved successfully", suggestions)
+}
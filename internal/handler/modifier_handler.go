@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModifierHandler struct {
+	modifierService service.ModifierService
+}
+
+func NewModifierHandler(modifierService service.ModifierService) *ModifierHandler {
+	return &ModifierHandler{
+		modifierService: modifierService,
+	}
+}
+
+func (h *ModifierHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	groups, total, err := h.modifierService.GetAll(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get modifier groups", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Modifier groups retrieved successfully", groups, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *ModifierHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	group, err := h.modifierService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Modifier group retrieved successfully", group)
+}
+
+func (h *ModifierHandler) Create(c *gin.Context) {
+	var req dto.CreateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	group, err := h.modifierService.Create(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Modifier group created successfully", group)
+}
+
+func (h *ModifierHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	group, err := h.modifierService.Update(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Modifier group updated successfully", group)
+}
+
+func (h *ModifierHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.modifierService.Delete(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Modifier group deleted successfully", nil)
+}
+
+func (h *ModifierHandler) GetProductGroups(c *gin.Context) {
+	id := c.Param("id")
+
+	groups, err := h.modifierService.GetProductGroups(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Product modifier groups retrieved successfully", groups)
+}
+
+func (h *ModifierHandler) SetProductGroups(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SetProductModifierGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	groups, err := h.modifierService.SetProductGroups(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Product modifier groups updated successfully", groups)
+}
@@ -80,6 +80,28 @@ func (h *ProductHandler) GetByCategory(c *gin.Context) {
 	})
 }
 
+func (h *ProductHandler) GetBundles(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	products, total, err := h.productService.GetBundles(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get bundles", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Bundles retrieved successfully", products, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
 func (h *ProductHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -111,7 +133,7 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.Create(&req)
+	product, err := h.productService.Create(&req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -129,7 +151,7 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.Update(id, &req)
+	product, err := h.productService.Update(id, &req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -141,7 +163,7 @@ func (h *ProductHandler) Update(c *gin.Context) {
 func (h *ProductHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.productService.Delete(id); err != nil {
+	if err := h.productService.Delete(id, actorFromContext(c)); err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
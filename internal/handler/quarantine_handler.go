@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuarantineHandler struct {
+	quarantineService service.QuarantineService
+}
+
+func NewQuarantineHandler(quarantineService service.QuarantineService) *QuarantineHandler {
+	return &QuarantineHandler{
+		quarantineService: quarantineService,
+	}
+}
+
+func (h *QuarantineHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	entries, total, err := h.quarantineService.GetAll(page, limit, status)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get quarantined transactions", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Quarantined transactions retrieved successfully", entries, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *QuarantineHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	entry, err := h.quarantineService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Quarantined transaction retrieved successfully", entry)
+}
+
+func (h *QuarantineHandler) Approve(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ReviewQuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	transaction, err := h.quarantineService.Approve(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Quarantined transaction approved and posted", transaction)
+}
+
+func (h *QuarantineHandler) Reject(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ReviewQuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	entry, err := h.quarantineService.Reject(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Quarantined transaction rejected", entry)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecipeHandler struct {
+	recipeService service.RecipeService
+}
+
+func NewRecipeHandler(recipeService service.RecipeService) *RecipeHandler {
+	return &RecipeHandler{
+		recipeService: recipeService,
+	}
+}
+
+func (h *RecipeHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	recipes, total, err := h.recipeService.GetAll(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get recipes", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Recipes retrieved successfully", recipes, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *RecipeHandler) GetByProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	recipe, err := h.recipeService.GetByProduct(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Recipe retrieved successfully", recipe)
+}
+
+func (h *RecipeHandler) Save(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SaveRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	recipe, err := h.recipeService.Save(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Recipe saved successfully", recipe)
+}
+
+func (h *RecipeHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.recipeService.Delete(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Recipe deleted successfully", nil)
+}
@@ -1,103 +1,128 @@
 package handler
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/businessday"
+	"pos-backend/pkg/export"
+	"pos-backend/pkg/money"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type ReportsHandler struct {
-	db *gorm.DB
+	db             *gorm.DB
+	settingService *service.SettingService
 }
 
-func NewReportsHandler(db *gorm.DB) *ReportsHandler {
-	return &ReportsHandler{db: db}
+func NewReportsHandler(db *gorm.DB, settingService *service.SettingService) *ReportsHandler {
+	return &ReportsHandler{db: db, settingService: settingService}
 }
 
 // Sales Summary Response
 type SalesSummaryResponse struct {
-	TotalRevenue       float64 `json:"total_revenue"`
-	TotalTransactions  int64   `json:"total_transactions"`
-	TotalProductsSold  int64   `json:"total_products_sold"`
-	AverageOrderValue  float64 `json:"average_order_value"`
+	TotalRevenue      money.Money `json:"total_revenue"`
+	TotalTransactions int64       `json:"total_transactions"`
+	TotalProductsSold int64       `json:"total_products_sold"`
+	AverageOrderValue money.Money `json:"average_order_value"`
 }
 
 // Top Product Response
 type TopProductResponse struct {
-	ProductID     string  `json:"product_id"`
-	ProductName   string  `json:"product_name"`
-	SKU           string  `json:"sku"`
-	TotalQuantity int64   `json:"total_quantity"`
-	TotalRevenue  float64 `json:"total_revenue"`
-	AvgPrice      float64 `json:"avg_price"`
+	ProductID     string      `json:"product_id"`
+	ProductName   string      `json:"product_name"`
+	SKU           string      `json:"sku"`
+	TotalQuantity int64       `json:"total_quantity"`
+	TotalRevenue  money.Money `json:"total_revenue"`
+	AvgPrice      money.Money `json:"avg_price"`
 }
 
 // Sales by Payment Method Response
 type PaymentMethodResponse struct {
-	PaymentMethod    string  `json:"payment_method"`
-	TotalAmount      float64 `json:"total_amount"`
-	TransactionCount int64   `json:"transaction_count"`
-	Percentage       float64 `json:"percentage"`
+	PaymentMethod    string      `json:"payment_method"`
+	TotalAmount      money.Money `json:"total_amount"`
+	TransactionCount int64       `json:"transaction_count"`
+	Percentage       float64     `json:"percentage"`
 }
 
 // Daily Sales Response
 type DailySalesResponse struct {
-	Date             string  `json:"date"`
-	TotalRevenue     float64 `json:"total_revenue"`
-	TransactionCount int64   `json:"transaction_count"`
+	Date             string      `json:"date"`
+	TotalRevenue     money.Money `json:"total_revenue"`
+	TransactionCount int64       `json:"transaction_count"`
 }
 
 // GetSalesSummary returns overall sales summary
 func (h *ReportsHandler) GetSalesSummary(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
-
-	query := h.db.Table("transactions").
-		Where("payment_status = ?", "completed").
-		Where("deleted_at IS NULL")
-
-	// Apply date filters
-	if startDate != "" {
-		query = query.Where("DATE(created_at) >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("DATE(created_at) <= ?", endDate)
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
 	}
 
-	var summary SalesSummaryResponse
-
-	// Get total revenue and transaction count
-	var result struct {
-		TotalRevenue      float64
-		TotalTransactions int64
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
 	}
 
-	query.Select("COALESCE(SUM(final_amount), 0) as total_revenue, COUNT(*) as total_transactions").
-		Scan(&result)
-
-	summary.TotalRevenue = result.TotalRevenue
-	summary.TotalTransactions = result.TotalTransactions
-
-	// Get total products sold
-	itemQuery := h.db.Table("transaction_items").
-		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
-		Where("transactions.payment_status = ?", "completed").
-		Where("transactions.deleted_at IS NULL")
-
-	if startDate != "" {
-		itemQuery = itemQuery.Where("DATE(transactions.created_at) >= ?", startDate)
-	}
-	if endDate != "" {
-		itemQuery = itemQuery.Where("DATE(transactions.created_at) <= ?", endDate)
+	var summary SalesSummaryResponse
+	err = h.db.Table("(?) as sales", h.salesSource(calendar, start, end)).
+		Select(`
+			COALESCE(SUM(revenue), 0) as total_revenue,
+			COALESCE(SUM(transaction_count), 0) as total_transactions,
+			COALESCE(SUM(items_sold), 0) as total_products_sold
+		`).
+		Scan(&summary).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch sales summary",
+			"error":   err.Error(),
+		})
+		return
 	}
 
-	itemQuery.Select("COALESCE(SUM(quantity), 0)").Scan(&summary.TotalProductsSold)
-
 	// Calculate average order value
 	if summary.TotalTransactions > 0 {
-		summary.AverageOrderValue = summary.TotalRevenue / float64(summary.TotalTransactions)
+		summary.AverageOrderValue = summary.TotalRevenue.Div(summary.TotalTransactions, money.RoundHalfUp)
+	}
+
+	if format != "" {
+		streamExport(c, format, "sales-summary", h.exportHeader("Sales Summary", startDate, endDate), func(w export.Writer) error {
+			rows := [][]export.Cell{
+				{export.Text("Total Revenue"), export.Money(summary.TotalRevenue)},
+				{export.Text("Total Transactions"), export.Int(summary.TotalTransactions)},
+				{export.Text("Total Products Sold"), export.Int(summary.TotalProductsSold)},
+				{export.Text("Average Order Value"), export.Money(summary.AverageOrderValue)},
+			}
+			if err := w.WriteHeader("Metric", "Value"); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				if err := w.WriteRow(row...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -106,40 +131,76 @@ func (h *ReportsHandler) GetSalesSummary(c *gin.Context) {
 	})
 }
 
-// GetTopProducts returns best selling products
+// GetTopProducts returns best selling products, the top 10 unless limit says
+// otherwise. Exports list every product unless a limit is given.
 func (h *ReportsHandler) GetTopProducts(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
-	limit := c.DefaultQuery("limit", "10")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
 
-	query := h.db.Table("transaction_items").
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	limit := 0
+	if format == "" {
+		limit = 10
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+	}
+
+	// The average price is revenue per unit, weighted by quantity
+	query := h.db.Table("(?) as sales", h.productSalesSource(calendar, start, end)).
 		Select(`
-			transaction_items.product_id,
-			transaction_items.product_name,
+			NULLIF(sales.product_id, ?) as product_id,
+			sales.product_name,
 			products.sku,
-			SUM(transaction_items.quantity) as total_quantity,
-			SUM(transaction_items.subtotal) as total_revenue,
-			AVG(transaction_items.product_price) as avg_price
-		`).
-		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
-		Joins("LEFT JOIN products ON products.id = transaction_items.product_id").
-		Where("transactions.payment_status = ?", "completed").
-		Where("transactions.deleted_at IS NULL").
-		Group("transaction_items.product_id, transaction_items.product_name, products.sku").
-		Order("total_quantity DESC").
-		Limit(10)
-
-	// Apply date filters
-	if startDate != "" {
-		query = query.Where("DATE(transactions.created_at) >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("DATE(transactions.created_at) <= ?", endDate)
+			SUM(sales.quantity) as total_quantity,
+			SUM(sales.revenue) as total_revenue,
+			ROUND(SUM(sales.revenue) / NULLIF(SUM(sales.quantity), 0), 2) as avg_price
+		`, uuid.Nil).
+		Joins("LEFT JOIN products ON products.id = sales.product_id").
+		Group("sales.product_id, sales.product_name, products.sku").
+		Having("SUM(sales.quantity) > 0").
+		Order("total_quantity DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
 	}
 
-	// Override limit if provided
-	if limit != "10" {
-		query = query.Limit(10) // You can parse limit string to int if needed
+	if format != "" {
+		streamExport(c, format, "top-products", h.exportHeader("Top Products", startDate, endDate), func(w export.Writer) error {
+			if err := w.WriteHeader("Product ID", "Product", "SKU", "Quantity", "Revenue", "Average Price"); err != nil {
+				return err
+			}
+			return h.streamRows(query, func(scan func(dest interface{}) error) error {
+				var row TopProductResponse
+				if err := scan(&row); err != nil {
+					return err
+				}
+				return w.WriteRow(export.Text(row.ProductID), export.Text(row.ProductName), export.Text(row.SKU), export.Int(row.TotalQuantity), export.Money(row.TotalRevenue), export.Money(row.AvgPrice))
+			})
+		})
+		return
 	}
 
 	var topProducts []TopProductResponse
@@ -162,24 +223,66 @@ func (h *ReportsHandler) GetTopProducts(c *gin.Context) {
 func (h *ReportsHandler) GetSalesByPaymentMethod(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
 
-	query := h.db.Table("transactions").
+	query := h.db.Table("(?) as sales", h.salesSource(calendar, start, end)).
 		Select(`
 			payment_method,
-			SUM(final_amount) as total_amount,
-			COUNT(*) as transaction_count
+			SUM(revenue) as total_amount,
+			SUM(transaction_count) as transaction_count
 		`).
-		Where("payment_status = ?", "completed").
-		Where("deleted_at IS NULL").
 		Group("payment_method").
+		Having("SUM(transaction_count) > 0").
 		Order("total_amount DESC")
 
-	// Apply date filters
-	if startDate != "" {
-		query = query.Where("DATE(created_at) >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("DATE(created_at) <= ?", endDate)
+	if format != "" {
+		// The share of each method needs the grand total before the rows are streamed
+		var total money.Money
+		err := h.db.Table("(?) as sales", h.salesSource(calendar, start, end)).
+			Select("COALESCE(SUM(revenue), 0)").
+			Scan(&total).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to fetch payment methods data",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		streamExport(c, format, "sales-by-payment", h.exportHeader("Sales by Payment Method", startDate, endDate), func(w export.Writer) error {
+			if err := w.WriteHeader("Payment Method", "Total Amount", "Transactions", "Percentage"); err != nil {
+				return err
+			}
+			return h.streamRows(query, func(scan func(dest interface{}) error) error {
+				var row PaymentMethodResponse
+				if err := scan(&row); err != nil {
+					return err
+				}
+				if total > 0 {
+					row.Percentage = (row.TotalAmount.Float64() / total.Float64()) * 100
+				}
+				return w.WriteRow(export.Text(row.PaymentMethod), export.Money(row.TotalAmount), export.Int(row.TransactionCount), export.Float(row.Percentage))
+			})
+		})
+		return
 	}
 
 	var paymentMethods []PaymentMethodResponse
@@ -193,15 +296,15 @@ func (h *ReportsHandler) GetSalesByPaymentMethod(c *gin.Context) {
 	}
 
 	// Calculate total for percentage
-	var total float64
+	var total money.Money
 	for _, pm := range paymentMethods {
-		total += pm.TotalAmount
+		total = total.Add(pm.TotalAmount)
 	}
 
 	// Calculate percentage
 	for i := range paymentMethods {
 		if total > 0 {
-			paymentMethods[i].Percentage = (paymentMethods[i].TotalAmount / total) * 100
+			paymentMethods[i].Percentage = (paymentMethods[i].TotalAmount.Float64() / total.Float64()) * 100
 		}
 	}
 
@@ -215,28 +318,63 @@ func (h *ReportsHandler) GetSalesByPaymentMethod(c *gin.Context) {
 func (h *ReportsHandler) GetDailySales(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
 
 	// Default to last 30 days if no dates provided
+	calendar := h.settingService.BusinessCalendar()
 	if startDate == "" {
-		startDate = time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+		startDate = calendar.Format(time.Now().AddDate(0, 0, -30))
 	}
 	if endDate == "" {
-		endDate = time.Now().Format("2006-01-02")
+		endDate = calendar.Format(time.Now())
+	}
+
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
 	}
 
-	query := h.db.Table("transactions").
+	query := h.db.Table("(?) as sales", h.salesSource(calendar, start, end)).
 		Select(`
-			DATE(created_at) as date,
-			SUM(final_amount) as total_revenue,
-			COUNT(*) as transaction_count
+			business_date as date,
+			SUM(revenue) as total_revenue,
+			SUM(transaction_count) as transaction_count
 		`).
-		Where("payment_status = ?", "completed").
-		Where("deleted_at IS NULL").
-		Where("DATE(created_at) >= ?", startDate).
-		Where("DATE(created_at) <= ?", endDate).
-		Group("DATE(created_at)").
+		Group("business_date").
+		Having("SUM(transaction_count) > 0").
 		Order("date ASC")
 
+	if format != "" {
+		streamExport(c, format, "daily-sales", h.exportHeader("Daily Sales", startDate, endDate), func(w export.Writer) error {
+			if err := w.WriteHeader("Date", "Revenue", "Transactions"); err != nil {
+				return err
+			}
+			return h.streamRows(query, func(scan func(dest interface{}) error) error {
+				var row struct {
+					Date             time.Time
+					TotalRevenue     money.Money
+					TransactionCount int64
+				}
+				if err := scan(&row); err != nil {
+					return err
+				}
+				return w.WriteRow(export.Date(row.Date), export.Money(row.TotalRevenue), export.Int(row.TransactionCount))
+			})
+		})
+		return
+	}
+
 	var dailySales []DailySalesResponse
 	if err := query.Scan(&dailySales).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -252,3 +390,1595 @@ func (h *ReportsHandler) GetDailySales(c *gin.Context) {
 		"data":    dailySales,
 	})
 }
+
+// Category Sales Response
+type CategorySalesResponse struct {
+	CategoryID       string      `json:"category_id"` // Empty for uncategorized products
+	CategoryName     string      `json:"category_name"`
+	QuantitySold     int64       `json:"quantity_sold"`
+	Revenue          money.Money `json:"revenue"`
+	Cost             money.Money `json:"cost"`
+	GrossProfit      money.Money `json:"gross_profit"`
+	MarginPercent    float64     `json:"margin_percent"`
+	ProductCount     int64       `json:"product_count"`
+	TransactionCount int64       `json:"transaction_count"`
+	Percentage       float64     `json:"percentage"`
+}
+
+// Category Product Sales Response
+type CategoryProductSalesResponse struct {
+	ProductID     string      `json:"product_id"` // Empty when the product row no longer exists
+	ProductName   string      `json:"product_name"`
+	SKU           string      `json:"sku"`
+	Deleted       bool        `json:"deleted"`
+	QuantitySold  int64       `json:"quantity_sold"`
+	Revenue       money.Money `json:"revenue"`
+	Cost          money.Money `json:"cost"`
+	GrossProfit   money.Money `json:"gross_profit"`
+	MarginPercent float64     `json:"margin_percent"`
+	Percentage    float64     `json:"percentage"` // Share of the category's revenue
+}
+
+// uncategorizedKey selects products without a category in the drill-down
+const uncategorizedKey = "uncategorized"
+
+// GetSalesByCategory returns revenue, quantity and margin per category. Lines
+// count towards the category their product was in when sold, so moving or
+// deleting products later doesn't change past figures, and a category renamed
+// since shows up under each name. Revenue is line subtotals before transaction
+// discounts. It reads the transaction items rather than the daily summaries,
+// which can't tell how many distinct sales a category appeared in.
+func (h *ReportsHandler) GetSalesByCategory(c *gin.Context) {
+	query, ok := h.categorySalesQuery(c)
+	if !ok {
+		return
+	}
+
+	query = query.
+		Select(fmt.Sprintf(`
+			COALESCE(transaction_items.category_id::text, '') as category_id,
+			%s as category_name,
+			COALESCE(SUM(transaction_items.quantity), 0) as quantity_sold,
+			COALESCE(SUM(transaction_items.subtotal), 0) as revenue,
+			COALESCE(SUM(transaction_items.total_cost), 0) as cost,
+			COUNT(DISTINCT COALESCE(transaction_items.product_id::text, transaction_items.product_name)) as product_count,
+			COUNT(DISTINCT transactions.id) as transaction_count
+		`, snapshotCategoryName)).
+		Group("1, 2").
+		Order("revenue DESC")
+
+	var categories []CategorySalesResponse
+	if err := query.Scan(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch category sales",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Calculate total for percentage
+	var total money.Money
+	for _, category := range categories {
+		total = total.Add(category.Revenue)
+	}
+
+	for i := range categories {
+		categories[i].GrossProfit = categories[i].Revenue.Sub(categories[i].Cost)
+		if categories[i].Revenue > 0 {
+			categories[i].MarginPercent = (categories[i].GrossProfit.Float64() / categories[i].Revenue.Float64()) * 100
+		}
+		if total > 0 {
+			categories[i].Percentage = (categories[i].Revenue.Float64() / total.Float64()) * 100
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    categories,
+	})
+}
+
+// GetCategoryProductSales drills down from a category to its products. Use
+// "uncategorized" as the category ID for products without a category. Closed
+// days come from the daily summaries.
+func (h *ReportsHandler) GetCategoryProductSales(c *gin.Context) {
+	categoryParam := c.Param("category_id")
+
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Uncategorized lines are kept under the nil UUID
+	categoryID := uuid.Nil
+	if categoryParam != uncategorizedKey {
+		categoryID, err = uuid.Parse(categoryParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid category ID format",
+			})
+			return
+		}
+	}
+
+	// Products that were hard deleted are told apart by the name captured on the line
+	query := h.db.Table("(?) as sales", h.productSalesSource(calendar, start, end)).
+		Select(`
+			COALESCE(NULLIF(sales.product_id, ?)::text, '') as product_id,
+			MAX(COALESCE(products.name, sales.product_name)) as product_name,
+			COALESCE(MAX(products.sku), '') as sku,
+			BOOL_OR(products.id IS NULL OR products.deleted_at IS NOT NULL) as deleted,
+			COALESCE(SUM(sales.quantity), 0) as quantity_sold,
+			COALESCE(SUM(sales.revenue), 0) as revenue,
+			COALESCE(SUM(sales.cost), 0) as cost
+		`, uuid.Nil).
+		Joins("LEFT JOIN products ON products.id = sales.product_id").
+		Where("sales.category_id = ?", categoryID).
+		Group("sales.product_id, CASE WHEN products.id IS NULL THEN sales.product_name END").
+		Order("revenue DESC")
+
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		storeID, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return
+		}
+		query = query.Where("sales.store_id = ?", storeID)
+	}
+
+	var products []CategoryProductSalesResponse
+	if err := query.Scan(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch category product sales",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Calculate total for percentage
+	var total money.Money
+	for _, product := range products {
+		total = total.Add(product.Revenue)
+	}
+
+	for i := range products {
+		products[i].GrossProfit = products[i].Revenue.Sub(products[i].Cost)
+		if products[i].Revenue > 0 {
+			products[i].MarginPercent = (products[i].GrossProfit.Float64() / products[i].Revenue.Float64()) * 100
+		}
+		if total > 0 {
+			products[i].Percentage = (products[i].Revenue.Float64() / total.Float64()) * 100
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    products,
+	})
+}
+
+// snapshotCategoryName is the category name a transaction item was sold under
+const snapshotCategoryName = "CASE WHEN transaction_items.category_id IS NULL THEN 'Uncategorized' ELSE transaction_items.category_name END"
+
+// categorySalesQuery selects completed transaction items and applies the date
+// and store filters. It writes the error response itself and reports whether
+// to go on.
+func (h *ReportsHandler) categorySalesQuery(c *gin.Context) (*gorm.DB, bool) {
+	start, end, err := h.settingService.BusinessCalendar().Range(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	query := h.db.Table("transaction_items").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL")
+
+	// Apply date filters
+	query = applyDateRange(query, "transactions.created_at", start, end)
+
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		storeID, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return nil, false
+		}
+		query = query.Where("transactions.store_id = ?", storeID)
+	}
+
+	return query, true
+}
+
+// Sales Heatmap Cell Response
+type SalesHeatmapCellResponse struct {
+	DayOfWeek        int         `json:"day_of_week"` // 1 = Monday ... 7 = Sunday
+	DayName          string      `json:"day_name"`
+	Hour             int         `json:"hour"`
+	Revenue          money.Money `json:"revenue"`
+	TransactionCount int64       `json:"transaction_count"`
+	AverageBasket    money.Money `json:"average_basket"`
+}
+
+// Day of Week Sales Response
+type DayOfWeekSalesResponse struct {
+	DayOfWeek           int         `json:"day_of_week"`
+	DayName             string      `json:"day_name"`
+	Revenue             money.Money `json:"revenue"`
+	TransactionCount    int64       `json:"transaction_count"`
+	AverageBasket       money.Money `json:"average_basket"`
+	Days                int         `json:"days"` // How often this weekday occurs in the range
+	AverageDailyRevenue money.Money `json:"average_daily_revenue"`
+	PeakHour            *int        `json:"peak_hour"`
+}
+
+// Sales Heatmap Response
+type SalesHeatmapResponse struct {
+	StartDate  string                     `json:"start_date"`
+	EndDate    string                     `json:"end_date"`
+	Timezone   string                     `json:"timezone"`
+	Cells      []SalesHeatmapCellResponse `json:"cells"` // Always 7 x 24, Monday 00:00 first
+	DaysOfWeek []DayOfWeekSalesResponse   `json:"days_of_week"`
+	Peak       *SalesHeatmapCellResponse  `json:"peak"`
+}
+
+// GetSalesHeatmap returns completed sales by hour of day and day of week. Hours
+// are wall-clock hours in the store timezone; the weekday is that of the business
+// day, so with a 04:00 cutoff a sale at 01:00 on Saturday is shown as Friday 01:00.
+// When filtered by category, revenue is the category's line subtotals before
+// transaction-level discounts. The daily summaries have no time of day, so the
+// whole range is read from the transactions.
+func (h *ReportsHandler) GetSalesHeatmap(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// Default to the last four weeks so every weekday is counted equally
+	calendar := h.settingService.BusinessCalendar()
+	if startDate == "" {
+		startDate = calendar.Format(time.Now().AddDate(0, 0, -27))
+	}
+	if endDate == "" {
+		endDate = calendar.Format(time.Now())
+	}
+
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if !start.Before(*end) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "start_date must not be after end_date",
+		})
+		return
+	}
+
+	filters := make(map[string]uuid.UUID)
+	for _, param := range []string{"store_id", "category_id", "cashier_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": fmt.Sprintf("Invalid %s format", param),
+			})
+			return
+		}
+		filters[param] = id
+	}
+
+	dayOfWeek := "EXTRACT(ISODOW FROM " + calendar.DateSQL("transactions.created_at") + ")::int"
+	hour := "EXTRACT(HOUR FROM " + calendar.LocalTimeSQL("transactions.created_at") + ")::int"
+
+	var query *gorm.DB
+	if categoryID, ok := filters["category_id"]; ok {
+		query = h.db.Table("transaction_items").
+			Select(fmt.Sprintf(`
+				%s as day_of_week,
+				%s as hour,
+				COALESCE(SUM(transaction_items.subtotal), 0) as revenue,
+				COUNT(DISTINCT transactions.id) as transaction_count
+			`, dayOfWeek, hour)).
+			Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+			Where("transaction_items.category_id = ?", categoryID)
+	} else {
+		query = h.db.Table("transactions").
+			Select(fmt.Sprintf(`
+				%s as day_of_week,
+				%s as hour,
+				COALESCE(SUM(transactions.final_amount), 0) as revenue,
+				COUNT(*) as transaction_count
+			`, dayOfWeek, hour))
+	}
+
+	query = query.
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL").
+		Group("1, 2")
+	query = applyDateRange(query, "transactions.created_at", start, end)
+
+	if storeID, ok := filters["store_id"]; ok {
+		query = query.Where("transactions.store_id = ?", storeID)
+	}
+	if cashierID, ok := filters["cashier_id"]; ok {
+		query = query.Where("transactions.user_id = ?", cashierID)
+	}
+
+	var rows []SalesHeatmapCellResponse
+	if err := query.Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch sales heatmap",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Lay out the full grid so empty hours show up as zeros
+	cells := make([]SalesHeatmapCellResponse, 7*24)
+	for i := range cells {
+		cells[i].DayOfWeek = i/24 + 1
+		cells[i].DayName = isoWeekdayName(cells[i].DayOfWeek)
+		cells[i].Hour = i % 24
+	}
+	for _, row := range rows {
+		if row.DayOfWeek < 1 || row.DayOfWeek > 7 || row.Hour < 0 || row.Hour > 23 {
+			continue
+		}
+		cell := &cells[(row.DayOfWeek-1)*24+row.Hour]
+		cell.Revenue = row.Revenue
+		cell.TransactionCount = row.TransactionCount
+		cell.AverageBasket = row.Revenue.Div(row.TransactionCount, money.RoundHalfUp)
+	}
+
+	// Count how many of each weekday the range covers for per-day averages
+	var occurrences [8]int
+	for day := calendar.BusinessDate(*start); day.Before(calendar.BusinessDate(*end)); day = day.AddDate(0, 0, 1) {
+		occurrences[isoWeekday(day.Weekday())]++
+	}
+
+	days := make([]DayOfWeekSalesResponse, 7)
+	var peak *SalesHeatmapCellResponse
+	for i := range days {
+		summary := &days[i]
+		summary.DayOfWeek = i + 1
+		summary.DayName = isoWeekdayName(summary.DayOfWeek)
+		summary.Days = occurrences[summary.DayOfWeek]
+
+		var peakRevenue money.Money
+		for _, cell := range cells[i*24 : (i+1)*24] {
+			summary.Revenue = summary.Revenue.Add(cell.Revenue)
+			summary.TransactionCount += cell.TransactionCount
+			if cell.TransactionCount > 0 && (summary.PeakHour == nil || cell.Revenue.Cmp(peakRevenue) > 0) {
+				peakHour := cell.Hour
+				summary.PeakHour = &peakHour
+				peakRevenue = cell.Revenue
+			}
+		}
+		summary.AverageBasket = summary.Revenue.Div(summary.TransactionCount, money.RoundHalfUp)
+		if summary.Days > 0 {
+			summary.AverageDailyRevenue = summary.Revenue.Div(int64(summary.Days), money.RoundHalfUp)
+		}
+	}
+	for i := range cells {
+		if cells[i].TransactionCount > 0 && (peak == nil || cells[i].Revenue.Cmp(peak.Revenue) > 0) {
+			peak = &cells[i]
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": SalesHeatmapResponse{
+			StartDate:  startDate,
+			EndDate:    endDate,
+			Timezone:   calendar.Location.String(),
+			Cells:      cells,
+			DaysOfWeek: days,
+			Peak:       peak,
+		},
+	})
+}
+
+// Gross Margin Response
+type GrossMarginResponse struct {
+	Key            string      `json:"key"`
+	Name           string      `json:"name"`
+	QuantitySold   int64       `json:"quantity_sold"`
+	Revenue        money.Money `json:"revenue"`
+	Cost           money.Money `json:"cost"`
+	GrossProfit    money.Money `json:"gross_profit"`
+	MarginPercent  float64     `json:"margin_percent"`
+	BelowCostLines int64       `json:"below_cost_lines"`
+}
+
+// Below Cost Sale Response
+type BelowCostSaleResponse struct {
+	TransactionID   string      `json:"transaction_id"`
+	TransactionCode string      `json:"transaction_code"`
+	ProductID       string      `json:"product_id"`
+	ProductName     string      `json:"product_name"`
+	Quantity        int64       `json:"quantity"`
+	UnitPrice       money.Money `json:"unit_price"`
+	UnitCost        money.Money `json:"unit_cost"`
+	Loss            money.Money `json:"loss"`
+	CashierName     string      `json:"cashier_name"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// GetGrossMargin returns revenue, cost of goods sold and gross profit grouped by
+// product, category, cashier or day. Costs and categories come from the
+// snapshot taken on each transaction item, so later cost changes or moving a
+// product to another category don't rewrite history. Figures are based on line
+// subtotals, before transaction-level discounts. Closed days come from the
+// daily summaries except when grouped by cashier, which they don't record.
+func (h *ReportsHandler) GetGrossMargin(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	groupBy := c.DefaultQuery("group_by", "product")
+
+	var keyColumn, nameColumn string
+	var args []interface{}
+	switch groupBy {
+	case "product":
+		keyColumn = "COALESCE(NULLIF(sales.product_id, ?)::text, '')"
+		nameColumn = "sales.product_name"
+		args = []interface{}{uuid.Nil}
+	case "category":
+		keyColumn = "COALESCE(NULLIF(sales.category_id, ?)::text, '')"
+		nameColumn = "CASE WHEN sales.category_id = ? THEN 'Uncategorized' ELSE sales.category_name END"
+		args = []interface{}{uuid.Nil, uuid.Nil}
+	case "cashier":
+		// Summaries don't record the cashier, so this grouping reads the transaction items
+	case "day":
+		keyColumn = "TO_CHAR(sales.business_date, 'YYYY-MM-DD')"
+		nameColumn = keyColumn
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "group_by must be one of product, category, cashier, day",
+		})
+		return
+	}
+
+	var query *gorm.DB
+	if groupBy == "cashier" {
+		query = h.db.Table("transaction_items").
+			Select(`
+				COALESCE(transactions.user_id::text, '') as key,
+				COALESCE(users.full_name, 'Unknown') as name,
+				COALESCE(SUM(transaction_items.quantity), 0) as quantity_sold,
+				COALESCE(SUM(transaction_items.subtotal), 0) as revenue,
+				COALESCE(SUM(transaction_items.total_cost), 0) as cost,
+				COUNT(*) FILTER (WHERE transaction_items.product_price + transaction_items.modifier_amount < transaction_items.unit_cost) as below_cost_lines
+			`).
+			Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+			Joins("LEFT JOIN users ON users.id = transactions.user_id").
+			Where("transactions.payment_status = ?", "completed").
+			Where("transactions.deleted_at IS NULL").
+			Group("1, 2")
+		query = applyDateRange(query, "transactions.created_at", start, end)
+	} else {
+		query = h.db.Table("(?) as sales", h.productSalesSource(calendar, start, end)).
+			Select(fmt.Sprintf(`
+				%s as key,
+				%s as name,
+				COALESCE(SUM(sales.quantity), 0) as quantity_sold,
+				COALESCE(SUM(sales.revenue), 0) as revenue,
+				COALESCE(SUM(sales.cost), 0) as cost,
+				COALESCE(SUM(sales.below_cost_lines), 0) as below_cost_lines
+			`, keyColumn, nameColumn), args...).
+			Group("1, 2")
+	}
+
+	if groupBy == "day" {
+		query = query.Order("key ASC")
+	} else {
+		query = query.Order("revenue DESC")
+	}
+
+	var margins []GrossMarginResponse
+	if err := query.Scan(&margins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch gross margin",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	for i := range margins {
+		margins[i].GrossProfit = margins[i].Revenue.Sub(margins[i].Cost)
+		if margins[i].Revenue > 0 {
+			margins[i].MarginPercent = (margins[i].GrossProfit.Float64() / margins[i].Revenue.Float64()) * 100
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    margins,
+	})
+}
+
+// GetBelowCostSales lists transaction lines sold for less than their cost at the
+// time of sale. It lists single lines, so it reads the transaction items for the
+// whole range; the daily summaries only keep a count per product.
+func (h *ReportsHandler) GetBelowCostSales(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	query := h.db.Table("transaction_items").
+		Select(`
+			transactions.id as transaction_id,
+			transactions.transaction_code,
+			COALESCE(transaction_items.product_id::text, '') as product_id,
+			transaction_items.product_name,
+			transaction_items.quantity,
+			transaction_items.product_price + transaction_items.modifier_amount as unit_price,
+			transaction_items.unit_cost,
+			transaction_items.total_cost - transaction_items.subtotal as loss,
+			COALESCE(users.full_name, 'Unknown') as cashier_name,
+			transactions.created_at
+		`).
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("LEFT JOIN users ON users.id = transactions.user_id").
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL").
+		Where("transaction_items.product_price + transaction_items.modifier_amount < transaction_items.unit_cost").
+		Order("transactions.created_at DESC")
+
+	// Apply date filters
+	query = applyDateRange(query, "transactions.created_at", start, end)
+
+	var lines []BelowCostSaleResponse
+	if err := query.Scan(&lines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch below cost sales",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lines,
+	})
+}
+
+// Bundle Component Sales Response
+type BundleComponentSalesResponse struct {
+	ProductID    string      `json:"product_id"`
+	ProductName  string      `json:"product_name"`
+	QuantitySold int64       `json:"quantity_sold"` // Units that left stock inside bundles
+	ListValue    money.Money `json:"list_value"`    // What those units would have sold for on their own
+	Revenue      money.Money `json:"revenue"`       // Share of bundle sales after discount
+	Cost         money.Money `json:"cost"`
+	GrossProfit  money.Money `json:"gross_profit"`
+	BundleCount  int64       `json:"bundle_count"` // Different bundles the product was sold in
+}
+
+// GetBundleSales allocates bundle sales back to the products inside them, so
+// a product's performance includes what it sold as part of a bundle. Each
+// line's sales after discount were shared out by list value when it was sold.
+func (h *ReportsHandler) GetBundleSales(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	query := h.db.Table("transaction_item_components").
+		Select(`
+			transaction_item_components.product_id::text as product_id,
+			MAX(transaction_item_components.product_name) as product_name,
+			COALESCE(SUM(transaction_item_components.quantity), 0) as quantity_sold,
+			COALESCE(SUM(transaction_item_components.list_value), 0) as list_value,
+			COALESCE(SUM(transaction_item_components.revenue), 0) as revenue,
+			COALESCE(SUM(transaction_item_components.total_cost), 0) as cost,
+			COUNT(DISTINCT transaction_items.product_id) as bundle_count
+		`).
+		Joins("JOIN transaction_items ON transaction_items.id = transaction_item_components.transaction_item_id").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("JOIN products ON products.id = transaction_items.product_id").
+		Where("products.is_bundle = ?", true).
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL").
+		Group("transaction_item_components.product_id").
+		Order("revenue DESC")
+
+	// Apply date filters
+	query = applyDateRange(query, "transactions.created_at", start, end)
+
+	if bundleIDStr := c.Query("bundle_id"); bundleIDStr != "" {
+		bundleID, err := uuid.Parse(bundleIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid bundle_id format",
+			})
+			return
+		}
+		query = query.Where("transaction_items.product_id = ?", bundleID)
+	}
+
+	var components []BundleComponentSalesResponse
+	if err := query.Scan(&components).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch bundle sales",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	for i := range components {
+		components[i].GrossProfit = components[i].Revenue.Sub(components[i].Cost)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    components,
+	})
+}
+
+// Cashier Performance Response
+type CashierPerformanceResponse struct {
+	UserID                 string      `json:"user_id"`
+	Username               string      `json:"username"`
+	FullName               string      `json:"full_name"`
+	Revenue                money.Money `json:"revenue"`
+	TransactionCount       int64       `json:"transaction_count"`
+	AverageBasket          money.Money `json:"average_basket"`
+	CancelledCount         int64       `json:"cancelled_count"` // Own sales that were cancelled afterwards
+	CancelledAmount        money.Money `json:"cancelled_amount"`
+	CancelRatio            float64     `json:"cancel_ratio"`      // Cancelled share of all own sales, 0-1
+	PeerCancelRatio        float64     `json:"peer_cancel_ratio"` // Same ratio for everyone else combined
+	CancellationsPerformed int64       `json:"cancellations_performed"`
+	DiscountTotal          money.Money `json:"discount_total"`
+	DiscountedCount        int64       `json:"discounted_count"`
+	StockIssueCount        int64       `json:"stock_issue_count"`
+	Flagged                bool        `json:"flagged"`
+	FlagReason             string      `json:"flag_reason,omitempty"`
+}
+
+// Thresholds for flagging an unusual cancel ratio
+const (
+	cashierFlagMultiplier       = 2.0 // Own ratio must be this many times the peer ratio
+	cashierFlagMinTransactions  = 20  // Too few sales make any ratio meaningless
+	cashierFlagMinCancellations = 3
+)
+
+// GetCashierPerformance returns sales, cancellations, discounts and stock issues
+// per cashier. Cancellations refund the whole sale, so they also cover refunds.
+// A cashier is flagged when their cancel ratio is well above that of everyone
+// else combined, which is worth a look for voided-after-payment fraud. The daily
+// summaries don't record the cashier, so the whole range is read from the
+// transactions.
+func (h *ReportsHandler) GetCashierPerformance(c *gin.Context) {
+	start, end, err := h.settingService.BusinessCalendar().Range(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var storeID *uuid.UUID
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		id, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return
+		}
+		storeID = &id
+	}
+
+	// Sales are attributed to the cashier who rang them up, by sale date
+	salesQuery := h.db.Table("transactions").
+		Select(`
+			transactions.user_id::text as user_id,
+			users.username,
+			users.full_name,
+			COALESCE(SUM(transactions.final_amount) FILTER (WHERE transactions.payment_status = 'completed'), 0) as revenue,
+			COUNT(*) FILTER (WHERE transactions.payment_status = 'completed') as transaction_count,
+			COUNT(*) FILTER (WHERE transactions.payment_status = 'cancelled') as cancelled_count,
+			COALESCE(SUM(transactions.final_amount) FILTER (WHERE transactions.payment_status = 'cancelled'), 0) as cancelled_amount,
+			COALESCE(SUM(transactions.discount_amount) FILTER (WHERE transactions.payment_status = 'completed'), 0) as discount_total,
+			COUNT(*) FILTER (WHERE transactions.payment_status = 'completed' AND transactions.discount_amount > 0) as discounted_count,
+			COUNT(*) FILTER (WHERE transactions.payment_status = 'completed' AND transactions.has_stock_issue) as stock_issue_count
+		`).
+		Joins("JOIN users ON users.id = transactions.user_id").
+		Where("transactions.payment_status IN ?", []string{"completed", "cancelled"}).
+		Where("transactions.deleted_at IS NULL").
+		Group("transactions.user_id, users.username, users.full_name").
+		Order("revenue DESC")
+	salesQuery = applyDateRange(salesQuery, "transactions.created_at", start, end)
+	if storeID != nil {
+		salesQuery = salesQuery.Where("transactions.store_id = ?", storeID)
+	}
+
+	var cashiers []CashierPerformanceResponse
+	if err := salesQuery.Scan(&cashiers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch cashier performance",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Cancellations are attributed to whoever cancelled, by cancellation date
+	cancelQuery := h.db.Table("transactions").
+		Select("transactions.cancelled_by::text as user_id, COUNT(*) as cancellations_performed").
+		Where("transactions.payment_status = ?", "cancelled").
+		Where("transactions.cancelled_by IS NOT NULL").
+		Where("transactions.deleted_at IS NULL").
+		Group("transactions.cancelled_by")
+	cancelQuery = applyDateRange(cancelQuery, "transactions.cancelled_at", start, end)
+	if storeID != nil {
+		cancelQuery = cancelQuery.Where("transactions.store_id = ?", storeID)
+	}
+
+	var performed []struct {
+		UserID                 string
+		CancellationsPerformed int64
+	}
+	if err := cancelQuery.Scan(&performed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch cashier performance",
+			"error":   err.Error(),
+		})
+		return
+	}
+	performedBy := make(map[string]int64, len(performed))
+	for _, p := range performed {
+		performedBy[p.UserID] = p.CancellationsPerformed
+	}
+
+	// Managers who only cancel others' sales still belong in the report
+	var missing []string
+	listed := make(map[string]bool, len(cashiers))
+	for _, cashier := range cashiers {
+		listed[cashier.UserID] = true
+	}
+	for userID := range performedBy {
+		if !listed[userID] {
+			missing = append(missing, userID)
+		}
+	}
+	if len(missing) > 0 {
+		var users []CashierPerformanceResponse
+		if err := h.db.Table("users").
+			Select("id::text as user_id, username, full_name").
+			Where("id::text IN ?", missing).
+			Order("username ASC").
+			Scan(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to fetch cashier performance",
+				"error":   err.Error(),
+			})
+			return
+		}
+		cashiers = append(cashiers, users...)
+	}
+
+	var totalSales, totalCancelled int64
+	for _, cashier := range cashiers {
+		totalSales += cashier.TransactionCount + cashier.CancelledCount
+		totalCancelled += cashier.CancelledCount
+	}
+
+	for i := range cashiers {
+		cashier := &cashiers[i]
+		cashier.AverageBasket = cashier.Revenue.Div(cashier.TransactionCount, money.RoundHalfUp)
+		cashier.CancellationsPerformed = performedBy[cashier.UserID]
+
+		own := cashier.TransactionCount + cashier.CancelledCount
+		if own > 0 {
+			cashier.CancelRatio = float64(cashier.CancelledCount) / float64(own)
+		}
+
+		// Compare against everyone else so one cashier can't pull the baseline up
+		peers := totalSales - own
+		if peers > 0 {
+			cashier.PeerCancelRatio = float64(totalCancelled-cashier.CancelledCount) / float64(peers)
+		}
+
+		if own >= cashierFlagMinTransactions &&
+			cashier.CancelledCount >= cashierFlagMinCancellations &&
+			peers > 0 &&
+			cashier.CancelRatio > cashier.PeerCancelRatio*cashierFlagMultiplier {
+			cashier.Flagged = true
+			cashier.FlagReason = fmt.Sprintf("Cancel ratio %.1f%% is more than %.0fx the peer ratio of %.1f%%",
+				cashier.CancelRatio*100, cashierFlagMultiplier, cashier.PeerCancelRatio*100)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    cashiers,
+	})
+}
+
+// ABC classes by cumulative share of revenue
+const (
+	abcClassALimit = 80.0
+	abcClassBLimit = 95.0
+)
+
+// Inventory Analysis Product Response
+type InventoryAnalysisProductResponse struct {
+	ProductID         string      `json:"product_id"`
+	ProductName       string      `json:"product_name"`
+	SKU               string      `json:"sku"`
+	CategoryID        string      `json:"category_id"`
+	CategoryName      string      `json:"category_name"`
+	Stock             int64       `json:"stock"`
+	UnitCost          money.Money `json:"unit_cost"`
+	StockValue        money.Money `json:"stock_value"` // Stock on hand at Product.Cost
+	UnitsSold         int64       `json:"units_sold"`
+	Revenue           money.Money `json:"revenue"`
+	CostOfGoodsSold   money.Money `json:"cost_of_goods_sold"`
+	RevenueShare      float64     `json:"revenue_share"`
+	CumulativeShare   float64     `json:"cumulative_share"`
+	Class             string      `json:"class"`        // A, B or C
+	SellThroughRate   float64     `json:"sell_through"` // Percent of available units that sold
+	StockTurn         float64     `json:"stock_turn"`   // COGS over average inventory value
+	LastSoldAt        *time.Time  `json:"last_sold_at"`
+	DaysSinceLastSale *int        `json:"days_since_last_sale"`
+	OpeningValue      money.Money `json:"-"`
+	ClosingValue      money.Money `json:"-"`
+}
+
+// Inventory Analysis Category Response
+type InventoryAnalysisCategoryResponse struct {
+	CategoryID      string      `json:"category_id"`
+	CategoryName    string      `json:"category_name"`
+	ProductCount    int         `json:"product_count"`
+	Stock           int64       `json:"stock"`
+	StockValue      money.Money `json:"stock_value"`
+	UnitsSold       int64       `json:"units_sold"`
+	Revenue         money.Money `json:"revenue"`
+	CostOfGoodsSold money.Money `json:"cost_of_goods_sold"`
+	SellThroughRate float64     `json:"sell_through"`
+	StockTurn       float64     `json:"stock_turn"`
+	ClassA          int         `json:"class_a"`
+	ClassB          int         `json:"class_b"`
+	ClassC          int         `json:"class_c"`
+	openingValue    money.Money
+	closingValue    money.Money
+}
+
+// Inventory Analysis Response
+type InventoryAnalysisResponse struct {
+	StartDate       string                              `json:"start_date"`
+	EndDate         string                              `json:"end_date"`
+	DeadStockDays   int                                 `json:"dead_stock_days"`
+	TotalRevenue    money.Money                         `json:"total_revenue"`
+	TotalStockValue money.Money                         `json:"total_stock_value"`
+	DeadStockValue  money.Money                         `json:"dead_stock_value"`
+	Products        []InventoryAnalysisProductResponse  `json:"products"`
+	Categories      []InventoryAnalysisCategoryResponse `json:"categories"`
+	DeadStock       []InventoryAnalysisProductResponse  `json:"dead_stock"`
+}
+
+// GetInventoryAnalysis classifies products A/B/C by their share of revenue in
+// the period (A up to 80% cumulative, B up to 95%, C the rest), lists dead stock
+// with no sale in dead_stock_days (default 90) and works out sell-through and
+// stock turn per product and category. Units sold include those taken as
+// recipe or bundle components and as modifier ingredients. Sell-through is
+// units sold over units sold plus stock on hand; stock turn is COGS over the average of the inventory
+// value at the start and end of the period.
+func (h *ReportsHandler) GetInventoryAnalysis(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// Default to the last 90 days
+	calendar := h.settingService.BusinessCalendar()
+	if startDate == "" {
+		startDate = calendar.Format(time.Now().AddDate(0, 0, -89))
+	}
+	if endDate == "" {
+		endDate = calendar.Format(time.Now())
+	}
+
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	deadStockDays, err := strconv.Atoi(c.DefaultQuery("dead_stock_days", "90"))
+	if err != nil || deadStockDays < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "dead_stock_days must be a positive number",
+		})
+		return
+	}
+
+	// Stock leaves as the product sold, as recipe or bundle components and as
+	// modifier ingredients. Each part is costed to the product it was taken
+	// from, so a line keeps only the cost of its own units.
+	usage := h.db.Raw(`
+		SELECT transaction_items.product_id, transaction_items.transaction_id, transaction_items.quantity,
+			transaction_items.subtotal as revenue,
+			transaction_items.total_cost
+				- COALESCE((SELECT SUM(total_cost) FROM transaction_item_components WHERE transaction_item_id = transaction_items.id), 0)
+				- COALESCE((SELECT SUM(ingredient_cost) FROM transaction_item_modifiers WHERE transaction_item_id = transaction_items.id), 0) as cost
+		FROM transaction_items
+		UNION ALL
+		SELECT transaction_item_components.product_id, transaction_items.transaction_id, transaction_item_components.quantity,
+			0, transaction_item_components.total_cost
+		FROM transaction_item_components
+		JOIN transaction_items ON transaction_items.id = transaction_item_components.transaction_item_id
+		UNION ALL
+		SELECT transaction_item_modifiers.ingredient_id, transaction_items.transaction_id, transaction_item_modifiers.ingredient_quantity,
+			0, transaction_item_modifiers.ingredient_cost
+		FROM transaction_item_modifiers
+		JOIN transaction_items ON transaction_items.id = transaction_item_modifiers.transaction_item_id
+		WHERE transaction_item_modifiers.ingredient_id IS NOT NULL
+	`)
+
+	sales := h.db.Table("(?) as sold_units", usage).
+		Select(`
+			sold_units.product_id,
+			SUM(sold_units.quantity) as units_sold,
+			SUM(sold_units.revenue) as revenue,
+			SUM(sold_units.cost) as cost_of_goods_sold
+		`).
+		Joins("JOIN transactions ON transactions.id = sold_units.transaction_id").
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL").
+		Where("transactions.created_at >= ? AND transactions.created_at < ?", *start, *end).
+		Group("sold_units.product_id")
+
+	lastSales := h.db.Table("(?) as sold_units", usage).
+		Select("sold_units.product_id, MAX(transactions.created_at) as last_sold_at").
+		Joins("JOIN transactions ON transactions.id = sold_units.transaction_id").
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL").
+		Group("sold_units.product_id")
+
+	values := h.db.Table("inventory_movements").
+		Select(`
+			product_id,
+			COALESCE(SUM(total_cost) FILTER (WHERE created_at < ?), 0) as opening_value,
+			COALESCE(SUM(total_cost) FILTER (WHERE created_at < ?), 0) as closing_value
+		`, *start, *end).
+		Group("product_id")
+
+	query := h.db.Table("products").
+		Select(`
+			products.id::text as product_id,
+			products.name as product_name,
+			products.sku,
+			COALESCE(categories.id::text, '') as category_id,
+			COALESCE(categories.name, 'Uncategorized') as category_name,
+			products.stock,
+			products.cost as unit_cost,
+			COALESCE(sales.units_sold, 0) as units_sold,
+			COALESCE(sales.revenue, 0) as revenue,
+			COALESCE(sales.cost_of_goods_sold, 0) as cost_of_goods_sold,
+			last_sales.last_sold_at,
+			COALESCE(stock_values.opening_value, 0) as opening_value,
+			COALESCE(stock_values.closing_value, 0) as closing_value
+		`).
+		Joins("LEFT JOIN categories ON categories.id = products.category_id").
+		Joins("LEFT JOIN (?) sales ON sales.product_id = products.id", sales).
+		Joins("LEFT JOIN (?) last_sales ON last_sales.product_id = products.id", lastSales).
+		Joins("LEFT JOIN (?) stock_values ON stock_values.product_id = products.id", values).
+		Where("products.deleted_at IS NULL").
+		Order("revenue DESC, products.name ASC")
+
+	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid category_id format",
+			})
+			return
+		}
+		query = query.Where("products.category_id = ?", categoryID)
+	}
+
+	var products []InventoryAnalysisProductResponse
+	if err := query.Scan(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch inventory analysis",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	analysis := InventoryAnalysisResponse{
+		StartDate:     startDate,
+		EndDate:       endDate,
+		DeadStockDays: deadStockDays,
+		Products:      []InventoryAnalysisProductResponse{},
+		Categories:    []InventoryAnalysisCategoryResponse{},
+		DeadStock:     []InventoryAnalysisProductResponse{},
+	}
+	for _, product := range products {
+		analysis.TotalRevenue = analysis.TotalRevenue.Add(product.Revenue)
+	}
+
+	now := time.Now()
+	deadSince := now.AddDate(0, 0, -deadStockDays)
+	categoryIndex := make(map[string]int)
+	var cumulative money.Money
+	for i := range products {
+		product := &products[i]
+
+		if product.Stock > 0 {
+			product.StockValue = product.UnitCost.Mul(product.Stock)
+		}
+		analysis.TotalStockValue = analysis.TotalStockValue.Add(product.StockValue)
+
+		// Products are ordered by revenue, so the running total gives the class
+		product.Class = "C"
+		if analysis.TotalRevenue > 0 && product.Revenue > 0 {
+			product.RevenueShare = product.Revenue.Float64() / analysis.TotalRevenue.Float64() * 100
+			previousShare := cumulative.Float64() / analysis.TotalRevenue.Float64() * 100
+			cumulative = cumulative.Add(product.Revenue)
+			product.CumulativeShare = cumulative.Float64() / analysis.TotalRevenue.Float64() * 100
+			// The product that crosses a limit still belongs to the class it started in
+			switch {
+			case previousShare < abcClassALimit:
+				product.Class = "A"
+			case previousShare < abcClassBLimit:
+				product.Class = "B"
+			}
+		}
+
+		product.SellThroughRate = sellThrough(product.UnitsSold, product.Stock)
+		product.StockTurn = stockTurn(product.CostOfGoodsSold, product.OpeningValue, product.ClosingValue)
+
+		if product.LastSoldAt != nil {
+			days := int(now.Sub(*product.LastSoldAt).Hours() / 24)
+			product.DaysSinceLastSale = &days
+		}
+		if product.Stock > 0 && (product.LastSoldAt == nil || product.LastSoldAt.Before(deadSince)) {
+			analysis.DeadStock = append(analysis.DeadStock, *product)
+			analysis.DeadStockValue = analysis.DeadStockValue.Add(product.StockValue)
+		}
+
+		index, ok := categoryIndex[product.CategoryID]
+		if !ok {
+			index = len(analysis.Categories)
+			categoryIndex[product.CategoryID] = index
+			analysis.Categories = append(analysis.Categories, InventoryAnalysisCategoryResponse{
+				CategoryID:   product.CategoryID,
+				CategoryName: product.CategoryName,
+			})
+		}
+		category := &analysis.Categories[index]
+		category.ProductCount++
+		category.Stock += product.Stock
+		category.StockValue = category.StockValue.Add(product.StockValue)
+		category.UnitsSold += product.UnitsSold
+		category.Revenue = category.Revenue.Add(product.Revenue)
+		category.CostOfGoodsSold = category.CostOfGoodsSold.Add(product.CostOfGoodsSold)
+		category.openingValue = category.openingValue.Add(product.OpeningValue)
+		category.closingValue = category.closingValue.Add(product.ClosingValue)
+		switch product.Class {
+		case "A":
+			category.ClassA++
+		case "B":
+			category.ClassB++
+		default:
+			category.ClassC++
+		}
+	}
+	analysis.Products = append(analysis.Products, products...)
+
+	for i := range analysis.Categories {
+		category := &analysis.Categories[i]
+		category.SellThroughRate = sellThrough(category.UnitsSold, category.Stock)
+		category.StockTurn = stockTurn(category.CostOfGoodsSold, category.openingValue, category.closingValue)
+	}
+	sort.SliceStable(analysis.Categories, func(i, j int) bool {
+		return analysis.Categories[i].Revenue.Cmp(analysis.Categories[j].Revenue) > 0
+	})
+
+	// Biggest tied-up capital first
+	sort.SliceStable(analysis.DeadStock, func(i, j int) bool {
+		return analysis.DeadStock[i].StockValue.Cmp(analysis.DeadStock[j].StockValue) > 0
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    analysis,
+	})
+}
+
+// Helper functions
+
+// exportHeader builds the header block with the configured store name
+func (h *ReportsHandler) exportHeader(title, startDate, endDate string) export.HeaderBlock {
+	block := export.HeaderBlock{
+		Title:     title,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	if setting, err := h.settingService.GetSettingByKey("store_name"); err == nil {
+		block.StoreName = settingString(setting.Value)
+	}
+
+	return block
+}
+
+// streamRows runs query and hands each row to fn one at a time instead of loading them all
+func (h *ReportsHandler) streamRows(query *gorm.DB, fn func(scan func(dest interface{}) error) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		scan := func(dest interface{}) error {
+			return h.db.ScanRows(rows, dest)
+		}
+		if err := fn(scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// sellThrough is the percentage of available units (sold plus on hand) that sold
+func sellThrough(unitsSold, stock int64) float64 {
+	if stock < 0 {
+		stock = 0
+	}
+	if unitsSold+stock <= 0 {
+		return 0
+	}
+	return math.Round(float64(unitsSold)/float64(unitsSold+stock)*10000) / 100
+}
+
+// stockTurn is how many times the average inventory value was sold through
+func stockTurn(costOfGoodsSold, openingValue, closingValue money.Money) float64 {
+	average := openingValue.Add(closingValue).Div(2, money.RoundHalfUp)
+	if average <= 0 {
+		return 0
+	}
+	return math.Round(costOfGoodsSold.Float64()/average.Float64()*100) / 100
+}
+
+// isoWeekday numbers weekdays 1 = Monday ... 7 = Sunday like Postgres ISODOW
+func isoWeekday(day time.Weekday) int {
+	if day == time.Sunday {
+		return 7
+	}
+	return int(day)
+}
+
+func isoWeekdayName(isoDay int) string {
+	return time.Weekday(isoDay % 7).String()
+}
+
+// salesSource returns completed sales in the [start, end) range as rows of
+// business_date, store_id (nil UUID without a store), payment_method,
+// transaction_count, revenue, items_sold, rounded_count, rounding_gain and
+// rounding_loss. Closed business days come from the daily summaries and only
+// today is read from the transactions, so aggregate over the rows rather than
+// counting them.
+func (h *ReportsHandler) salesSource(calendar businessday.Calendar, start, end *time.Time) *gorm.DB {
+	closedEnd, todayStart := summaryBounds(calendar, start, end)
+
+	summaries := h.db.Table("daily_sales_summaries").
+		Select("business_date, store_id, payment_method, transaction_count, revenue, items_sold, rounded_count, rounding_gain, rounding_loss").
+		Where("business_date < ?", calendar.Format(closedEnd))
+	if start != nil {
+		summaries = summaries.Where("business_date >= ?", calendar.Format(*start))
+	}
+
+	today := h.db.Table("transactions").
+		Select(calendar.DateSQL("created_at")+` as business_date,
+			COALESCE(store_id, ?) as store_id,
+			payment_method,
+			1 as transaction_count,
+			final_amount as revenue,
+			(SELECT COALESCE(SUM(quantity), 0) FROM transaction_items WHERE transaction_id = transactions.id) as items_sold,
+			CASE WHEN rounding_amount <> 0 THEN 1 ELSE 0 END as rounded_count,
+			GREATEST(rounding_amount, 0) as rounding_gain,
+			GREATEST(-rounding_amount, 0) as rounding_loss
+		`, uuid.Nil).
+		Where("payment_status = ?", "completed").
+		Where("deleted_at IS NULL")
+	today = applyDateRange(today, "created_at", &todayStart, end)
+
+	return h.db.Raw("(?) UNION ALL (?)", summaries, today)
+}
+
+// productSalesSource is salesSource for product lines, giving rows of
+// business_date, store_id, product_id (nil UUID when the product is gone),
+// product_name, category_id (nil UUID when uncategorized), category_name,
+// quantity, revenue, cost and below_cost_lines. Categories are those the
+// lines were sold under.
+func (h *ReportsHandler) productSalesSource(calendar businessday.Calendar, start, end *time.Time) *gorm.DB {
+	closedEnd, todayStart := summaryBounds(calendar, start, end)
+
+	summaries := h.db.Table("daily_product_sales").
+		Select("business_date, store_id, product_id, product_name, category_id, category_name, quantity, revenue, cost, below_cost_lines").
+		Where("business_date < ?", calendar.Format(closedEnd))
+	if start != nil {
+		summaries = summaries.Where("business_date >= ?", calendar.Format(*start))
+	}
+
+	today := h.db.Table("transaction_items").
+		Select(calendar.DateSQL("transactions.created_at")+` as business_date,
+			COALESCE(transactions.store_id, ?) as store_id,
+			COALESCE(transaction_items.product_id, ?) as product_id,
+			transaction_items.product_name,
+			COALESCE(transaction_items.category_id, ?) as category_id,
+			CASE WHEN transaction_items.category_id IS NULL THEN '' ELSE COALESCE(transaction_items.category_name, '') END as category_name,
+			transaction_items.quantity,
+			transaction_items.subtotal as revenue,
+			transaction_items.total_cost as cost,
+			CASE WHEN transaction_items.product_price + transaction_items.modifier_amount < transaction_items.unit_cost THEN 1 ELSE 0 END as below_cost_lines
+		`, uuid.Nil, uuid.Nil, uuid.Nil).
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transactions.payment_status = ?", "completed").
+		Where("transactions.deleted_at IS NULL")
+	today = applyDateRange(today, "transactions.created_at", &todayStart, end)
+
+	return h.db.Raw("(?) UNION ALL (?)", summaries, today)
+}
+
+// summaryBounds splits [start, end) at the start of today: closed days before
+// closedEnd come from the summaries, the rest from todayStart on is queried live
+func summaryBounds(calendar businessday.Calendar, start, end *time.Time) (closedEnd, todayStart time.Time) {
+	todayStart, _ = calendar.Today()
+	closedEnd = todayStart
+	if end != nil && end.Before(closedEnd) {
+		closedEnd = *end
+	}
+	if start != nil && start.After(todayStart) {
+		todayStart = *start
+	}
+	return closedEnd, todayStart
+}
+
+// applyDateRange limits column to the [start, end) business-day range
+func applyDateRange(query *gorm.DB, column string, start, end *time.Time) *gorm.DB {
+	if start != nil {
+		query = query.Where(column+" >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where(column+" < ?", *end)
+	}
+	return query
+}
+
+// Tax Class Summary Response
+type TaxClassSummaryResponse struct {
+	TaxClassID       string      `json:"tax_class_id"` // Empty for lines sold without a tax class
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	Rate             float64     `json:"rate"` // As charged on the lines, a rate change splits a class over two rows
+	Inclusive        bool        `json:"inclusive"`
+	NetSales         money.Money `json:"net_sales"`      // Line amounts after discount, including any inclusive tax
+	TaxableAmount    money.Money `json:"taxable_amount"` // Tax base
+	TaxAmount        money.Money `json:"tax_amount"`
+	LineCount        int64       `json:"line_count"`
+	TransactionCount int64       `json:"transaction_count"`
+}
+
+// Tax Summary Response
+type TaxSummaryResponse struct {
+	Classes            []TaxClassSummaryResponse `json:"classes"`
+	TotalTaxableAmount money.Money               `json:"total_taxable_amount"`
+	TotalTaxAmount     money.Money               `json:"total_tax_amount"`
+	UnclassifiedTax    money.Money               `json:"unclassified_tax"` // Tax entered by hand on sales without any taxed line
+}
+
+// GetTaxSummary returns the taxable base and tax collected per tax class for
+// filing, from the tax stored on each completed sale line. Filing needs the
+// figures per class and rate, which the daily summaries don't keep, so the
+// whole range is read from the transaction items.
+func (h *ReportsHandler) GetTaxSummary(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	start, end, err := h.settingService.BusinessCalendar().Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var storeID *uuid.UUID
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		parsed, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return
+		}
+		storeID = &parsed
+	}
+
+	filter := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("transactions.payment_status = ?", "completed").
+			Where("transactions.deleted_at IS NULL")
+		query = applyDateRange(query, "transactions.created_at", start, end)
+		if storeID != nil {
+			query = query.Where("transactions.store_id = ?", *storeID)
+		}
+		return query
+	}
+
+	// Deleted classes are still joined so past sales keep their name
+	var classes []TaxClassSummaryResponse
+	err = filter(h.db.Table("transaction_items").
+		Select(`
+			COALESCE(transaction_items.tax_class_id::text, '') as tax_class_id,
+			COALESCE(MAX(tax_classes.code), '') as code,
+			COALESCE(MAX(tax_classes.name), 'No tax class') as name,
+			transaction_items.tax_rate as rate,
+			transaction_items.tax_inclusive as inclusive,
+			COALESCE(SUM(transaction_items.subtotal - transaction_items.discount_amount), 0) as net_sales,
+			COALESCE(SUM(transaction_items.taxable_amount), 0) as taxable_amount,
+			COALESCE(SUM(transaction_items.tax_amount), 0) as tax_amount,
+			COUNT(*) as line_count,
+			COUNT(DISTINCT transactions.id) as transaction_count
+		`).
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("LEFT JOIN tax_classes ON tax_classes.id = transaction_items.tax_class_id")).
+		Group("transaction_items.tax_class_id, transaction_items.tax_rate, transaction_items.tax_inclusive").
+		Order("transaction_items.tax_class_id IS NULL, code ASC, rate ASC").
+		Scan(&classes).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch tax summary",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	summary := TaxSummaryResponse{Classes: classes}
+	for _, class := range classes {
+		summary.TotalTaxableAmount = summary.TotalTaxableAmount.Add(class.TaxableAmount)
+		summary.TotalTaxAmount = summary.TotalTaxAmount.Add(class.TaxAmount)
+	}
+
+	err = filter(h.db.Table("transactions")).
+		Select("COALESCE(SUM(transactions.tax_amount), 0)").
+		Where("NOT EXISTS (SELECT 1 FROM transaction_items WHERE transaction_items.transaction_id = transactions.id AND (transaction_items.tax_class_id IS NOT NULL OR transaction_items.tax_rate <> 0))").
+		Scan(&summary.UnclassifiedTax).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch tax summary",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if format != "" {
+		streamExport(c, format, "tax-summary", h.exportHeader("Tax Summary", startDate, endDate), func(w export.Writer) error {
+			if err := w.WriteHeader("Code", "Tax Class", "Rate (%)", "Pricing", "Net Sales", "Taxable Amount", "Tax Amount", "Lines", "Transactions"); err != nil {
+				return err
+			}
+			for _, class := range summary.Classes {
+				pricing := "Exclusive"
+				if class.Inclusive {
+					pricing = "Inclusive"
+				}
+				if class.TaxClassID == "" {
+					pricing = ""
+				}
+				err := w.WriteRow(export.Text(class.Code), export.Text(class.Name), export.Float(class.Rate), export.Text(pricing), export.Money(class.NetSales),
+					export.Money(class.TaxableAmount), export.Money(class.TaxAmount), export.Int(class.LineCount), export.Int(class.TransactionCount))
+				if err != nil {
+					return err
+				}
+			}
+			if err := w.WriteRow(export.Text(""), export.Text("Total"), export.Text(""), export.Text(""), export.Text(""),
+				export.Money(summary.TotalTaxableAmount), export.Money(summary.TotalTaxAmount), export.Text(""), export.Text("")); err != nil {
+				return err
+			}
+			if summary.UnclassifiedTax.IsZero() {
+				return nil
+			}
+			return w.WriteRow(export.Text(""), export.Text("Tax without a class"), export.Text(""), export.Text(""), export.Text(""),
+				export.Text(""), export.Money(summary.UnclassifiedTax), export.Text(""), export.Text(""))
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// Cash Rounding Day Response
+type CashRoundingDayResponse struct {
+	Date                string      `json:"date"`
+	CashTransactions    int64       `json:"cash_transactions"`
+	RoundedTransactions int64       `json:"rounded_transactions"`
+	RoundingGain        money.Money `json:"rounding_gain"` // Rounded up in the store's favour
+	RoundingLoss        money.Money `json:"rounding_loss"` // Rounded down, as a positive amount
+	NetRounding         money.Money `json:"net_rounding"`
+}
+
+// Cash Rounding Response
+type CashRoundingResponse struct {
+	Days                []CashRoundingDayResponse `json:"days"`
+	CashTransactions    int64                     `json:"cash_transactions"`
+	RoundedTransactions int64                     `json:"rounded_transactions"`
+	RoundingGain        money.Money               `json:"rounding_gain"`
+	RoundingLoss        money.Money               `json:"rounding_loss"`
+	NetRounding         money.Money               `json:"net_rounding"`
+}
+
+// GetCashRounding returns the gains and losses from rounding cash totals per
+// business day, for reconciling the cash drawer against sales. Closed days come
+// from the daily summaries.
+func (h *ReportsHandler) GetCashRounding(c *gin.Context) {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	calendar := h.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	query := h.db.Table("(?) as sales", h.salesSource(calendar, start, end)).
+		Select(`
+			TO_CHAR(business_date, 'YYYY-MM-DD') as date,
+			SUM(transaction_count) as cash_transactions,
+			SUM(rounded_count) as rounded_transactions,
+			SUM(rounding_gain) as rounding_gain,
+			SUM(rounding_loss) as rounding_loss,
+			SUM(rounding_gain) - SUM(rounding_loss) as net_rounding
+		`).
+		Where("payment_method = ?", "cash").
+		Group("business_date").
+		Having("SUM(transaction_count) > 0").
+		Order("date ASC")
+
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		storeID, err := uuid.Parse(storeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid store_id format",
+			})
+			return
+		}
+		query = query.Where("store_id = ?", storeID)
+	}
+
+	var days []CashRoundingDayResponse
+	if err := query.Scan(&days).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch cash rounding",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	report := CashRoundingResponse{Days: days}
+	for _, day := range days {
+		report.CashTransactions += day.CashTransactions
+		report.RoundedTransactions += day.RoundedTransactions
+		report.RoundingGain = report.RoundingGain.Add(day.RoundingGain)
+		report.RoundingLoss = report.RoundingLoss.Add(day.RoundingLoss)
+		report.NetRounding = report.NetRounding.Add(day.NetRounding)
+	}
+
+	if format != "" {
+		streamExport(c, format, "cash-rounding", h.exportHeader("Cash Rounding", startDate, endDate), func(w export.Writer) error {
+			if err := w.WriteHeader("Date", "Cash Transactions", "Rounded Transactions", "Rounding Gain", "Rounding Loss", "Net Rounding"); err != nil {
+				return err
+			}
+			for _, day := range report.Days {
+				err := w.WriteRow(export.Text(day.Date), export.Int(day.CashTransactions), export.Int(day.RoundedTransactions),
+					export.Money(day.RoundingGain), export.Money(day.RoundingLoss), export.Money(day.NetRounding))
+				if err != nil {
+					return err
+				}
+			}
+			return w.WriteRow(export.Text("Total"), export.Int(report.CashTransactions), export.Int(report.RoundedTransactions),
+				export.Money(report.RoundingGain), export.Money(report.RoundingLoss), export.Money(report.NetRounding))
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -1,10 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"pos-backend/internal/domain"
 	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type SettingHandler struct {
@@ -15,10 +22,19 @@ func NewSettingHandler(service *service.SettingService) *SettingHandler {
 	return &SettingHandler{service: service}
 }
 
-// GetSettings returns all settings grouped by category
+// GetSettings returns all settings grouped by category. Pass store_id and/or
+// device_id to get the values in effect there, overrides included.
 func (h *SettingHandler) GetSettings(c *gin.Context) {
-	settings, err := h.service.GetSettings()
+	scope, ok := settingScopeFromQuery(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(scope)
 	if err != nil {
+		if h.writeScopeError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": "Failed to fetch settings",
@@ -46,7 +62,10 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UpdateSettings(settingsMap); err != nil {
+	if err := h.service.UpdateSettings(settingsMap, c.GetString("role"), actorFromContext(c)); err != nil {
+		if h.writeValidationError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": "Failed to update settings",
@@ -63,7 +82,7 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 
 // InitializeDefaults creates default settings
 func (h *SettingHandler) InitializeDefaults(c *gin.Context) {
-	if err := h.service.InitializeDefaultSettings(); err != nil {
+	if err := h.service.InitializeDefaultSettings(actorFromContext(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"message": "Failed to initialize default settings",
@@ -77,3 +96,261 @@ func (h *SettingHandler) InitializeDefaults(c *gin.Context) {
 		"message": "Default settings initialized",
 	})
 }
+
+// GetSchema describes every setting: type, allowed values, default and who may edit it
+func (h *SettingHandler) GetSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.service.GetSchema(),
+	})
+}
+
+// GetVersions lists setting versions, newest first
+func (h *SettingHandler) GetVersions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	versions, total, err := h.service.GetVersions(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to fetch setting versions",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response.SuccessWithPagination(c, "Setting versions retrieved successfully", versions, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+	})
+}
+
+// GetVersion returns a version with the full settings as they were after it
+func (h *SettingHandler) GetVersion(c *gin.Context) {
+	version, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetVersion(version)
+	if err != nil {
+		h.writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// DiffVersion compares a version with the one before it, or with ?from=<version>
+func (h *SettingHandler) DiffVersion(c *gin.Context) {
+	to, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	from := to - 1
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, ok = versionParam(c, fromStr); !ok {
+			return
+		}
+	}
+
+	diff, err := h.service.DiffVersions(from, to)
+	if err != nil {
+		h.writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    diff,
+	})
+}
+
+// Rollback restores the settings to a previous version as a new version
+func (h *SettingHandler) Rollback(c *gin.Context) {
+	version, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Rollback(version, actorFromContext(c))
+	if err != nil {
+		if h.writeValidationError(c, err) {
+			return
+		}
+		h.writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Settings rolled back successfully",
+		"data":    result,
+	})
+}
+
+// GetResolvedSettings lists every setting in effect for store_id and/or
+// device_id and whether it came from the default, global, store or device level
+func (h *SettingHandler) GetResolvedSettings(c *gin.Context) {
+	scope, ok := settingScopeFromQuery(c)
+	if !ok {
+		return
+	}
+
+	resolved, err := h.service.GetResolvedSettings(scope)
+	if err != nil {
+		if h.writeScopeError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to resolve settings",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resolved,
+	})
+}
+
+// UpdateStoreOverrides sets a store's own values; null reverts a key to the global value
+func (h *SettingHandler) UpdateStoreOverrides(c *gin.Context) {
+	h.updateOverrides(c, domain.SettingScopeStore)
+}
+
+// UpdateDeviceOverrides sets a device's own values; null reverts a key to its store's value
+func (h *SettingHandler) UpdateDeviceOverrides(c *gin.Context) {
+	h.updateOverrides(c, domain.SettingScopeDevice)
+}
+
+// Helper functions
+
+func (h *SettingHandler) updateOverrides(c *gin.Context, scope string) {
+	scopeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": fmt.Sprintf("Invalid %s ID format", scope),
+		})
+		return
+	}
+
+	var settingsMap map[string]map[string]interface{}
+	if err := c.ShouldBindJSON(&settingsMap); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.UpdateOverrides(scope, scopeID, settingsMap, c.GetString("role"), actorFromContext(c)); err != nil {
+		if h.writeValidationError(c, err) || h.writeScopeError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to update setting overrides",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Setting overrides updated successfully",
+	})
+}
+
+// writeScopeError answers 404 when the store or device doesn't exist
+func (h *SettingHandler) writeScopeError(c *gin.Context, err error) bool {
+	if err.Error() != "store not found" && err.Error() != "device not found" {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+	return true
+}
+
+// settingScopeFromQuery reads the optional store_id and device_id parameters
+func settingScopeFromQuery(c *gin.Context) (service.SettingScope, bool) {
+	var scope service.SettingScope
+	for param, target := range map[string]**uuid.UUID{"store_id": &scope.StoreID, "device_id": &scope.DeviceID} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": fmt.Sprintf("Invalid %s format", param),
+			})
+			return scope, false
+		}
+		*target = &id
+	}
+	return scope, true
+}
+
+// writeValidationError answers with the per-field errors when err is a
+// validation error, 403 if any field was off limits for the caller's role
+func (h *SettingHandler) writeValidationError(c *gin.Context, err error) bool {
+	var validationErr *service.SettingValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	status := http.StatusBadRequest
+	if validationErr.Forbidden() {
+		status = http.StatusForbidden
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"message": "Invalid settings",
+		"errors":  validationErr.Errors,
+	})
+	return true
+}
+
+func (h *SettingHandler) writeVersionError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if err.Error() == "setting version not found" {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// versionParam parses a version number, answering 400 when it isn't one
+func versionParam(c *gin.Context, value string) (int64, bool) {
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid version",
+		})
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StoreHandler struct {
+	storeService service.StoreService
+}
+
+func NewStoreHandler(storeService service.StoreService) *StoreHandler {
+	return &StoreHandler{
+		storeService: storeService,
+	}
+}
+
+func (h *StoreHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	stores, total, err := h.storeService.GetAll(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get stores", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Stores retrieved successfully", stores, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *StoreHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	store, err := h.storeService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Store retrieved successfully", store)
+}
+
+func (h *StoreHandler) Create(c *gin.Context) {
+	var req dto.CreateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	store, err := h.storeService.Create(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Store created successfully", store)
+}
+
+func (h *StoreHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	store, err := h.storeService.Update(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Store updated successfully", store)
+}
+
+func (h *StoreHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.storeService.Delete(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Store deleted successfully", nil)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxClassHandler struct {
+	taxClassService service.TaxClassService
+}
+
+func NewTaxClassHandler(taxClassService service.TaxClassService) *TaxClassHandler {
+	return &TaxClassHandler{
+		taxClassService: taxClassService,
+	}
+}
+
+func (h *TaxClassHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	taxClasses, total, err := h.taxClassService.GetAll(page, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get tax classes", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Tax classes retrieved successfully", taxClasses, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *TaxClassHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	taxClass, err := h.taxClassService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Tax class retrieved successfully", taxClass)
+}
+
+func (h *TaxClassHandler) Create(c *gin.Context) {
+	var req dto.CreateTaxClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	taxClass, err := h.taxClassService.Create(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Tax class created successfully", taxClass)
+}
+
+func (h *TaxClassHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateTaxClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	taxClass, err := h.taxClassService.Update(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Tax class updated successfully", taxClass)
+}
+
+func (h *TaxClassHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.taxClassService.Delete(id, actorFromContext(c)); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Tax class deleted successfully", nil)
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"math"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TicketHandler struct {
+	ticketService service.TicketService
+}
+
+func NewTicketHandler(ticketService service.TicketService) *TicketHandler {
+	return &TicketHandler{
+		ticketService: ticketService,
+	}
+}
+
+func (h *TicketHandler) Open(c *gin.Context) {
+	var req dto.OpenTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	ticket, err := h.ticketService.Open(&req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Ticket opened successfully", ticket)
+}
+
+func (h *TicketHandler) GetAll(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	// Open tickets unless asked otherwise
+	filters := domain.TicketFilters{Status: c.DefaultQuery("status", domain.TicketStatusOpen)}
+	if filters.Status == "all" {
+		filters.Status = ""
+	}
+	storeID, ok := optionalUUIDQuery(c, "store_id")
+	if !ok {
+		return
+	}
+	filters.StoreID = storeID
+	tableID, ok := optionalUUIDQuery(c, "table_id")
+	if !ok {
+		return
+	}
+	filters.TableID = tableID
+
+	tickets, total, err := h.ticketService.GetAll(page, limit, filters)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get tickets", err.Error())
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	response.SuccessWithPagination(c, "Tickets retrieved successfully", tickets, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	})
+}
+
+func (h *TicketHandler) GetByID(c *gin.Context) {
+	id := c.Param("id")
+
+	ticket, err := h.ticketService.GetByID(id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, "Ticket retrieved successfully", ticket)
+}
+
+func (h *TicketHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	ticket, err := h.ticketService.Update(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Ticket updated successfully", ticket)
+}
+
+func (h *TicketHandler) SendRound(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SendRoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	ticket, err := h.ticketService.SendRound(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Round sent to the kitchen", ticket)
+}
+
+func (h *TicketHandler) VoidItem(c *gin.Context) {
+	ticket, err := h.ticketService.VoidItem(c.Param("id"), c.Param("item_id"), actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Ticket item voided", ticket)
+}
+
+func (h *TicketHandler) Settle(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.SettleTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	actor := actorFromContext(c)
+	if actor.UserID == uuid.Nil {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	transaction, warnings, err := h.ticketService.Settle(id, &req, actor)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	if len(warnings) > 0 {
+		response.SuccessWithWarnings(c, "Ticket settled with stock warnings", transaction, warnings)
+		return
+	}
+
+	response.Success(c, "Ticket settled successfully", transaction)
+}
+
+func (h *TicketHandler) Void(c *gin.Context) {
+	id := c.Param("id")
+
+	ticket, err := h.ticketService.Void(id, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Ticket voided", ticket)
+}
+
+func (h *TicketHandler) KitchenQueue(c *gin.Context) {
+	storeID, ok := optionalUUIDQuery(c, "store_id")
+	if !ok {
+		return
+	}
+
+	// Everything not yet served unless asked otherwise, e.g. status=queued,preparing
+	filters := domain.KitchenQueueFilters{StoreID: storeID}
+	status := c.DefaultQuery("status", strings.Join([]string{domain.KitchenStatusQueued, domain.KitchenStatusPreparing, domain.KitchenStatusReady}, ","))
+	if status != "all" {
+		for _, s := range strings.Split(status, ",") {
+			switch s = strings.TrimSpace(s); s {
+			case domain.KitchenStatusQueued, domain.KitchenStatusPreparing, domain.KitchenStatusReady, domain.KitchenStatusServed:
+				filters.Statuses = append(filters.Statuses, s)
+			default:
+				response.BadRequest(c, "Invalid status: "+s, nil)
+				return
+			}
+		}
+	}
+
+	items, err := h.ticketService.KitchenQueue(filters)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get kitchen queue", err.Error())
+		return
+	}
+
+	response.Success(c, "Kitchen queue retrieved successfully", items)
+}
+
+func (h *TicketHandler) UpdateKitchenStatus(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateKitchenStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	item, err := h.ticketService.UpdateKitchenStatus(id, &req, actorFromContext(c))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, "Kitchen status updated", item)
+}
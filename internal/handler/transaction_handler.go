@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"math"
 	"pos-backend/internal/domain"
 	"pos-backend/internal/dto"
 	"pos-backend/internal/service"
+	"pos-backend/pkg/export"
 	"pos-backend/pkg/response"
 	"strconv"
 	"time"
@@ -15,11 +18,13 @@ import (
 
 type TransactionHandler struct {
 	transactionService service.TransactionService
+	settingService     *service.SettingService
 }
 
-func NewTransactionHandler(transactionService service.TransactionService) *TransactionHandler {
+func NewTransactionHandler(transactionService service.TransactionService, settingService *service.SettingService) *TransactionHandler {
 	return &TransactionHandler{
 		transactionService: transactionService,
+		settingService:     settingService,
 	}
 }
 
@@ -31,14 +36,13 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 	}
 
 	// Get user ID from JWT token
-	userIDStr := c.GetString("user_id")
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	actor := actorFromContext(c)
+	if actor.UserID == uuid.Nil {
 		response.Unauthorized(c, "Invalid user ID")
 		return
 	}
 
-	transaction, warnings, err := h.transactionService.Create(&req, userID)
+	transaction, warnings, err := h.transactionService.Create(&req, actor)
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -61,14 +65,13 @@ func (h *TransactionHandler) BulkSync(c *gin.Context) {
 	}
 
 	// Get user ID from JWT token
-	userIDStr := c.GetString("user_id")
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	actor := actorFromContext(c)
+	if actor.UserID == uuid.Nil {
 		response.Unauthorized(c, "Invalid user ID")
 		return
 	}
 
-	result, err := h.transactionService.BulkSync(&req, userID)
+	result, err := h.transactionService.BulkSync(&req, actor)
 	if err != nil {
 		response.InternalServerError(c, "Failed to sync transactions", err.Error())
 		return
@@ -90,6 +93,12 @@ func (h *TransactionHandler) GetByID(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetAll(c *gin.Context) {
+	format, err := exportFormat(c)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
 	// Get pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -105,6 +114,22 @@ func (h *TransactionHandler) GetAll(c *gin.Context) {
 		}
 	}
 
+	// Filter by device ID
+	if deviceIDStr := c.Query("device_id"); deviceIDStr != "" {
+		deviceID, err := uuid.Parse(deviceIDStr)
+		if err == nil {
+			filters.DeviceID = &deviceID
+		}
+	}
+
+	// Filter by store ID
+	if storeIDStr := c.Query("store_id"); storeIDStr != "" {
+		storeID, err := uuid.Parse(storeIDStr)
+		if err == nil {
+			filters.StoreID = &storeID
+		}
+	}
+
 	// Filter by payment method
 	if paymentMethod := c.Query("payment_method"); paymentMethod != "" {
 		filters.PaymentMethod = paymentMethod
@@ -115,19 +140,14 @@ func (h *TransactionHandler) GetAll(c *gin.Context) {
 		filters.PaymentStatus = paymentStatus
 	}
 
-	// Filter by date range
-	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
-			filters.StartDate = &startDate
-		}
-	}
-	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
-			// Set to end of day
-			endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-			filters.EndDate = &endDate
-		}
+	// Filter by business-day range in the store timezone
+	startDate, endDate, err := h.settingService.BusinessCalendar().Range(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
 	}
+	filters.StartDate = startDate
+	filters.EndDate = endDate
 
 	// Filter by stock issue
 	if hasStockIssueStr := c.Query("has_stock_issue"); hasStockIssueStr != "" {
@@ -135,6 +155,11 @@ func (h *TransactionHandler) GetAll(c *gin.Context) {
 		filters.HasStockIssue = &hasStockIssue
 	}
 
+	if format != "" {
+		h.export(c, format, filters)
+		return
+	}
+
 	transactions, total, err := h.transactionService.GetAll(page, limit, filters)
 	if err != nil {
 		response.InternalServerError(c, "Failed to get transactions", err.Error())
@@ -155,10 +180,69 @@ func (h *TransactionHandler) GetAll(c *gin.Context) {
 func (h *TransactionHandler) Cancel(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.transactionService.Cancel(id); err != nil {
+	// The reason is optional, so an empty body is an empty request. Chunked
+	// bodies have no length, so only the decoder can tell.
+	var req dto.CancelTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.transactionService.Cancel(id, &req, actorFromContext(c)); err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
 
 	response.Success(c, "Transaction cancelled successfully", nil)
 }
+
+// export streams every transaction matching filters, ignoring pagination
+func (h *TransactionHandler) export(c *gin.Context, format export.Format, filters domain.TransactionFilters) {
+	block := export.HeaderBlock{
+		Title:     "Transactions",
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+	}
+	if setting, err := h.settingService.GetSettingByKey("store_name"); err == nil {
+		block.StoreName = settingString(setting.Value)
+	}
+
+	streamExport(c, format, "transactions", block, func(w export.Writer) error {
+		if err := w.WriteHeader("Transaction Code", "Date", "Cashier", "Customer", "Payment Method", "Status", "Items", "Total", "Discount", "Service Charge", "Tax", "Rounding", "Final Amount", "Stock Issue"); err != nil {
+			return err
+		}
+		return h.transactionService.Export(filters, func(transaction *dto.TransactionResponse) error {
+			createdAt := export.Text(transaction.CreatedAt)
+			if t, err := time.Parse(time.RFC3339, transaction.CreatedAt); err == nil {
+				createdAt = export.DateTime(t)
+			}
+
+			var quantity int64
+			for _, item := range transaction.Items {
+				quantity += int64(item.Quantity)
+			}
+
+			stockIssue := "No"
+			if transaction.HasStockIssue {
+				stockIssue = "Yes"
+			}
+
+			return w.WriteRow(
+				export.Text(transaction.TransactionCode),
+				createdAt,
+				export.Text(transaction.Username),
+				export.Text(transaction.CustomerName),
+				export.Text(transaction.PaymentMethod),
+				export.Text(transaction.PaymentStatus),
+				export.Int(quantity),
+				export.Money(transaction.TotalAmount),
+				export.Money(transaction.DiscountAmount),
+				export.Money(transaction.ServiceChargeAmount),
+				export.Money(transaction.TaxAmount),
+				export.Money(transaction.RoundingAmount),
+				export.Money(transaction.FinalAmount),
+				export.Text(stockIssue),
+			)
+		})
+	})
+}
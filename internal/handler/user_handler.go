@@ -63,7 +63,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Update(id, &req)
+	user, err := h.userService.Update(id, &req, actorFromContext(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
@@ -82,7 +82,7 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.Delete(id); err != nil {
+	if err := h.userService.Delete(id, actorFromContext(c)); err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
@@ -107,7 +107,7 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.ChangePassword(id, &req); err != nil {
+	if err := h.userService.ChangePassword(id, &req, actorFromContext(c)); err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
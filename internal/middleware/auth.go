@@ -43,6 +43,20 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// StreamAuthMiddleware is AuthMiddleware for EventSource clients, which can't
+// send headers: the token may also come in the access_token query parameter
+func StreamAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	authenticate := AuthMiddleware(cfg)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		authenticate(c)
+	}
+}
+
 // RoleMiddleware checks if user has required role
 func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"pos-backend/internal/service"
+	"pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceAuthMiddleware authenticates a POS terminal from the X-Device-Token header.
+// When required is false, requests without the header pass through untouched, but
+// a header that is present must still be valid.
+func DeviceAuthMiddleware(deviceService service.DeviceService, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Device-Token")
+		if token == "" {
+			if required {
+				response.Unauthorized(c, "Device token required")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		device, err := deviceService.Authenticate(token)
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			c.Abort()
+			return
+		}
+
+		// Set device info in context
+		c.Set("device_id", device.ID.String())
+		c.Set("device_name", device.Name)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditChainLockKey serializes appends so every entry sees the latest hash
+const auditChainLockKey = 7243001
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) domain.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) WithTx(tx *gorm.DB) domain.AuditLogRepository {
+	return &auditLogRepository{db: tx}
+}
+
+func (r *auditLogRepository) Append(entry *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+			return err
+		}
+
+		var last domain.AuditLog
+		err := tx.Order("sequence DESC").First(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Postgres keeps microsecond precision, so truncate before hashing
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *auditLogRepository) FindAll(page, limit int, filters domain.AuditLogFilters) ([]domain.AuditLog, int64, error) {
+	var logs []domain.AuditLog
+	var count int64
+
+	query := r.db.Model(&domain.AuditLog{})
+
+	// Apply filters
+	if filters.ActorID != nil {
+		query = query.Where("actor_id = ?", filters.ActorID)
+	}
+	if filters.Action != "" {
+		query = query.Where("action = ?", filters.Action)
+	}
+	if filters.EntityType != "" {
+		query = query.Where("entity_type = ?", filters.EntityType)
+	}
+	if filters.EntityID != "" {
+		query = query.Where("entity_id = ?", filters.EntityID)
+	}
+	if filters.StartDate != nil {
+		query = query.Where("created_at >= ?", filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("created_at <= ?", filters.EndDate)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("sequence DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, count, nil
+}
+
+func (r *auditLogRepository) FindAfterSequence(sequence int64, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	err := r.db.Where("sequence > ?", sequence).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
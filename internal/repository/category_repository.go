@@ -15,6 +15,10 @@ func NewCategoryRepository(db *gorm.DB) domain.CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
+func (r *categoryRepository) WithTx(tx *gorm.DB) domain.CategoryRepository {
+	return &categoryRepository{db: tx}
+}
+
 func (r *categoryRepository) Create(category *domain.Category) error {
 	return r.db.Create(category).Error
 }
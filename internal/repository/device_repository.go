@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) domain.DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+func (r *deviceRepository) WithTx(tx *gorm.DB) domain.DeviceRepository {
+	return &deviceRepository{db: tx}
+}
+
+func (r *deviceRepository) Create(device *domain.Device) error {
+	return r.db.Create(device).Error
+}
+
+func (r *deviceRepository) FindByID(id uuid.UUID) (*domain.Device, error) {
+	var device domain.Device
+	if err := r.db.First(&device, id).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindByPairingCodeHash(hash string) (*domain.Device, error) {
+	var device domain.Device
+	if err := r.db.Where("pairing_code_hash = ?", hash).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) Update(device *domain.Device) error {
+	return r.db.Save(device).Error
+}
+
+func (r *deviceRepository) UpdateLastSeen(id uuid.UUID, seenAt time.Time) error {
+	return r.db.Model(&domain.Device{}).Where("id = ?", id).
+		UpdateColumn("last_seen_at", seenAt).Error
+}
+
+func (r *deviceRepository) UpdateSyncStatus(id uuid.UUID, status string, count int, syncedAt time.Time) error {
+	return r.db.Model(&domain.Device{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_sync_at":     syncedAt,
+			"last_sync_status": status,
+			"last_sync_count":  count,
+			"last_seen_at":     syncedAt,
+		}).Error
+}
+
+func (r *deviceRepository) FindAll(page, limit int) ([]domain.Device, int64, error) {
+	var devices []domain.Device
+	var count int64
+	if err := r.db.Model(&domain.Device{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&devices).Error; err != nil {
+		return nil, 0, err
+	}
+	return devices, count, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type deviceSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceSigningKeyRepository(db *gorm.DB) domain.DeviceSigningKeyRepository {
+	return &deviceSigningKeyRepository{db: db}
+}
+
+func (r *deviceSigningKeyRepository) WithTx(tx *gorm.DB) domain.DeviceSigningKeyRepository {
+	return &deviceSigningKeyRepository{db: tx}
+}
+
+func (r *deviceSigningKeyRepository) Create(key *domain.DeviceSigningKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *deviceSigningKeyRepository) FindByID(id uuid.UUID) (*domain.DeviceSigningKey, error) {
+	var key domain.DeviceSigningKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *deviceSigningKeyRepository) FindByDevice(deviceID uuid.UUID) ([]domain.DeviceSigningKey, error) {
+	var keys []domain.DeviceSigningKey
+	err := r.db.Where("device_id = ?", deviceID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *deviceSigningKeyRepository) RetireActive(deviceID uuid.UUID, retiredAt time.Time) error {
+	return r.db.Model(&domain.DeviceSigningKey{}).
+		Where("device_id = ? AND status = ?", deviceID, "active").
+		Updates(map[string]interface{}{
+			"status":     "retired",
+			"retired_at": retiredAt,
+		}).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type diningRepository struct {
+	db *gorm.DB
+}
+
+func NewDiningRepository(db *gorm.DB) domain.DiningRepository {
+	return &diningRepository{db: db}
+}
+
+func (r *diningRepository) WithTx(tx *gorm.DB) domain.DiningRepository {
+	return &diningRepository{db: tx}
+}
+
+func (r *diningRepository) CreateArea(area *domain.DiningArea) error {
+	return r.db.Create(area).Error
+}
+
+func (r *diningRepository) FindAreaByID(id uuid.UUID) (*domain.DiningArea, error) {
+	var area domain.DiningArea
+	if err := r.db.First(&area, id).Error; err != nil {
+		return nil, err
+	}
+	return &area, nil
+}
+
+func (r *diningRepository) FindAreas(storeID *uuid.UUID) ([]domain.DiningArea, error) {
+	var areas []domain.DiningArea
+	query := r.db.Model(&domain.DiningArea{})
+	if storeID != nil {
+		query = query.Where("store_id = ?", *storeID)
+	}
+	if err := query.Order("sort_order ASC, name ASC").Find(&areas).Error; err != nil {
+		return nil, err
+	}
+	return areas, nil
+}
+
+func (r *diningRepository) UpdateArea(area *domain.DiningArea) error {
+	return r.db.Save(area).Error
+}
+
+func (r *diningRepository) DeleteArea(id uuid.UUID) error {
+	return r.db.Delete(&domain.DiningArea{}, id).Error
+}
+
+func (r *diningRepository) CreateTable(table *domain.DiningTable) error {
+	return r.db.Omit("Area").Create(table).Error
+}
+
+func (r *diningRepository) FindTableByID(id uuid.UUID) (*domain.DiningTable, error) {
+	var table domain.DiningTable
+	if err := r.db.Preload("Area").First(&table, id).Error; err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *diningRepository) FindTables(storeID, areaID *uuid.UUID) ([]domain.DiningTable, error) {
+	var tables []domain.DiningTable
+	query := r.db.Model(&domain.DiningTable{}).
+		Joins("JOIN dining_areas ON dining_areas.id = dining_tables.area_id AND dining_areas.deleted_at IS NULL")
+	if storeID != nil {
+		query = query.Where("dining_areas.store_id = ?", *storeID)
+	}
+	if areaID != nil {
+		query = query.Where("dining_tables.area_id = ?", *areaID)
+	}
+	if err := query.Preload("Area").Order("dining_areas.sort_order ASC, dining_tables.name ASC").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (r *diningRepository) UpdateTable(table *domain.DiningTable) error {
+	return r.db.Omit("Area").Save(table).Error
+}
+
+func (r *diningRepository) DeleteTable(id uuid.UUID) error {
+	return r.db.Delete(&domain.DiningTable{}, id).Error
+}
+
+func (r *diningRepository) CountTables(areaID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.DiningTable{}).Where("area_id = ?", areaID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type heldOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewHeldOrderRepository(db *gorm.DB) domain.HeldOrderRepository {
+	return &heldOrderRepository{db: db}
+}
+
+func (r *heldOrderRepository) WithTx(tx *gorm.DB) domain.HeldOrderRepository {
+	return &heldOrderRepository{db: tx}
+}
+
+func (r *heldOrderRepository) CreateTx(tx *gorm.DB, order *domain.HeldOrder) error {
+	return tx.Create(order).Error
+}
+
+func (r *heldOrderRepository) FindByID(id uuid.UUID) (*domain.HeldOrder, error) {
+	var order domain.HeldOrder
+	if err := r.db.Preload("Items").Preload("User").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *heldOrderRepository) FindAll(page, limit int, filters domain.HeldOrderFilters) ([]domain.HeldOrder, int64, error) {
+	var orders []domain.HeldOrder
+	var count int64
+
+	query := r.db.Model(&domain.HeldOrder{})
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.StoreID != nil {
+		query = query.Where("store_id = ?", *filters.StoreID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Preload("Items").Preload("User").Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+	return orders, count, nil
+}
+
+func (r *heldOrderRepository) Update(order *domain.HeldOrder) error {
+	return r.db.Omit("Items", "Reservations", "User").Save(order).Error
+}
+
+func (r *heldOrderRepository) ReplaceTx(tx *gorm.DB, order *domain.HeldOrder) error {
+	if _, err := r.lockHeldTx(tx, order.ID); err != nil {
+		return err
+	}
+	if err := tx.Where("held_order_id = ?", order.ID).Delete(&domain.HeldOrderItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("held_order_id = ?", order.ID).Delete(&domain.HeldOrderReservation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Omit("Items", "Reservations", "User").Save(order).Error; err != nil {
+		return err
+	}
+	for i := range order.Items {
+		order.Items[i].ID = uuid.Nil
+		order.Items[i].HeldOrderID = order.ID
+	}
+	if len(order.Items) > 0 {
+		if err := tx.Create(&order.Items).Error; err != nil {
+			return err
+		}
+	}
+	for i := range order.Reservations {
+		order.Reservations[i].ID = uuid.Nil
+		order.Reservations[i].HeldOrderID = order.ID
+	}
+	if len(order.Reservations) == 0 {
+		return nil
+	}
+	return tx.Create(&order.Reservations).Error
+}
+
+func (r *heldOrderRepository) ReservedQuantityTx(tx *gorm.DB, productID uuid.UUID, exclude *uuid.UUID) (int, error) {
+	query := tx.Table("held_order_reservations").
+		Select("COALESCE(SUM(held_order_reservations.quantity), 0)").
+		Joins("JOIN held_orders ON held_orders.id = held_order_reservations.held_order_id").
+		Where("held_order_reservations.product_id = ?", productID).
+		Where("held_orders.status = ?", domain.HeldOrderStatusHeld).
+		Where("held_orders.reserve_stock AND held_orders.reserved_until > ?", time.Now())
+	if exclude != nil {
+		query = query.Where("held_orders.id <> ?", *exclude)
+	}
+
+	var reserved int
+	if err := query.Scan(&reserved).Error; err != nil {
+		return 0, err
+	}
+	return reserved, nil
+}
+
+func (r *heldOrderRepository) CompleteTx(tx *gorm.DB, id uuid.UUID, transactionID uuid.UUID, updatedAt time.Time) error {
+	order, err := r.lockHeldTx(tx, id)
+	if err != nil {
+		return err
+	}
+	if !order.UpdatedAt.Equal(updatedAt) {
+		return errors.New("held order changed while it was being completed, please try again")
+	}
+
+	result := tx.Model(&domain.HeldOrder{}).
+		Where("id = ? AND status = ?", id, domain.HeldOrderStatusHeld).
+		Updates(map[string]interface{}{
+			"status":         domain.HeldOrderStatusCompleted,
+			"transaction_id": transactionID,
+			"reserved_until": nil,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("held order is no longer open")
+	}
+	return nil
+}
+
+// lockHeldTx locks a held order row until tx ends so its cart can't change
+// underneath, failing when it is no longer held
+func (r *heldOrderRepository) lockHeldTx(tx *gorm.DB, id uuid.UUID) (*domain.HeldOrder, error) {
+	var order domain.HeldOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("status", "updated_at").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	if order.Status != domain.HeldOrderStatusHeld {
+		return nil, errors.New("held order is no longer open")
+	}
+	return &order, nil
+}
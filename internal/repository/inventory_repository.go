@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) domain.InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+func (r *inventoryRepository) FindMovements(page, limit int, filters domain.InventoryMovementFilters) ([]domain.InventoryMovement, int64, error) {
+	var movements []domain.InventoryMovement
+	var count int64
+
+	query := r.db.Model(&domain.InventoryMovement{})
+
+	// Apply filters
+	if filters.ProductID != nil {
+		query = query.Where("product_id = ?", filters.ProductID)
+	}
+	if filters.MovementType != "" {
+		query = query.Where("movement_type = ?", filters.MovementType)
+	}
+	if filters.ReferenceType != "" {
+		query = query.Where("reference_type = ?", filters.ReferenceType)
+	}
+	if filters.StartDate != nil {
+		query = query.Where("created_at >= ?", filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("created_at < ?", filters.EndDate)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Product").Preload("User").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return movements, count, nil
+}
+
+func (r *inventoryRepository) Valuation(asOf time.Time, productID *uuid.UUID) ([]domain.InventoryValuation, error) {
+	var rows []domain.InventoryValuation
+
+	query := r.db.Table("inventory_movements").
+		Select(`
+			products.id as product_id,
+			products.name as product_name,
+			products.sku,
+			COALESCE(SUM(inventory_movements.quantity), 0) as quantity,
+			COALESCE(SUM(inventory_movements.total_cost), 0) as value
+		`).
+		Joins("JOIN products ON products.id = inventory_movements.product_id").
+		Where("inventory_movements.created_at <= ?", asOf).
+		Group("products.id, products.name, products.sku").
+		Order("products.name ASC")
+
+	if productID != nil {
+		query = query.Where("inventory_movements.product_id = ?", productID)
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *inventoryRepository) SoldQuantities(since time.Time, productID *uuid.UUID) ([]domain.ProductSoldQuantity, error) {
+	var rows []domain.ProductSoldQuantity
+
+	// Sales are booked as negative quantities and cancellations put them back
+	query := r.db.Table("inventory_movements").
+		Select("product_id, -SUM(quantity) as quantity").
+		Where("reference_type IN ?", []string{"transaction", "transaction_cancel"}).
+		Where("created_at >= ?", since).
+		Group("product_id")
+
+	if productID != nil {
+		query = query.Where("product_id = ?", productID)
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
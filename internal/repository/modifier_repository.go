@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type modifierRepository struct {
+	db *gorm.DB
+}
+
+func NewModifierRepository(db *gorm.DB) domain.ModifierRepository {
+	return &modifierRepository{db: db}
+}
+
+func (r *modifierRepository) WithTx(tx *gorm.DB) domain.ModifierRepository {
+	return &modifierRepository{db: tx}
+}
+
+func (r *modifierRepository) Create(group *domain.ModifierGroup) error {
+	return r.db.Create(group).Error
+}
+
+func (r *modifierRepository) FindByID(id uuid.UUID) (*domain.ModifierGroup, error) {
+	var group domain.ModifierGroup
+	if err := r.db.Preload("Options", orderOptions).First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *modifierRepository) FindAll(page, limit int) ([]domain.ModifierGroup, int64, error) {
+	var groups []domain.ModifierGroup
+	var count int64
+	if err := r.db.Model(&domain.ModifierGroup{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Preload("Options", orderOptions).Order("name ASC").Offset((page - 1) * limit).Limit(limit).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, count, nil
+}
+
+func (r *modifierRepository) Update(group *domain.ModifierGroup) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Options").Save(group).Error; err != nil {
+			return err
+		}
+
+		keep := []uuid.UUID{}
+		for i := range group.Options {
+			group.Options[i].GroupID = group.ID
+			if err := tx.Omit("Ingredient").Save(&group.Options[i]).Error; err != nil {
+				return err
+			}
+			keep = append(keep, group.Options[i].ID)
+		}
+
+		query := tx.Where("group_id = ?", group.ID)
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		return query.Delete(&domain.ModifierOption{}).Error
+	})
+}
+
+func (r *modifierRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("modifier_group_id = ?", id).Delete(&domain.ProductModifierGroup{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.ModifierGroup{}, id).Error
+	})
+}
+
+func (r *modifierRepository) FindByProduct(productID uuid.UUID) ([]domain.ModifierGroup, error) {
+	return r.FindByProductTx(r.db, productID)
+}
+
+func (r *modifierRepository) FindByProductTx(tx *gorm.DB, productID uuid.UUID) ([]domain.ModifierGroup, error) {
+	var groups []domain.ModifierGroup
+	err := tx.Joins("JOIN product_modifier_groups ON product_modifier_groups.modifier_group_id = modifier_groups.id").
+		Where("product_modifier_groups.product_id = ?", productID).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return orderOptions(db.Where("is_active"))
+		}).
+		Order("product_modifier_groups.sort_order ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *modifierRepository) SetProductGroups(productID uuid.UUID, groupIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&domain.ProductModifierGroup{}).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+
+		links := make([]domain.ProductModifierGroup, 0, len(groupIDs))
+		for i, groupID := range groupIDs {
+			links = append(links, domain.ProductModifierGroup{ProductID: productID, ModifierGroupID: groupID, SortOrder: i})
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+func (r *modifierRepository) IsInUse(id uuid.UUID) (bool, error) {
+	var inUse bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM product_modifier_groups
+		JOIN products ON products.id = product_modifier_groups.product_id
+		WHERE product_modifier_groups.modifier_group_id = ? AND products.deleted_at IS NULL)`, id).Scan(&inUse).Error
+	return inUse, err
+}
+
+func orderOptions(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, name ASC")
+}
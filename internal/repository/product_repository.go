@@ -15,13 +15,17 @@ func NewProductRepository(db *gorm.DB) domain.ProductRepository {
 	return &productRepository{db: db}
 }
 
+func (r *productRepository) WithTx(tx *gorm.DB) domain.ProductRepository {
+	return &productRepository{db: tx}
+}
+
 func (r *productRepository) Create(product *domain.Product) error {
-	return r.db.Create(product).Error
+	return r.db.Omit("BundleItems").Create(product).Error
 }
 
 func (r *productRepository) FindByID(id uuid.UUID) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.Preload("Category").First(&product, id).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("BundleItems.Component").First(&product, id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
@@ -29,14 +33,14 @@ func (r *productRepository) FindByID(id uuid.UUID) (*domain.Product, error) {
 
 func (r *productRepository) FindBySKU(sku string) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.Where("sku = ?", sku).First(&product).Error; err != nil {
+	if err := r.db.Preload("BundleItems.Component").Where("sku = ?", sku).First(&product).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
 func (r *productRepository) Update(product *domain.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit("BundleItems", "Category", "Stock", "Cost", "StockVersion", "LastStockUpdate").Save(product).Error
 }
 
 func (r *productRepository) Delete(id uuid.UUID) error {
@@ -49,7 +53,7 @@ func (r *productRepository) FindAll(page, limit int) ([]domain.Product, int64, e
 	if err := r.db.Model(&domain.Product{}).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := r.db.Preload("Category").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("BundleItems.Component").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 	return products, count, nil
@@ -78,7 +82,7 @@ func (r *productRepository) FindAllWithFilter(search string, categoryID *uuid.UU
 	}
 
 	// Get paginated results
-	if err := query.Preload("Category").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
+	if err := query.Preload("Category").Preload("BundleItems.Component").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -92,8 +96,65 @@ func (r *productRepository) FindByCategory(categoryID uuid.UUID, page, limit int
 	if err := query.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := query.Preload("Category").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
+	if err := query.Preload("Category").Preload("BundleItems.Component").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	return products, count, nil
+}
+
+func (r *productRepository) FindAllActive(categoryID *uuid.UUID) ([]domain.Product, error) {
+	var products []domain.Product
+
+	query := r.db.Where("is_active = ?", true)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+
+	if err := query.Order("name ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *productRepository) FindBundles(page, limit int) ([]domain.Product, int64, error) {
+	var products []domain.Product
+	var count int64
+	query := r.db.Model(&domain.Product{}).Where("is_bundle = ?", true)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Preload("Category").Preload("BundleItems.Component").Order("name ASC").Offset((page - 1) * limit).Limit(limit).Find(&products).Error; err != nil {
 		return nil, 0, err
 	}
 	return products, count, nil
 }
+
+func (r *productRepository) SetBundleItems(bundleID uuid.UUID, items []domain.BundleItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundleID).Delete(&domain.BundleItem{}).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ID = uuid.Nil
+			items[i].BundleID = bundleID
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Omit("Component").Create(&items).Error
+	})
+}
+
+func (r *productRepository) FindBundleItemsTx(tx *gorm.DB, bundleID uuid.UUID) ([]domain.BundleItem, error) {
+	var items []domain.BundleItem
+	if err := tx.Where("bundle_id = ?", bundleID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *productRepository) IsBundleComponent(productID uuid.UUID) (bool, error) {
+	var used bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM bundle_items WHERE component_id = ?)`, productID).Scan(&used).Error
+	return used, err
+}
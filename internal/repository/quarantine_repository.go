@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type quarantineRepository struct {
+	db *gorm.DB
+}
+
+func NewQuarantineRepository(db *gorm.DB) domain.QuarantineRepository {
+	return &quarantineRepository{db: db}
+}
+
+func (r *quarantineRepository) CreateTx(tx *gorm.DB, entry *domain.QuarantinedTransaction) error {
+	return tx.Create(entry).Error
+}
+
+func (r *quarantineRepository) FindByID(id uuid.UUID) (*domain.QuarantinedTransaction, error) {
+	var entry domain.QuarantinedTransaction
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *quarantineRepository) FindPendingByClientTransactionID(clientTxID string) (*domain.QuarantinedTransaction, error) {
+	var entry domain.QuarantinedTransaction
+	if err := r.db.Where("client_transaction_id = ? AND status = ?", clientTxID, "pending").First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *quarantineRepository) FindAll(page, limit int, status string) ([]domain.QuarantinedTransaction, int64, error) {
+	var entries []domain.QuarantinedTransaction
+	var count int64
+
+	query := r.db.Model(&domain.QuarantinedTransaction{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, count, nil
+}
+
+func (r *quarantineRepository) ReviewTx(tx *gorm.DB, entry *domain.QuarantinedTransaction) error {
+	result := tx.Model(&domain.QuarantinedTransaction{}).
+		Where("id = ? AND status = ?", entry.ID, "pending").
+		Updates(map[string]interface{}{
+			"status":         entry.Status,
+			"review_notes":   entry.ReviewNotes,
+			"reviewed_at":    entry.ReviewedAt,
+			"reviewed_by":    entry.ReviewedBy,
+			"transaction_id": entry.TransactionID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("quarantined transaction has already been reviewed")
+	}
+	return nil
+}
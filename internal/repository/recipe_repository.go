@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type recipeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecipeRepository(db *gorm.DB) domain.RecipeRepository {
+	return &recipeRepository{db: db}
+}
+
+func (r *recipeRepository) WithTx(tx *gorm.DB) domain.RecipeRepository {
+	return &recipeRepository{db: tx}
+}
+
+func (r *recipeRepository) Save(recipe *domain.Recipe) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if recipe.ID != uuid.Nil {
+			if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&domain.RecipeComponent{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Product", "Components").Save(recipe).Error; err != nil {
+			return err
+		}
+
+		for i := range recipe.Components {
+			recipe.Components[i].ID = uuid.Nil
+			recipe.Components[i].RecipeID = recipe.ID
+		}
+		if len(recipe.Components) == 0 {
+			return nil
+		}
+		return tx.Omit("Component").Create(&recipe.Components).Error
+	})
+}
+
+func (r *recipeRepository) FindByProduct(productID uuid.UUID) (*domain.Recipe, error) {
+	var recipe domain.Recipe
+	if err := r.db.Preload("Product").Preload("Components.Component").Where("product_id = ?", productID).First(&recipe).Error; err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+func (r *recipeRepository) FindActiveByProductTx(tx *gorm.DB, productID uuid.UUID) (*domain.Recipe, error) {
+	var recipe domain.Recipe
+	err := tx.Preload("Components").Where("product_id = ? AND is_active", productID).First(&recipe).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+func (r *recipeRepository) FindAll(page, limit int) ([]domain.Recipe, int64, error) {
+	var recipes []domain.Recipe
+	var count int64
+	if err := r.db.Model(&domain.Recipe{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.db.Preload("Product").Preload("Components.Component").
+		Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&recipes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return recipes, count, nil
+}
+
+func (r *recipeRepository) Delete(productID uuid.UUID) error {
+	return r.db.Where("product_id = ?", productID).Delete(&domain.Recipe{}).Error
+}
+
+func (r *recipeRepository) IsComponent(productID uuid.UUID) (bool, error) {
+	var used bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM recipe_components WHERE component_id = ?)`, productID).Scan(&used).Error
+	return used, err
+}
+
+func (r *recipeRepository) ActiveProductIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.Recipe{}).Where("is_active").Pluck("product_id", &ids).Error
+	return ids, err
+}
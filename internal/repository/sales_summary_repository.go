@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/pkg/businessday"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type salesSummaryRepository struct {
+	db *gorm.DB
+}
+
+func NewSalesSummaryRepository(db *gorm.DB) domain.SalesSummaryRepository {
+	return &salesSummaryRepository{db: db}
+}
+
+func (r *salesSummaryRepository) AddTx(tx *gorm.DB, summary domain.DailySalesSummary, products []domain.DailyProductSales) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "business_date"}, {Name: "store_id"}, {Name: "payment_method"}},
+		DoUpdates: additive("daily_sales_summaries", []string{
+			"transaction_count", "total_amount", "discount_amount", "tax_amount", "revenue",
+			"items_sold", "cancelled_count", "cancelled_amount", "rounded_count", "rounding_gain", "rounding_loss",
+		}),
+	}).Create(&summary).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "business_date"}, {Name: "store_id"}, {Name: "product_id"}, {Name: "product_name"},
+				{Name: "category_id"}, {Name: "category_name"},
+			},
+			DoUpdates: additive("daily_product_sales", []string{"quantity", "revenue", "cost", "below_cost_lines"}),
+		}).Create(&products[i]).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// A product with nothing left sold that day is only noise in the reports
+	if len(products) > 0 {
+		return tx.Where("business_date = ? AND store_id = ? AND quantity = 0 AND revenue = 0", summary.BusinessDate.Format("2006-01-02"), summary.StoreID).
+			Delete(&domain.DailyProductSales{}).Error
+	}
+	return nil
+}
+
+func (r *salesSummaryRepository) Rebuild(calendar businessday.Calendar, start, end *time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Sales committing meanwhile wait for the rebuild, then add themselves on top of it
+		if err := tx.Exec("LOCK TABLE daily_sales_summaries, daily_product_sales IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		dateFilter, dateArgs := "", []interface{}{}
+		transactionFilter, transactionArgs := "", []interface{}{}
+		if start != nil {
+			dateFilter += " AND business_date >= ?"
+			dateArgs = append(dateArgs, calendar.Format(*start))
+			transactionFilter += " AND t.created_at >= ?"
+			transactionArgs = append(transactionArgs, *start)
+		}
+		if end != nil {
+			dateFilter += " AND business_date < ?"
+			dateArgs = append(dateArgs, calendar.Format(*end))
+			transactionFilter += " AND t.created_at < ?"
+			transactionArgs = append(transactionArgs, *end)
+		}
+
+		for _, table := range []string{"daily_sales_summaries", "daily_product_sales"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE TRUE"+dateFilter, dateArgs...).Error; err != nil {
+				return err
+			}
+		}
+
+		businessDate := calendar.DateSQL("t.created_at")
+
+		salesSQL := fmt.Sprintf(`
+			INSERT INTO daily_sales_summaries (
+				business_date, store_id, payment_method, transaction_count, total_amount, discount_amount,
+				tax_amount, revenue, items_sold, cancelled_count, cancelled_amount,
+				rounded_count, rounding_gain, rounding_loss, updated_at
+			)
+			SELECT
+				%s,
+				COALESCE(t.store_id, ?),
+				t.payment_method,
+				COUNT(*) FILTER (WHERE t.payment_status = 'completed'),
+				COALESCE(SUM(t.total_amount) FILTER (WHERE t.payment_status = 'completed'), 0),
+				COALESCE(SUM(t.discount_amount) FILTER (WHERE t.payment_status = 'completed'), 0),
+				COALESCE(SUM(t.tax_amount) FILTER (WHERE t.payment_status = 'completed'), 0),
+				COALESCE(SUM(t.final_amount) FILTER (WHERE t.payment_status = 'completed'), 0),
+				COALESCE(SUM((SELECT SUM(quantity) FROM transaction_items WHERE transaction_id = t.id)) FILTER (WHERE t.payment_status = 'completed'), 0),
+				COUNT(*) FILTER (WHERE t.payment_status = 'cancelled'),
+				COALESCE(SUM(t.final_amount) FILTER (WHERE t.payment_status = 'cancelled'), 0),
+				COUNT(*) FILTER (WHERE t.payment_status = 'completed' AND t.rounding_amount <> 0),
+				COALESCE(SUM(t.rounding_amount) FILTER (WHERE t.payment_status = 'completed' AND t.rounding_amount > 0), 0),
+				COALESCE(-SUM(t.rounding_amount) FILTER (WHERE t.payment_status = 'completed' AND t.rounding_amount < 0), 0),
+				NOW()
+			FROM transactions t
+			WHERE t.deleted_at IS NULL
+			  AND t.payment_status IN ('completed', 'cancelled')%s
+			GROUP BY 1, 2, 3`, businessDate, transactionFilter)
+		if err := tx.Exec(salesSQL, append([]interface{}{uuid.Nil}, transactionArgs...)...).Error; err != nil {
+			return err
+		}
+
+		productSQL := fmt.Sprintf(`
+			INSERT INTO daily_product_sales (
+				business_date, store_id, product_id, product_name, category_id, category_name,
+				quantity, revenue, cost, below_cost_lines, updated_at
+			)
+			SELECT
+				%s,
+				COALESCE(t.store_id, ?),
+				COALESCE(ti.product_id, ?),
+				ti.product_name,
+				COALESCE(ti.category_id, ?),
+				CASE WHEN ti.category_id IS NULL THEN '' ELSE COALESCE(ti.category_name, '') END,
+				SUM(ti.quantity),
+				SUM(ti.subtotal),
+				SUM(ti.total_cost),
+				COUNT(*) FILTER (WHERE ti.product_price + ti.modifier_amount < ti.unit_cost),
+				NOW()
+			FROM transaction_items ti
+			JOIN transactions t ON t.id = ti.transaction_id
+			WHERE t.deleted_at IS NULL
+			  AND t.payment_status = 'completed'%s
+			GROUP BY 1, 2, 3, 4, 5, 6`, businessDate, transactionFilter)
+		return tx.Exec(productSQL, append([]interface{}{uuid.Nil, uuid.Nil, uuid.Nil}, transactionArgs...)...).Error
+	})
+}
+
+func (r *salesSummaryRepository) IsEmpty() (bool, error) {
+	var exists bool
+	if err := r.db.Raw("SELECT EXISTS (SELECT 1 FROM daily_sales_summaries)").Scan(&exists).Error; err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+// additive builds "column = table.column + EXCLUDED.column" for each column
+func additive(table string, columns []string) clause.Set {
+	set := make(clause.Set, len(columns)+1)
+	for i, column := range columns {
+		set[i] = clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr(fmt.Sprintf("%s.%s + EXCLUDED.%s", table, column, column)),
+		}
+	}
+	set[len(columns)] = clause.Assignment{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")}
+	return set
+}
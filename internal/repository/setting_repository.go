@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"encoding/json"
 	"pos-backend/internal/domain"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type settingRepository struct {
@@ -14,6 +17,10 @@ func NewSettingRepository(db *gorm.DB) domain.SettingRepository {
 	return &settingRepository{db: db}
 }
 
+func (r *settingRepository) WithTx(tx *gorm.DB) domain.SettingRepository {
+	return &settingRepository{db: tx}
+}
+
 func (r *settingRepository) GetByKey(key string) (*domain.Setting, error) {
 	var setting domain.Setting
 	err := r.db.Where("key = ?", key).First(&setting).Error
@@ -65,3 +72,125 @@ func (r *settingRepository) BulkUpsert(settings []domain.Setting) error {
 func (r *settingRepository) Delete(key string) error {
 	return r.db.Where("key = ?", key).Delete(&domain.Setting{}).Error
 }
+
+func (r *settingRepository) SaveVersion(settings []domain.Setting, version *domain.SettingVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// One writer at a time so version numbers and snapshots follow each other
+		if err := tx.Exec("LOCK TABLE setting_versions IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		if err := (&settingRepository{db: tx}).BulkUpsert(settings); err != nil {
+			return err
+		}
+
+		var latest int64
+		if err := tx.Model(&domain.SettingVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+
+		var all []domain.Setting
+		if err := tx.Find(&all).Error; err != nil {
+			return err
+		}
+		snapshot := make(domain.SettingSnapshot, len(all))
+		for _, setting := range all {
+			value := json.RawMessage(setting.Value)
+			if !json.Valid(value) {
+				value, _ = json.Marshal(setting.Value)
+			}
+			snapshot[setting.Key] = domain.SettingSnapshotEntry{Category: setting.Category, Value: value}
+		}
+		snapshotJSON, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+		version.Snapshot = string(snapshotJSON)
+
+		var overrides []domain.SettingOverride
+		if err := tx.Order("scope ASC, scope_id ASC, key ASC").Find(&overrides).Error; err != nil {
+			return err
+		}
+		overrideSnapshot := make([]domain.SettingOverrideSnapshotEntry, 0, len(overrides))
+		for _, override := range overrides {
+			value := json.RawMessage(override.Value)
+			if !json.Valid(value) {
+				value, _ = json.Marshal(override.Value)
+			}
+			overrideSnapshot = append(overrideSnapshot, domain.SettingOverrideSnapshotEntry{
+				Scope:    override.Scope,
+				ScopeID:  override.ScopeID,
+				Key:      override.Key,
+				Category: override.Category,
+				Value:    value,
+			})
+		}
+		overrideSnapshotJSON, err := json.Marshal(overrideSnapshot)
+		if err != nil {
+			return err
+		}
+		version.OverrideSnapshot = string(overrideSnapshotJSON)
+
+		return tx.Create(version).Error
+	})
+}
+
+func (r *settingRepository) FindVersion(version int64) (*domain.SettingVersion, error) {
+	var settingVersion domain.SettingVersion
+	if err := r.db.Where("version = ?", version).First(&settingVersion).Error; err != nil {
+		return nil, err
+	}
+	return &settingVersion, nil
+}
+
+func (r *settingRepository) FindVersions(page, limit int) ([]domain.SettingVersion, int64, error) {
+	var versions []domain.SettingVersion
+	var count int64
+	if err := r.db.Model(&domain.SettingVersion{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("version DESC").Offset((page - 1) * limit).Limit(limit).Find(&versions).Error; err != nil {
+		return nil, 0, err
+	}
+	return versions, count, nil
+}
+
+func (r *settingRepository) HasVersions() (bool, error) {
+	var exists bool
+	if err := r.db.Raw("SELECT EXISTS (SELECT 1 FROM setting_versions)").Scan(&exists).Error; err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *settingRepository) FindOverrides(scope string, scopeID uuid.UUID) ([]domain.SettingOverride, error) {
+	var overrides []domain.SettingOverride
+	err := r.db.Where("scope = ? AND scope_id = ?", scope, scopeID).Order("key ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *settingRepository) FindAllOverrides() ([]domain.SettingOverride, error) {
+	var overrides []domain.SettingOverride
+	err := r.db.Order("scope ASC, scope_id ASC, key ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *settingRepository) FindOverride(scope string, scopeID uuid.UUID, key string) (*domain.SettingOverride, error) {
+	var override domain.SettingOverride
+	if err := r.db.Where("scope = ? AND scope_id = ? AND key = ?", scope, scopeID, key).First(&override).Error; err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *settingRepository) UpsertOverride(override *domain.SettingOverride) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "category", "updated_at"}),
+	}).Create(override).Error
+}
+
+func (r *settingRepository) DeleteOverride(scope string, scopeID uuid.UUID, key string) error {
+	return r.db.Where("scope = ? AND scope_id = ? AND key = ?", scope, scopeID, key).Delete(&domain.SettingOverride{}).Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type storeRepository struct {
+	db *gorm.DB
+}
+
+func NewStoreRepository(db *gorm.DB) domain.StoreRepository {
+	return &storeRepository{db: db}
+}
+
+func (r *storeRepository) WithTx(tx *gorm.DB) domain.StoreRepository {
+	return &storeRepository{db: tx}
+}
+
+func (r *storeRepository) Create(store *domain.Store) error {
+	return r.db.Create(store).Error
+}
+
+func (r *storeRepository) FindByID(id uuid.UUID) (*domain.Store, error) {
+	var store domain.Store
+	if err := r.db.First(&store, id).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+func (r *storeRepository) FindByCode(code string) (*domain.Store, error) {
+	var store domain.Store
+	if err := r.db.Where("code = ?", code).First(&store).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+func (r *storeRepository) Update(store *domain.Store) error {
+	return r.db.Save(store).Error
+}
+
+func (r *storeRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Store{}, id).Error
+}
+
+func (r *storeRepository) FindAll(page, limit int) ([]domain.Store, int64, error) {
+	var stores []domain.Store
+	var count int64
+	if err := r.db.Model(&domain.Store{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("name ASC").Offset((page - 1) * limit).Limit(limit).Find(&stores).Error; err != nil {
+		return nil, 0, err
+	}
+	return stores, count, nil
+}
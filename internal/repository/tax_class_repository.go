@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type taxClassRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxClassRepository(db *gorm.DB) domain.TaxClassRepository {
+	return &taxClassRepository{db: db}
+}
+
+func (r *taxClassRepository) WithTx(tx *gorm.DB) domain.TaxClassRepository {
+	return &taxClassRepository{db: tx}
+}
+
+func (r *taxClassRepository) Create(taxClass *domain.TaxClass) error {
+	return r.db.Create(taxClass).Error
+}
+
+func (r *taxClassRepository) FindByID(id uuid.UUID) (*domain.TaxClass, error) {
+	var taxClass domain.TaxClass
+	if err := r.db.First(&taxClass, id).Error; err != nil {
+		return nil, err
+	}
+	return &taxClass, nil
+}
+
+func (r *taxClassRepository) FindByCode(code string) (*domain.TaxClass, error) {
+	var taxClass domain.TaxClass
+	if err := r.db.Where("code = ?", code).First(&taxClass).Error; err != nil {
+		return nil, err
+	}
+	return &taxClass, nil
+}
+
+func (r *taxClassRepository) Update(taxClass *domain.TaxClass) error {
+	return r.db.Save(taxClass).Error
+}
+
+func (r *taxClassRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.TaxClass{}, id).Error
+}
+
+func (r *taxClassRepository) FindAll(page, limit int) ([]domain.TaxClass, int64, error) {
+	var taxClasses []domain.TaxClass
+	var count int64
+	if err := r.db.Model(&domain.TaxClass{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("code ASC").Offset((page - 1) * limit).Limit(limit).Find(&taxClasses).Error; err != nil {
+		return nil, 0, err
+	}
+	return taxClasses, count, nil
+}
+
+func (r *taxClassRepository) IsInUse(id uuid.UUID) (bool, error) {
+	var inUse bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM products WHERE tax_class_id = ? AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM categories WHERE tax_class_id = ? AND deleted_at IS NULL)`, id, id).Scan(&inUse).Error
+	return inUse, err
+}
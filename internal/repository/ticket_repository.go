@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ticketRepository struct {
+	db *gorm.DB
+}
+
+func NewTicketRepository(db *gorm.DB) domain.TicketRepository {
+	return &ticketRepository{db: db}
+}
+
+func (r *ticketRepository) WithTx(tx *gorm.DB) domain.TicketRepository {
+	return &ticketRepository{db: tx}
+}
+
+func (r *ticketRepository) Create(ticket *domain.Ticket) error {
+	return r.db.Omit("Table", "User", "Items").Create(ticket).Error
+}
+
+func (r *ticketRepository) FindByID(id uuid.UUID) (*domain.Ticket, error) {
+	var ticket domain.Ticket
+	err := r.db.Preload("Table").Preload("User").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("round ASC, sent_at ASC")
+		}).
+		First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *ticketRepository) FindOpenByTable(tableID uuid.UUID) (*domain.Ticket, error) {
+	var ticket domain.Ticket
+	if err := r.db.Where("table_id = ? AND status = ?", tableID, domain.TicketStatusOpen).First(&ticket).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *ticketRepository) OpenTicketsByTable() (map[uuid.UUID]uuid.UUID, error) {
+	var rows []struct {
+		ID      uuid.UUID
+		TableID uuid.UUID
+	}
+	err := r.db.Model(&domain.Ticket{}).Select("id, table_id").
+		Where("status = ? AND table_id IS NOT NULL", domain.TicketStatusOpen).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := make(map[uuid.UUID]uuid.UUID, len(rows))
+	for _, row := range rows {
+		tickets[row.TableID] = row.ID
+	}
+	return tickets, nil
+}
+
+func (r *ticketRepository) FindAll(page, limit int, filters domain.TicketFilters) ([]domain.Ticket, int64, error) {
+	var tickets []domain.Ticket
+	var count int64
+
+	query := r.db.Model(&domain.Ticket{})
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.StoreID != nil {
+		query = query.Where("store_id = ?", *filters.StoreID)
+	}
+	if filters.TableID != nil {
+		query = query.Where("table_id = ?", *filters.TableID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Table").Preload("User").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("round ASC, sent_at ASC")
+		}).
+		Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&tickets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return tickets, count, nil
+}
+
+func (r *ticketRepository) Update(ticket *domain.Ticket) error {
+	return r.db.Omit("Table", "User", "Items").Save(ticket).Error
+}
+
+func (r *ticketRepository) AddRoundTx(tx *gorm.DB, ticketID uuid.UUID, items []domain.TicketItem) (int, error) {
+	var ticket domain.Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ticket, ticketID).Error; err != nil {
+		return 0, err
+	}
+	if ticket.Status != domain.TicketStatusOpen {
+		return 0, errors.New("ticket is no longer open")
+	}
+
+	round := ticket.Rounds + 1
+	now := time.Now()
+	for i := range items {
+		items[i].TicketID = ticketID
+		items[i].Round = round
+		items[i].KitchenStatus = domain.KitchenStatusQueued
+		items[i].SentAt = now
+		items[i].StatusChangedAt = now
+	}
+	if err := tx.Omit("Ticket").Create(&items).Error; err != nil {
+		return 0, err
+	}
+
+	err := tx.Model(&domain.Ticket{}).Where("id = ?", ticketID).
+		Updates(map[string]interface{}{"rounds": round, "updated_at": now}).Error
+	if err != nil {
+		return 0, err
+	}
+	return round, nil
+}
+
+func (r *ticketRepository) FindItem(id uuid.UUID) (*domain.TicketItem, error) {
+	var item domain.TicketItem
+	if err := r.db.Preload("Ticket.Table").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ticketRepository) UpdateItem(item *domain.TicketItem) error {
+	return r.db.Omit("Ticket").Save(item).Error
+}
+
+func (r *ticketRepository) DeleteItem(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Touch the ticket so a settle that read it before the void fails
+		err := tx.Model(&domain.Ticket{}).
+			Where("id = (SELECT ticket_id FROM ticket_items WHERE id = ?)", id).
+			Update("updated_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&domain.TicketItem{}, id).Error
+	})
+}
+
+func (r *ticketRepository) FindKitchenQueue(filters domain.KitchenQueueFilters) ([]domain.TicketItem, error) {
+	var items []domain.TicketItem
+
+	query := r.db.Model(&domain.TicketItem{}).
+		Joins("JOIN tickets ON tickets.id = ticket_items.ticket_id").
+		Where("tickets.status = ?", domain.TicketStatusOpen)
+	if filters.StoreID != nil {
+		query = query.Where("tickets.store_id = ?", *filters.StoreID)
+	}
+	if len(filters.Statuses) > 0 {
+		query = query.Where("ticket_items.kitchen_status IN ?", filters.Statuses)
+	}
+
+	if err := query.Preload("Ticket.Table").Order("ticket_items.sent_at ASC, ticket_items.round ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ticketRepository) SettleTx(tx *gorm.DB, id uuid.UUID, transactionID uuid.UUID, updatedAt time.Time) error {
+	var ticket domain.Ticket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("status", "updated_at").First(&ticket, id).Error; err != nil {
+		return err
+	}
+	if ticket.Status != domain.TicketStatusOpen {
+		return errors.New("ticket is no longer open")
+	}
+	if !ticket.UpdatedAt.Equal(updatedAt) {
+		return errors.New("ticket changed while it was being settled, please try again")
+	}
+
+	now := time.Now()
+	result := tx.Model(&domain.Ticket{}).
+		Where("id = ? AND status = ?", id, domain.TicketStatusOpen).
+		Updates(map[string]interface{}{
+			"status":         domain.TicketStatusSettled,
+			"transaction_id": transactionID,
+			"closed_at":      now,
+			"updated_at":     now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("ticket is no longer open")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"pos-backend/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -21,7 +22,7 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 
 func (r *transactionRepository) FindByID(id uuid.UUID) (*domain.Transaction, error) {
 	var transaction domain.Transaction
-	if err := r.db.Preload("Items.Product").Preload("User").First(&transaction, id).Error; err != nil {
+	if err := r.db.Preload("Items.Product").Preload("Items.Modifiers").Preload("Items.Components").Preload("User").First(&transaction, id).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -29,7 +30,7 @@ func (r *transactionRepository) FindByID(id uuid.UUID) (*domain.Transaction, err
 
 func (r *transactionRepository) FindByTransactionCode(code string) (*domain.Transaction, error) {
 	var transaction domain.Transaction
-	if err := r.db.Preload("Items.Product").Preload("User").Where("transaction_code = ?", code).First(&transaction).Error; err != nil {
+	if err := r.db.Preload("Items.Product").Preload("Items.Modifiers").Preload("Items.Components").Preload("User").Where("transaction_code = ?", code).First(&transaction).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -47,33 +48,13 @@ func (r *transactionRepository) FindAll(page, limit int, filters domain.Transact
 	var transactions []domain.Transaction
 	var count int64
 
-	query := r.db.Model(&domain.Transaction{})
-
-	// Apply filters
-	if filters.UserID != nil {
-		query = query.Where("user_id = ?", filters.UserID)
-	}
-	if filters.PaymentMethod != "" {
-		query = query.Where("payment_method = ?", filters.PaymentMethod)
-	}
-	if filters.PaymentStatus != "" {
-		query = query.Where("payment_status = ?", filters.PaymentStatus)
-	}
-	if filters.StartDate != nil {
-		query = query.Where("created_at >= ?", filters.StartDate)
-	}
-	if filters.EndDate != nil {
-		query = query.Where("created_at <= ?", filters.EndDate)
-	}
-	if filters.HasStockIssue != nil {
-		query = query.Where("has_stock_issue = ?", *filters.HasStockIssue)
-	}
+	query := r.applyFilters(r.db.Model(&domain.Transaction{}), filters)
 
 	if err := query.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := query.Preload("Items.Product").Preload("User").
+	if err := query.Preload("Items.Product").Preload("Items.Modifiers").Preload("Items.Components").Preload("User").
 		Order("created_at DESC").
 		Offset((page - 1) * limit).
 		Limit(limit).
@@ -91,3 +72,68 @@ func (r *transactionRepository) Update(transaction *domain.Transaction) error {
 func (r *transactionRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&domain.Transaction{}, id).Error
 }
+
+// FindAllInBatches walks every matching transaction, newest first, batchSize at a
+// time. It pages by (created_at, id) so rows inserted meanwhile can't shift the batches.
+func (r *transactionRepository) FindAllInBatches(filters domain.TransactionFilters, batchSize int, fn func(transactions []domain.Transaction) error) error {
+	var lastCreatedAt time.Time
+	var lastID uuid.UUID
+
+	for first := true; ; first = false {
+		query := r.applyFilters(r.db.Model(&domain.Transaction{}), filters)
+		if !first {
+			query = query.Where("(created_at, id) < (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var batch []domain.Transaction
+		if err := query.Preload("Items.Modifiers").Preload("Items.Components").Preload("User").
+			Order("created_at DESC, id DESC").
+			Limit(batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		lastCreatedAt = batch[len(batch)-1].CreatedAt
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+func (r *transactionRepository) applyFilters(query *gorm.DB, filters domain.TransactionFilters) *gorm.DB {
+	// Apply filters
+	if filters.UserID != nil {
+		query = query.Where("user_id = ?", filters.UserID)
+	}
+	if filters.DeviceID != nil {
+		query = query.Where("device_id = ?", filters.DeviceID)
+	}
+	if filters.StoreID != nil {
+		query = query.Where("store_id = ?", filters.StoreID)
+	}
+	if filters.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", filters.PaymentMethod)
+	}
+	if filters.PaymentStatus != "" {
+		query = query.Where("payment_status = ?", filters.PaymentStatus)
+	}
+	if filters.StartDate != nil {
+		query = query.Where("created_at >= ?", filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("created_at < ?", filters.EndDate)
+	}
+	if filters.HasStockIssue != nil {
+		query = query.Where("has_stock_issue = ?", *filters.HasStockIssue)
+	}
+
+	return query
+}
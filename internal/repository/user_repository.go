@@ -15,6 +15,10 @@ func NewUserRepository(db *gorm.DB) domain.UserRepository {
 	return &userRepository{db: db}
 }
 
+func (r *userRepository) WithTx(tx *gorm.DB) domain.UserRepository {
+	return &userRepository{db: tx}
+}
+
 func (r *userRepository) Create(user *domain.User) error {
 	return r.db.Create(user).Error
 }
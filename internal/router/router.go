@@ -4,11 +4,12 @@ import (
 	"pos-backend/internal/config"
 	"pos-backend/internal/handler"
 	"pos-backend/internal/middleware"
+	"pos-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, categoryHandler *handler.CategoryHandler, productHandler *handler.ProductHandler, transactionHandler *handler.TransactionHandler, reportsHandler *handler.ReportsHandler, settingHandler *handler.SettingHandler, dashboardHandler *handler.DashboardHandler) *gin.Engine {
+func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandler *handler.UserHandler, categoryHandler *handler.CategoryHandler, storeHandler *handler.StoreHandler, taxClassHandler *handler.TaxClassHandler, productHandler *handler.ProductHandler, modifierHandler *handler.ModifierHandler, recipeHandler *handler.RecipeHandler, transactionHandler *handler.TransactionHandler, reportsHandler *handler.ReportsHandler, settingHandler *handler.SettingHandler, dashboardHandler *handler.DashboardHandler, auditHandler *handler.AuditHandler, deviceHandler *handler.DeviceHandler, deviceService service.DeviceService, quarantineHandler *handler.QuarantineHandler, heldOrderHandler *handler.HeldOrderHandler, diningHandler *handler.DiningHandler, ticketHandler *handler.TicketHandler, inventoryHandler *handler.InventoryHandler) *gin.Engine {
 	// Set Gin mode
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -18,6 +19,7 @@ func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandl
 
 	// Global middlewares
 	r.Use(middleware.RecoveryMiddleware())
+	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(middleware.CORSMiddleware())
 
@@ -39,6 +41,20 @@ func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandl
 			auth.POST("/login", authHandler.Login)
 		}
 
+		// Device pairing (public, the pairing code is the credential)
+		v1.POST("/devices/pair", deviceHandler.Pair)
+
+		// Device routes (device token required)
+		device := v1.Group("/device")
+		device.Use(middleware.DeviceAuthMiddleware(deviceService, true))
+		{
+			device.GET("/config", deviceHandler.GetConfig)
+			device.POST("/signing-keys", deviceHandler.RegisterSigningKey)
+		}
+
+		// Live dashboard stream, EventSource can't send headers so the token may be a query parameter
+		v1.GET("/dashboard/stream", middleware.StreamAuthMiddleware(cfg), dashboardHandler.Stream)
+
 		// Protected routes (auth required)
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(cfg))
@@ -75,20 +91,63 @@ func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandl
 				categories.DELETE("/:id", middleware.RoleMiddleware("admin"), categoryHandler.Delete)
 			}
 
+			// Stores routes
+			stores := protected.Group("/stores")
+			{
+				stores.GET("", storeHandler.GetAll)
+				stores.GET("/:id", storeHandler.GetByID)
+				stores.POST("", middleware.RoleMiddleware("admin"), storeHandler.Create)
+				stores.PUT("/:id", middleware.RoleMiddleware("admin"), storeHandler.Update)
+				stores.DELETE("/:id", middleware.RoleMiddleware("admin"), storeHandler.Delete)
+			}
+
+			// Tax classes routes
+			taxClasses := protected.Group("/tax-classes")
+			{
+				taxClasses.GET("", taxClassHandler.GetAll)
+				taxClasses.GET("/:id", taxClassHandler.GetByID)
+				taxClasses.POST("", middleware.RoleMiddleware("admin"), taxClassHandler.Create)
+				taxClasses.PUT("/:id", middleware.RoleMiddleware("admin"), taxClassHandler.Update)
+				taxClasses.DELETE("/:id", middleware.RoleMiddleware("admin"), taxClassHandler.Delete)
+			}
+
 			// Products routes
 			products := protected.Group("/products")
 			{
 				products.GET("", productHandler.GetAll)
 				products.GET("/category/:category_id", productHandler.GetByCategory)
 				products.GET("/sku/:sku", productHandler.GetBySKU)
+				products.GET("/bundles", productHandler.GetBundles)
 				products.GET("/:id", productHandler.GetByID)
 				products.POST("", middleware.RoleMiddleware("admin", "manager"), productHandler.Create)
 				products.PUT("/:id", middleware.RoleMiddleware("admin", "manager"), productHandler.Update)
 				products.DELETE("/:id", middleware.RoleMiddleware("admin"), productHandler.Delete)
+				products.GET("/:id/modifier-groups", modifierHandler.GetProductGroups)
+				products.PUT("/:id/modifier-groups", middleware.RoleMiddleware("admin", "manager"), modifierHandler.SetProductGroups)
+				products.GET("/:id/recipe", recipeHandler.GetByProduct)
+				products.PUT("/:id/recipe", middleware.RoleMiddleware("admin", "manager"), recipeHandler.Save)
+				products.DELETE("/:id/recipe", middleware.RoleMiddleware("admin", "manager"), recipeHandler.Delete)
+			}
+
+			// Modifier groups routes (extra shot, milk type, toppings)
+			modifierGroups := protected.Group("/modifier-groups")
+			{
+				modifierGroups.GET("", modifierHandler.GetAll)
+				modifierGroups.GET("/:id", modifierHandler.GetByID)
+				modifierGroups.POST("", middleware.RoleMiddleware("admin", "manager"), modifierHandler.Create)
+				modifierGroups.PUT("/:id", middleware.RoleMiddleware("admin", "manager"), modifierHandler.Update)
+				modifierGroups.DELETE("/:id", middleware.RoleMiddleware("admin", "manager"), modifierHandler.Delete)
+			}
+
+			// Recipes routes (components taken from stock when a product sells)
+			recipes := protected.Group("/recipes")
+			{
+				recipes.GET("", recipeHandler.GetAll)
 			}
 
 			// Transactions routes
 			transactions := protected.Group("/transactions")
+			transactions.Use(middleware.DeviceAuthMiddleware(deviceService, false))
 			{
 				transactions.GET("", transactionHandler.GetAll)
 				transactions.GET("/:id", transactionHandler.GetByID)
@@ -97,21 +156,88 @@ func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandl
 				transactions.PATCH("/:id/cancel", middleware.RoleMiddleware("admin", "manager"), transactionHandler.Cancel)
 			}
 
+			// Held orders, parked carts that any device may resume
+			heldOrders := protected.Group("/held-orders")
+			heldOrders.Use(middleware.DeviceAuthMiddleware(deviceService, false))
+			{
+				heldOrders.GET("", heldOrderHandler.GetAll)
+				heldOrders.GET("/:id", heldOrderHandler.GetByID)
+				heldOrders.POST("", heldOrderHandler.Hold)
+				heldOrders.PUT("/:id", heldOrderHandler.Update)
+				heldOrders.POST("/:id/resume", heldOrderHandler.Resume)
+				heldOrders.POST("/:id/complete", heldOrderHandler.Complete)
+				heldOrders.POST("/:id/cancel", heldOrderHandler.Cancel)
+			}
+
+			// Dining floor plan routes
+			dining := protected.Group("/dining")
+			{
+				dining.GET("/areas", diningHandler.GetAreas)
+				dining.POST("/areas", middleware.RoleMiddleware("admin", "manager"), diningHandler.CreateArea)
+				dining.PUT("/areas/:id", middleware.RoleMiddleware("admin", "manager"), diningHandler.UpdateArea)
+				dining.DELETE("/areas/:id", middleware.RoleMiddleware("admin", "manager"), diningHandler.DeleteArea)
+				dining.GET("/tables", diningHandler.GetTables)
+				dining.GET("/tables/:id", diningHandler.GetTableByID)
+				dining.POST("/tables", middleware.RoleMiddleware("admin", "manager"), diningHandler.CreateTable)
+				dining.PUT("/tables/:id", middleware.RoleMiddleware("admin", "manager"), diningHandler.UpdateTable)
+				dining.DELETE("/tables/:id", middleware.RoleMiddleware("admin", "manager"), diningHandler.DeleteTable)
+			}
+
+			// Tickets, open tabs sent to the kitchen in rounds and settled as one sale
+			tickets := protected.Group("/tickets")
+			tickets.Use(middleware.DeviceAuthMiddleware(deviceService, false))
+			{
+				tickets.GET("", ticketHandler.GetAll)
+				tickets.GET("/:id", ticketHandler.GetByID)
+				tickets.POST("", ticketHandler.Open)
+				tickets.PUT("/:id", ticketHandler.Update)
+				tickets.POST("/:id/rounds", ticketHandler.SendRound)
+				tickets.DELETE("/:id/items/:item_id", ticketHandler.VoidItem)
+				tickets.POST("/:id/settle", ticketHandler.Settle)
+				tickets.POST("/:id/void", middleware.RoleMiddleware("admin", "manager"), ticketHandler.Void)
+			}
+
+			// Kitchen display routes
+			kitchen := protected.Group("/kitchen")
+			kitchen.Use(middleware.DeviceAuthMiddleware(deviceService, false))
+			{
+				kitchen.GET("/queue", ticketHandler.KitchenQueue)
+				kitchen.PATCH("/items/:id/status", ticketHandler.UpdateKitchenStatus)
+			}
+
 			// Reports routes
 			reports := protected.Group("/reports")
 			{
 				reports.GET("/sales-summary", reportsHandler.GetSalesSummary)
 				reports.GET("/top-products", reportsHandler.GetTopProducts)
 				reports.GET("/sales-by-payment", reportsHandler.GetSalesByPaymentMethod)
+				reports.GET("/sales-by-category", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetSalesByCategory)
+				reports.GET("/sales-by-category/:category_id/products", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetCategoryProductSales)
 				reports.GET("/daily-sales", reportsHandler.GetDailySales)
+				reports.GET("/sales-heatmap", reportsHandler.GetSalesHeatmap)
+				reports.GET("/gross-margin", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetGrossMargin)
+				reports.GET("/bundle-sales", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetBundleSales)
+				reports.GET("/below-cost-sales", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetBelowCostSales)
+				reports.GET("/cashier-performance", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetCashierPerformance)
+				reports.GET("/inventory-analysis", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetInventoryAnalysis)
+				reports.GET("/tax-summary", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetTaxSummary)
+				reports.GET("/cash-rounding", middleware.RoleMiddleware("admin", "manager"), reportsHandler.GetCashRounding)
 			}
 
 			// Settings routes
 			settings := protected.Group("/settings")
 			{
 				settings.GET("", settingHandler.GetSettings)
-				settings.PUT("", middleware.RoleMiddleware("admin"), settingHandler.UpdateSettings)
+				settings.GET("/schema", settingHandler.GetSchema)
+				settings.GET("/resolved", settingHandler.GetResolvedSettings)
+				settings.PUT("/stores/:id", middleware.RoleMiddleware("admin", "manager"), settingHandler.UpdateStoreOverrides)
+				settings.PUT("/devices/:id", middleware.RoleMiddleware("admin", "manager"), settingHandler.UpdateDeviceOverrides)
+				settings.PUT("", middleware.RoleMiddleware("admin", "manager"), settingHandler.UpdateSettings) // Per-key roles are checked against the schema
 				settings.POST("/initialize", middleware.RoleMiddleware("admin"), settingHandler.InitializeDefaults)
+				settings.GET("/versions", middleware.RoleMiddleware("admin", "manager"), settingHandler.GetVersions)
+				settings.GET("/versions/:version", middleware.RoleMiddleware("admin", "manager"), settingHandler.GetVersion)
+				settings.GET("/versions/:version/diff", middleware.RoleMiddleware("admin", "manager"), settingHandler.DiffVersion)
+				settings.POST("/versions/:version/rollback", middleware.RoleMiddleware("admin"), settingHandler.Rollback)
 			}
 
 			// Dashboard routes
@@ -122,12 +248,46 @@ func SetupRouter(cfg *config.Config, authHandler *handler.AuthHandler, userHandl
 				dashboard.GET("/low-stock", dashboardHandler.GetLowStockProducts)
 			}
 
+			// Audit log routes
+			auditLogs := protected.Group("/audit-logs")
+			auditLogs.Use(middleware.RoleMiddleware("admin"))
+			{
+				auditLogs.GET("", auditHandler.GetAll)
+				auditLogs.GET("/verify", auditHandler.Verify)
+			}
+
+			// Device management routes
+			devices := protected.Group("/devices")
+			devices.Use(middleware.RoleMiddleware("admin"))
+			{
+				devices.GET("", deviceHandler.GetAll)
+				devices.GET("/:id", deviceHandler.GetByID)
+				devices.GET("/:id/signing-keys", deviceHandler.GetSigningKeys)
+				devices.POST("", deviceHandler.Register)
+				devices.POST("/:id/pairing-code", deviceHandler.RegeneratePairingCode)
+				devices.PATCH("/:id/revoke", deviceHandler.Revoke)
+				devices.PATCH("/:id/store", deviceHandler.AssignStore)
+			}
+
+			// Quarantined offline transactions
+			quarantine := protected.Group("/quarantine")
+			quarantine.Use(middleware.RoleMiddleware("admin", "manager"))
+			{
+				quarantine.GET("", quarantineHandler.GetAll)
+				quarantine.GET("/:id", quarantineHandler.GetByID)
+				quarantine.POST("/:id/approve", quarantineHandler.Approve)
+				quarantine.POST("/:id/reject", quarantineHandler.Reject)
+			}
+
 			// Inventory routes
-			// inventory := protected.Group("/inventory")
-			// {
-			// 	inventory.GET("/movements", inventoryHandler.GetMovements)
-			// 	inventory.POST("/adjustment", middleware.RoleMiddleware("admin", "manager"), inventoryHandler.Adjustment)
-			// }
+			inventory := protected.Group("/inventory")
+			{
+				inventory.GET("/movements", inventoryHandler.GetMovements)
+				inventory.GET("/valuation", middleware.RoleMiddleware("admin", "manager"), inventoryHandler.GetValuation)
+				inventory.GET("/reorder-suggestions", middleware.RoleMiddleware("admin", "manager"), inventoryHandler.GetReorderSuggestions)
+				inventory.POST("/receipts", middleware.RoleMiddleware("admin", "manager"), inventoryHandler.Receive)
+				inventory.POST("/adjustment", middleware.RoleMiddleware("admin", "manager"), inventoryHandler.Adjustment)
+			}
 		}
 	}
 
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditService interface {
+	RecordTx(tx *gorm.DB, actor domain.Actor, action, entityType, entityID string, before, after interface{}) error
+	GetAll(page, limit int, filters domain.AuditLogFilters) ([]*dto.AuditLogResponse, int64, error)
+	Verify() (*dto.AuditVerifyResponse, error)
+}
+
+type auditService struct {
+	auditRepo domain.AuditLogRepository
+}
+
+func NewAuditService(auditRepo domain.AuditLogRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// RecordTx appends an audit entry as part of tx so it commits or rolls back with the change
+func (s *auditService) RecordTx(tx *gorm.DB, actor domain.Actor, action, entityType, entityID string, before, after interface{}) error {
+	entry, err := s.buildEntry(actor, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	return s.auditRepo.WithTx(tx).Append(entry)
+}
+
+func (s *auditService) GetAll(page, limit int, filters domain.AuditLogFilters) ([]*dto.AuditLogResponse, int64, error) {
+	logs, totalData, err := s.auditRepo.FindAll(page, limit, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.AuditLogResponse
+	for _, log := range logs {
+		responses = append(responses, s.toAuditLogResponse(&log))
+	}
+
+	return responses, totalData, nil
+}
+
+// Verify walks the whole chain and reports the first entry that doesn't match its hash
+func (s *auditService) Verify() (*dto.AuditVerifyResponse, error) {
+	const batchSize = 500
+
+	result := &dto.AuditVerifyResponse{Valid: true}
+	var lastSequence int64
+	var prevHash string
+
+	for {
+		logs, err := s.auditRepo.FindAfterSequence(lastSequence, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, log := range logs {
+			result.CheckedEntries++
+			if log.PrevHash != prevHash || log.ComputeHash() != log.Hash {
+				result.Valid = false
+				result.BrokenSequence = log.Sequence
+				result.Message = fmt.Sprintf("Audit chain broken at sequence %d", log.Sequence)
+				return result, nil
+			}
+			prevHash = log.Hash
+			lastSequence = log.Sequence
+		}
+
+		if len(logs) < batchSize {
+			break
+		}
+	}
+
+	result.Message = "Audit chain is intact"
+	return result, nil
+}
+
+// Helper functions
+
+func (s *auditService) buildEntry(actor domain.Actor, action, entityType, entityID string, before, after interface{}) (*domain.AuditLog, error) {
+	beforeJSON, err := marshalAuditValue(before)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := marshalAuditValue(after)
+	if err != nil {
+		return nil, err
+	}
+	diffJSON, err := diffAuditValues(beforeJSON, afterJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.AuditLog{
+		ActorName:  actor.Username,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		Diff:       diffJSON,
+		IPAddress:  actor.IPAddress,
+		RequestID:  actor.RequestID,
+	}
+	if actor.UserID != uuid.Nil {
+		actorID := actor.UserID
+		entry.ActorID = &actorID
+	}
+
+	return entry, nil
+}
+
+func marshalAuditValue(value interface{}) (string, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return "", nil
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(valueBytes), nil
+}
+
+// diffAuditValues returns the top-level fields that changed as {"field": {"from": x, "to": y}}
+func diffAuditValues(beforeJSON, afterJSON string) (string, error) {
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	if beforeJSON != "" {
+		if err := json.Unmarshal([]byte(beforeJSON), &before); err != nil {
+			before = map[string]interface{}{"value": json.RawMessage(beforeJSON)}
+		}
+	}
+	if afterJSON != "" {
+		if err := json.Unmarshal([]byte(afterJSON), &after); err != nil {
+			after = map[string]interface{}{"value": json.RawMessage(afterJSON)}
+		}
+	}
+
+	diff := map[string]map[string]interface{}{}
+	for key, from := range before {
+		to, ok := after[key]
+		if !ok || !reflect.DeepEqual(from, to) {
+			diff[key] = map[string]interface{}{"from": from, "to": to}
+		}
+	}
+	for key, to := range after {
+		if _, ok := before[key]; !ok {
+			diff[key] = map[string]interface{}{"from": nil, "to": to}
+		}
+	}
+
+	if len(diff) == 0 {
+		return "", nil
+	}
+	diffBytes, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return string(diffBytes), nil
+}
+
+func (s *auditService) toAuditLogResponse(log *domain.AuditLog) *dto.AuditLogResponse {
+	response := &dto.AuditLogResponse{
+		ID:         log.ID.String(),
+		Sequence:   log.Sequence,
+		ActorName:  log.ActorName,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		IPAddress:  log.IPAddress,
+		RequestID:  log.RequestID,
+		PrevHash:   log.PrevHash,
+		Hash:       log.Hash,
+		CreatedAt:  log.CreatedAt.Format(time.RFC3339),
+	}
+
+	if log.ActorID != nil {
+		response.ActorID = log.ActorID.String()
+	}
+	if log.Before != "" {
+		response.Before = json.RawMessage(log.Before)
+	}
+	if log.After != "" {
+		response.After = json.RawMessage(log.After)
+	}
+	if log.Diff != "" {
+		response.Diff = json.RawMessage(log.Diff)
+	}
+
+	return response
+}
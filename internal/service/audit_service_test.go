@@ -0,0 +1,124 @@
+package service
+
+import (
+	"pos-backend/internal/domain"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// chainRepository serves a fixed audit chain to Verify
+type chainRepository struct {
+	domain.AuditLogRepository
+	logs []domain.AuditLog
+}
+
+func (r *chainRepository) FindAfterSequence(sequence int64, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	for _, log := range r.logs {
+		if log.Sequence > sequence && len(logs) < limit {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// buildAuditChain links n entries the way the repository appends them
+func buildAuditChain(n int) []domain.AuditLog {
+	logs := make([]domain.AuditLog, n)
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	prevHash := ""
+	for i := range logs {
+		logs[i] = domain.AuditLog{
+			Sequence:   int64(i + 1),
+			ActorName:  "admin",
+			Action:     "update",
+			EntityType: "product",
+			EntityID:   strconv.Itoa(i),
+			After:      `{"stock":` + strconv.Itoa(i) + `}`,
+			PrevHash:   prevHash,
+			CreatedAt:  start.Add(time.Duration(i) * time.Second),
+		}
+		logs[i].Hash = logs[i].ComputeHash()
+		prevHash = logs[i].Hash
+	}
+	return logs
+}
+
+func TestAuditVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		logs        func() []domain.AuditLog
+		wantValid   bool
+		wantChecked int64
+		wantBroken  int64
+	}{
+		{
+			name:      "empty chain",
+			logs:      func() []domain.AuditLog { return nil },
+			wantValid: true,
+		},
+		{
+			name:        "intact chain",
+			logs:        func() []domain.AuditLog { return buildAuditChain(3) },
+			wantValid:   true,
+			wantChecked: 3,
+		},
+		{
+			name:        "intact chain across batches",
+			logs:        func() []domain.AuditLog { return buildAuditChain(501) },
+			wantValid:   true,
+			wantChecked: 501,
+		},
+		{
+			name: "edited entry",
+			logs: func() []domain.AuditLog {
+				logs := buildAuditChain(3)
+				logs[1].After = `{"stock":100}`
+				return logs
+			},
+			wantChecked: 2,
+			wantBroken:  2,
+		},
+		{
+			name: "edited entry with its hash recomputed",
+			logs: func() []domain.AuditLog {
+				logs := buildAuditChain(3)
+				logs[1].After = `{"stock":100}`
+				logs[1].Hash = logs[1].ComputeHash()
+				return logs
+			},
+			wantChecked: 3,
+			wantBroken:  3,
+		},
+		{
+			name: "deleted entry",
+			logs: func() []domain.AuditLog {
+				logs := buildAuditChain(3)
+				return append(logs[:1], logs[2])
+			},
+			wantChecked: 2,
+			wantBroken:  3,
+		},
+		{
+			name:        "chain missing its start",
+			logs:        func() []domain.AuditLog { return buildAuditChain(3)[1:] },
+			wantChecked: 1,
+			wantBroken:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := NewAuditService(&chainRepository{logs: tt.logs()})
+			result, err := audit.Verify()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Valid != tt.wantValid || result.CheckedEntries != tt.wantChecked || result.BrokenSequence != tt.wantBroken {
+				t.Errorf("got valid %v checked %d broken at %d, want valid %v checked %d broken at %d",
+					result.Valid, result.CheckedEntries, result.BrokenSequence, tt.wantValid, tt.wantChecked, tt.wantBroken)
+			}
+		})
+	}
+}
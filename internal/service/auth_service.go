@@ -7,27 +7,32 @@ import (
 	"pos-backend/pkg/jwt"
 	"pos-backend/pkg/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Register(req *dto.RegisterRequest) (*dto.AuthResponse, error)
+	Register(req *dto.RegisterRequest, actor domain.Actor) (*dto.AuthResponse, error)
 	Login(req *dto.LoginRequest) (*dto.AuthResponse, error)
 }
 
 type authService struct {
-	userRepo  domain.UserRepository
-	jwtSecret string
+	userRepo     domain.UserRepository
+	auditService AuditService
+	jwtSecret    string
+	db           *gorm.DB
 }
 
-func NewAuthService(userRepo domain.UserRepository, jwtSecret string) AuthService {
+func NewAuthService(userRepo domain.UserRepository, auditService AuditService, jwtSecret string, db *gorm.DB) AuthService {
 	return &authService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:     userRepo,
+		auditService: auditService,
+		jwtSecret:    jwtSecret,
+		db:           db,
 	}
 }
 
-func (s *authService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
+func (s *authService) Register(req *dto.RegisterRequest, actor domain.Actor) (*dto.AuthResponse, error) {
 	// Check if username already exists
 	_, err := s.userRepo.FindByUsername(req.Username)
 	if err == nil {
@@ -68,7 +73,19 @@ func (s *authService) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 		IsActive: true,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).Create(user); err != nil {
+			return err
+		}
+
+		// Self-registration has no authenticated actor, so attribute it to the new user
+		if actor.UserID == uuid.Nil {
+			actor.UserID = user.ID
+			actor.Username = user.Username
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "user", user.ID.String(), nil, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
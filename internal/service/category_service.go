@@ -6,43 +6,56 @@ import (
 	"pos-backend/internal/dto"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CategoryService interface {
 	GetAll(page, limit int) ([]*dto.CategoryResponse, int64, error)
-	Create(req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error)
+	Create(req *dto.CreateCategoryRequest, actor domain.Actor) (*dto.CategoryResponse, error)
 	GetByID(id string) (*dto.CategoryResponse, error)
 	GetByName(name string) (*dto.CategoryResponse, error)
-	Update(id string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error)
-	Delete(id string) error
+	Update(id string, req *dto.UpdateCategoryRequest, actor domain.Actor) (*dto.CategoryResponse, error)
+	Delete(id string, actor domain.Actor) error
 }
 
 type categoryService struct {
 	categoryRepo domain.CategoryRepository
+	taxClassRepo domain.TaxClassRepository
+	auditService AuditService
+	db           *gorm.DB
 }
 
-func NewCategoryService(categoryRepo domain.CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepo domain.CategoryRepository, taxClassRepo domain.TaxClassRepository, auditService AuditService, db *gorm.DB) CategoryService {
 	return &categoryService{
 		categoryRepo: categoryRepo,
+		taxClassRepo: taxClassRepo,
+		auditService: auditService,
+		db:           db,
 	}
 }
 
-func (s *categoryService) Create(req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
+func (s *categoryService) Create(req *dto.CreateCategoryRequest, actor domain.Actor) (*dto.CategoryResponse, error) {
+	taxClassID, err := parseTaxClassID(s.taxClassRepo, req.TaxClassID)
+	if err != nil {
+		return nil, err
+	}
+
 	category := domain.Category{
 		Name:        req.Name,
 		Description: req.Description,
+		TaxClassID:  taxClassID,
 	}
 
-	err := s.categoryRepo.Create(&category)
-	if err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.WithTx(tx).Create(&category); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "category", category.ID.String(), nil, category)
+	}); err != nil {
 		return nil, err
 	}
 
-	return &dto.CategoryResponse{
-		ID:          category.ID.String(),
-		Name:        category.Name,
-		Description: category.Description,
-	}, nil
+	return toCategoryResponse(&category), nil
 }
 
 func (s *categoryService) GetByID(id string) (*dto.CategoryResponse, error) {
@@ -56,11 +69,7 @@ func (s *categoryService) GetByID(id string) (*dto.CategoryResponse, error) {
 		return nil, err
 	}
 
-	return &dto.CategoryResponse{
-		ID:          category.ID.String(),
-		Name:        category.Name,
-		Description: category.Description,
-	}, nil
+	return toCategoryResponse(category), nil
 }
 
 func (s *categoryService) GetByName(name string) (*dto.CategoryResponse, error) {
@@ -69,15 +78,10 @@ func (s *categoryService) GetByName(name string) (*dto.CategoryResponse, error)
 		return nil, err
 	}
 
-	return &dto.CategoryResponse{
-		ID:          category.ID.String(),
-		Name:        category.Name,
-		Description: category.Description,
-		CreatedAt:   category.CreatedAt,
-	}, nil
+	return toCategoryResponse(category), nil
 }
 
-func (s *categoryService) Update(id string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
+func (s *categoryService) Update(id string, req *dto.UpdateCategoryRequest, actor domain.Actor) (*dto.CategoryResponse, error) {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, errors.New("invalid user ID format")
@@ -86,29 +90,54 @@ func (s *categoryService) Update(id string, req *dto.UpdateCategoryRequest) (*dt
 	if err != nil {
 		return nil, err
 	}
+	before := *category
 
 	category.Name = req.Name
 	category.Description = req.Description
 
-	err = s.categoryRepo.Update(category)
-	if err != nil {
+	// A class that was deactivated may stay on the categories already using it
+	if req.TaxClassID == "" {
+		category.TaxClassID = nil
+	} else if before.TaxClassID == nil || before.TaxClassID.String() != req.TaxClassID {
+		category.TaxClassID, err = parseTaxClassID(s.taxClassRepo, req.TaxClassID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.WithTx(tx).Update(category); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "category", category.ID.String(), before, category)
+	}); err != nil {
 		return nil, err
 	}
 
-	return &dto.CategoryResponse{
-		ID:          category.ID.String(),
-		Name:        category.Name,
-		Description: category.Description,
-		CreatedAt:   category.CreatedAt,
-	}, nil
+	return toCategoryResponse(category), nil
 }
 
-func (s *categoryService) Delete(id string) error {
+func (s *categoryService) Delete(id string, actor domain.Actor) error {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid user ID format")
 	}
-	return s.categoryRepo.Delete(userID)
+
+	category, err := s.categoryRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.categoryRepo.WithTx(tx).Delete(userID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "category", category.ID.String(), category, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (s *categoryService) GetAll(page, limit int) ([]*dto.CategoryResponse, int64, error) {
@@ -119,13 +148,23 @@ func (s *categoryService) GetAll(page, limit int) ([]*dto.CategoryResponse, int6
 
 	var responses []*dto.CategoryResponse
 	for _, category := range categories {
-		responses = append(responses, &dto.CategoryResponse{
-			ID:          category.ID.String(),
-			Name:        category.Name,
-			Description: category.Description,
-			CreatedAt:   category.CreatedAt,
-		})
+		responses = append(responses, toCategoryResponse(&category))
 	}
 
 	return responses, totalData, nil
 }
+
+// Helper functions
+
+func toCategoryResponse(category *domain.Category) *dto.CategoryResponse {
+	response := &dto.CategoryResponse{
+		ID:          category.ID.String(),
+		Name:        category.Name,
+		Description: category.Description,
+		CreatedAt:   category.CreatedAt,
+	}
+	if category.TaxClassID != nil {
+		response.TaxClassID = category.TaxClassID.String()
+	}
+	return response
+}
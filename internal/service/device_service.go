@@ -0,0 +1,500 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	pairingCodeLength   = 8
+	pairingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I to avoid typos
+	pairingCodeTTL      = 24 * time.Hour
+	deviceSeenThrottle  = time.Minute
+)
+
+type DeviceService interface {
+	Register(req *dto.CreateDeviceRequest, actor domain.Actor) (*dto.DeviceResponse, error)
+	RegeneratePairingCode(id string, actor domain.Actor) (*dto.DeviceResponse, error)
+	Pair(req *dto.PairDeviceRequest, ipAddress string) (*dto.DeviceCredentialsResponse, error)
+	Authenticate(token string) (*domain.Device, error)
+	Revoke(id string, actor domain.Actor) (*dto.DeviceResponse, error)
+	GetByID(id string) (*dto.DeviceResponse, error)
+	GetAll(page, limit int) ([]*dto.DeviceResponse, int64, error)
+	GetConfig(deviceID uuid.UUID) (*dto.DeviceConfigResponse, error)
+	RegisterSigningKey(deviceID uuid.UUID, req *dto.RegisterSigningKeyRequest, ipAddress string) (*dto.SigningKeyResponse, error)
+	GetSigningKeys(id string) ([]*dto.SigningKeyResponse, error)
+	AssignStore(id string, req *dto.AssignDeviceStoreRequest, actor domain.Actor) (*dto.DeviceResponse, error)
+}
+
+type deviceService struct {
+	deviceRepo     domain.DeviceRepository
+	signingKeyRepo domain.DeviceSigningKeyRepository
+	storeRepo      domain.StoreRepository
+	settingService *SettingService
+	auditService   AuditService
+	db             *gorm.DB
+}
+
+func NewDeviceService(deviceRepo domain.DeviceRepository, signingKeyRepo domain.DeviceSigningKeyRepository, storeRepo domain.StoreRepository, settingService *SettingService, auditService AuditService, db *gorm.DB) DeviceService {
+	return &deviceService{
+		deviceRepo:     deviceRepo,
+		signingKeyRepo: signingKeyRepo,
+		storeRepo:      storeRepo,
+		settingService: settingService,
+		auditService:   auditService,
+		db:             db,
+	}
+}
+
+func (s *deviceService) Register(req *dto.CreateDeviceRequest, actor domain.Actor) (*dto.DeviceResponse, error) {
+	code, err := generatePairingCode()
+	if err != nil {
+		return nil, err
+	}
+
+	storeID, err := s.parseStoreID(req.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(pairingCodeTTL)
+	device := domain.Device{
+		Name:                 req.Name,
+		Location:             req.Location,
+		StoreID:              storeID,
+		Status:               "pending",
+		PairingCodeHash:      hashSecret(code),
+		PairingCodeExpiresAt: &expiresAt,
+	}
+	if actor.UserID != uuid.Nil {
+		device.CreatedBy = &actor.UserID
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.deviceRepo.WithTx(tx).Create(&device); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "device", device.ID.String(), nil, device)
+	}); err != nil {
+		return nil, err
+	}
+
+	response := s.toDeviceResponse(&device)
+	response.PairingCode = code
+	return response, nil
+}
+
+// RegeneratePairingCode issues a new code and invalidates current credentials so the device must pair again
+func (s *deviceService) RegeneratePairingCode(id string, actor domain.Actor) (*dto.DeviceResponse, error) {
+	device, err := s.findDevice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.Status == "revoked" {
+		return nil, errors.New("device has been revoked")
+	}
+
+	code, err := generatePairingCode()
+	if err != nil {
+		return nil, err
+	}
+
+	before := *device
+	expiresAt := time.Now().Add(pairingCodeTTL)
+	device.Status = "pending"
+	device.PairingCodeHash = hashSecret(code)
+	device.PairingCodeExpiresAt = &expiresAt
+	device.CredentialHash = ""
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.deviceRepo.WithTx(tx).Update(device); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "regenerate_pairing_code", "device", device.ID.String(), before, device)
+	}); err != nil {
+		return nil, err
+	}
+
+	response := s.toDeviceResponse(device)
+	response.PairingCode = code
+	return response, nil
+}
+
+// Pair exchanges a one-time pairing code for long-lived device credentials
+func (s *deviceService) Pair(req *dto.PairDeviceRequest, ipAddress string) (*dto.DeviceCredentialsResponse, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.PairingCode))
+
+	device, err := s.deviceRepo.FindByPairingCodeHash(hashSecret(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid pairing code")
+		}
+		return nil, err
+	}
+
+	if device.Status != "pending" {
+		return nil, errors.New("invalid pairing code")
+	}
+	if device.PairingCodeExpiresAt == nil || time.Now().After(*device.PairingCodeExpiresAt) {
+		return nil, errors.New("pairing code has expired")
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device.Status = "active"
+	device.CredentialHash = hashSecret(secret)
+	device.PairingCodeHash = ""
+	device.PairingCodeExpiresAt = nil
+	device.PairedAt = &now
+	device.LastSeenAt = &now
+
+	actor := domain.Actor{Username: "device:" + device.Name, IPAddress: ipAddress}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.deviceRepo.WithTx(tx).Update(device); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "pair", "device", device.ID.String(), nil, map[string]interface{}{"status": device.Status})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DeviceCredentialsResponse{
+		DeviceID:    device.ID.String(),
+		DeviceToken: device.ID.String() + "." + secret,
+	}, nil
+}
+
+// Authenticate validates a "<device_id>.<secret>" token and returns the active device
+func (s *deviceService) Authenticate(token string) (*domain.Device, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid device token")
+	}
+
+	deviceID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return nil, errors.New("invalid device token")
+	}
+
+	device, err := s.deviceRepo.FindByID(deviceID)
+	if err != nil {
+		return nil, errors.New("invalid device token")
+	}
+
+	if device.Status != "active" || device.CredentialHash == "" {
+		return nil, errors.New("device is not active")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[1])), []byte(device.CredentialHash)) != 1 {
+		return nil, errors.New("invalid device token")
+	}
+
+	// Avoid a write on every request from a busy register
+	now := time.Now()
+	if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) > deviceSeenThrottle {
+		if err := s.deviceRepo.UpdateLastSeen(device.ID, now); err != nil {
+			log.Printf("Warning: failed to update device last seen: %v", err)
+		}
+		device.LastSeenAt = &now
+	}
+
+	return device, nil
+}
+
+func (s *deviceService) Revoke(id string, actor domain.Actor) (*dto.DeviceResponse, error) {
+	device, err := s.findDevice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.Status == "revoked" {
+		return nil, errors.New("device already revoked")
+	}
+
+	before := *device
+	now := time.Now()
+	device.Status = "revoked"
+	device.CredentialHash = ""
+	device.PairingCodeHash = ""
+	device.PairingCodeExpiresAt = nil
+	device.RevokedAt = &now
+	if actor.UserID != uuid.Nil {
+		device.RevokedBy = &actor.UserID
+	}
+
+	// Sales signed with a lost device's key must not be accepted anymore
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.deviceRepo.WithTx(tx).Update(device); err != nil {
+			return err
+		}
+		if err := s.signingKeyRepo.WithTx(tx).RetireActive(device.ID, now); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "revoke", "device", device.ID.String(), before, device)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.toDeviceResponse(device), nil
+}
+
+func (s *deviceService) GetByID(id string) (*dto.DeviceResponse, error) {
+	device, err := s.findDevice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDeviceResponse(device), nil
+}
+
+func (s *deviceService) GetAll(page, limit int) ([]*dto.DeviceResponse, int64, error) {
+	devices, totalData, err := s.deviceRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.DeviceResponse
+	for _, device := range devices {
+		responses = append(responses, s.toDeviceResponse(&device))
+	}
+
+	return responses, totalData, nil
+}
+
+// GetConfig returns the settings a register needs to run offline
+func (s *deviceService) GetConfig(deviceID uuid.UUID) (*dto.DeviceConfigResponse, error) {
+	device, err := s.deviceRepo.FindByID(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registers get their store's and their own overrides on top of the global settings
+	settings, err := s.settingService.GetSettings(SettingScope{StoreID: device.StoreID, DeviceID: &device.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	config := &dto.DeviceConfigResponse{
+		DeviceID:   device.ID.String(),
+		DeviceName: device.Name,
+		Store:      settings["store"],
+		Receipt:    settings["receipt"],
+	}
+	if system := settings["system"]; system != nil {
+		config.SyncInterval = system["sync_interval"]
+	}
+
+	return config, nil
+}
+
+// RegisterSigningKey stores a new Ed25519 public key for the device and retires the previous one
+func (s *deviceService) RegisterSigningKey(deviceID uuid.UUID, req *dto.RegisterSigningKeyRequest, ipAddress string) (*dto.SigningKeyResponse, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("public key must be a base64 encoded Ed25519 key")
+	}
+
+	device, err := s.deviceRepo.FindByID(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := domain.DeviceSigningKey{
+		DeviceID:  deviceID,
+		Algorithm: "ed25519",
+		PublicKey: base64.StdEncoding.EncodeToString(publicKey),
+		Status:    "active",
+	}
+	actor := domain.Actor{Username: "device:" + device.Name, DeviceID: &device.ID, IPAddress: ipAddress}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		signingKeyRepo := s.signingKeyRepo.WithTx(tx)
+		if err := signingKeyRepo.RetireActive(deviceID, time.Now()); err != nil {
+			return err
+		}
+		if err := signingKeyRepo.Create(&key); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "register_signing_key", "device", device.ID.String(), nil, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toSigningKeyResponse(&key), nil
+}
+
+func (s *deviceService) GetSigningKeys(id string) ([]*dto.SigningKeyResponse, error) {
+	device, err := s.findDevice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.signingKeyRepo.FindByDevice(device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*dto.SigningKeyResponse
+	for _, key := range keys {
+		responses = append(responses, s.toSigningKeyResponse(&key))
+	}
+
+	return responses, nil
+}
+
+// AssignStore moves a device to a store; later sales from it are booked to that store
+func (s *deviceService) AssignStore(id string, req *dto.AssignDeviceStoreRequest, actor domain.Actor) (*dto.DeviceResponse, error) {
+	device, err := s.findDevice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	storeID, err := s.parseStoreID(req.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *device
+	device.StoreID = storeID
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.deviceRepo.WithTx(tx).Update(device); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "assign_store", "device", device.ID.String(), before, device)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.toDeviceResponse(device), nil
+}
+
+// Helper functions
+
+// parseStoreID resolves an optional store reference, empty means no store
+func (s *deviceService) parseStoreID(id string) (*uuid.UUID, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	storeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid store ID format")
+	}
+
+	store, err := s.storeRepo.FindByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+	if !store.IsActive {
+		return nil, errors.New("store is not active")
+	}
+
+	return &store.ID, nil
+}
+
+func (s *deviceService) findDevice(id string) (*domain.Device, error) {
+	deviceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid device ID format")
+	}
+
+	device, err := s.deviceRepo.FindByID(deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("device not found")
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func generatePairingCode() (string, error) {
+	code := make([]byte, pairingCodeLength)
+	alphabetSize := big.NewInt(int64(len(pairingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatOptionalUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func (s *deviceService) toSigningKeyResponse(key *domain.DeviceSigningKey) *dto.SigningKeyResponse {
+	return &dto.SigningKeyResponse{
+		ID:        key.ID.String(),
+		DeviceID:  key.DeviceID.String(),
+		Algorithm: key.Algorithm,
+		PublicKey: key.PublicKey,
+		Status:    key.Status,
+		RetiredAt: formatOptionalTime(key.RetiredAt),
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *deviceService) toDeviceResponse(device *domain.Device) *dto.DeviceResponse {
+	return &dto.DeviceResponse{
+		ID:                   device.ID.String(),
+		Name:                 device.Name,
+		Location:             device.Location,
+		StoreID:              formatOptionalUUID(device.StoreID),
+		Status:               device.Status,
+		PairingCodeExpiresAt: formatOptionalTime(device.PairingCodeExpiresAt),
+		PairedAt:             formatOptionalTime(device.PairedAt),
+		LastSeenAt:           formatOptionalTime(device.LastSeenAt),
+		LastSyncAt:           formatOptionalTime(device.LastSyncAt),
+		LastSyncStatus:       device.LastSyncStatus,
+		LastSyncCount:        device.LastSyncCount,
+		RevokedAt:            formatOptionalTime(device.RevokedAt),
+		CreatedAt:            device.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DiningService manages the floor plan: areas of a store and their tables
+type DiningService interface {
+	GetAreas(storeID *uuid.UUID) ([]*dto.DiningAreaResponse, error)
+	CreateArea(req *dto.CreateDiningAreaRequest, actor domain.Actor) (*dto.DiningAreaResponse, error)
+	UpdateArea(id string, req *dto.UpdateDiningAreaRequest, actor domain.Actor) (*dto.DiningAreaResponse, error)
+	DeleteArea(id string, actor domain.Actor) error
+	// GetTables lists tables with whether each has an open ticket
+	GetTables(storeID, areaID *uuid.UUID) ([]*dto.DiningTableResponse, error)
+	GetTableByID(id string) (*dto.DiningTableResponse, error)
+	CreateTable(req *dto.CreateDiningTableRequest, actor domain.Actor) (*dto.DiningTableResponse, error)
+	UpdateTable(id string, req *dto.UpdateDiningTableRequest, actor domain.Actor) (*dto.DiningTableResponse, error)
+	DeleteTable(id string, actor domain.Actor) error
+}
+
+type diningService struct {
+	diningRepo   domain.DiningRepository
+	storeRepo    domain.StoreRepository
+	ticketRepo   domain.TicketRepository
+	auditService AuditService
+	db           *gorm.DB
+}
+
+func NewDiningService(diningRepo domain.DiningRepository, storeRepo domain.StoreRepository, ticketRepo domain.TicketRepository, auditService AuditService, db *gorm.DB) DiningService {
+	return &diningService{
+		diningRepo:   diningRepo,
+		storeRepo:    storeRepo,
+		ticketRepo:   ticketRepo,
+		auditService: auditService,
+		db:           db,
+	}
+}
+
+func (s *diningService) GetAreas(storeID *uuid.UUID) ([]*dto.DiningAreaResponse, error) {
+	areas, err := s.diningRepo.FindAreas(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*dto.DiningAreaResponse{}
+	for i := range areas {
+		responses = append(responses, toDiningAreaResponse(&areas[i]))
+	}
+
+	return responses, nil
+}
+
+func (s *diningService) CreateArea(req *dto.CreateDiningAreaRequest, actor domain.Actor) (*dto.DiningAreaResponse, error) {
+	storeID, err := s.parseStoreID(req.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	area := domain.DiningArea{
+		StoreID:   storeID,
+		Name:      req.Name,
+		SortOrder: req.SortOrder,
+		IsActive:  true,
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).CreateArea(&area); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "dining_area", area.ID.String(), nil, area)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toDiningAreaResponse(&area), nil
+}
+
+func (s *diningService) UpdateArea(id string, req *dto.UpdateDiningAreaRequest, actor domain.Actor) (*dto.DiningAreaResponse, error) {
+	area, err := s.findArea(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *area
+
+	storeID, err := s.parseStoreID(req.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	area.StoreID = storeID
+	area.Name = req.Name
+	area.SortOrder = req.SortOrder
+	area.IsActive = req.IsActive
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).UpdateArea(area); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "dining_area", area.ID.String(), before, area)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toDiningAreaResponse(area), nil
+}
+
+func (s *diningService) DeleteArea(id string, actor domain.Actor) error {
+	area, err := s.findArea(id)
+	if err != nil {
+		return err
+	}
+
+	tables, err := s.diningRepo.CountTables(area.ID)
+	if err != nil {
+		return err
+	}
+	if tables > 0 {
+		return errors.New("dining area still has tables")
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).DeleteArea(area.ID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "dining_area", area.ID.String(), area, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *diningService) GetTables(storeID, areaID *uuid.UUID) ([]*dto.DiningTableResponse, error) {
+	tables, err := s.diningRepo.FindTables(storeID, areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	openTickets, err := s.ticketRepo.OpenTicketsByTable()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*dto.DiningTableResponse{}
+	for i := range tables {
+		response := toDiningTableResponse(&tables[i])
+		if ticketID, ok := openTickets[tables[i].ID]; ok {
+			response.Occupied = true
+			response.TicketID = ticketID.String()
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *diningService) GetTableByID(id string) (*dto.DiningTableResponse, error) {
+	table, err := s.findTable(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toDiningTableResponse(table)
+	if ticket, err := s.ticketRepo.FindOpenByTable(table.ID); err == nil {
+		response.Occupied = true
+		response.TicketID = ticket.ID.String()
+	}
+
+	return response, nil
+}
+
+func (s *diningService) CreateTable(req *dto.CreateDiningTableRequest, actor domain.Actor) (*dto.DiningTableResponse, error) {
+	area, err := s.findArea(req.AreaID)
+	if err != nil {
+		return nil, err
+	}
+
+	table := domain.DiningTable{
+		AreaID:   area.ID,
+		Name:     req.Name,
+		Seats:    req.Seats,
+		IsActive: true,
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).CreateTable(&table); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "dining_table", table.ID.String(), nil, table)
+	}); err != nil {
+		return nil, err
+	}
+
+	table.Area = area
+	return toDiningTableResponse(&table), nil
+}
+
+func (s *diningService) UpdateTable(id string, req *dto.UpdateDiningTableRequest, actor domain.Actor) (*dto.DiningTableResponse, error) {
+	table, err := s.findTable(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *table
+	before.Area = nil
+
+	area, err := s.findArea(req.AreaID)
+	if err != nil {
+		return nil, err
+	}
+
+	table.AreaID = area.ID
+	table.Area = nil
+	table.Name = req.Name
+	table.Seats = req.Seats
+	table.IsActive = req.IsActive
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).UpdateTable(table); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "dining_table", table.ID.String(), before, table)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetTableByID(table.ID.String())
+}
+
+func (s *diningService) DeleteTable(id string, actor domain.Actor) error {
+	table, err := s.findTable(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.ticketRepo.FindOpenByTable(table.ID); err == nil {
+		return errors.New("dining table has an open ticket")
+	}
+
+	table.Area = nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.diningRepo.WithTx(tx).DeleteTable(table.ID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "dining_table", table.ID.String(), table, nil)
+	})
+}
+
+// Helper functions
+
+func (s *diningService) findArea(id string) (*domain.DiningArea, error) {
+	areaID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid dining area ID format")
+	}
+
+	area, err := s.diningRepo.FindAreaByID(areaID)
+	if err != nil {
+		return nil, errors.New("dining area not found")
+	}
+
+	return area, nil
+}
+
+func (s *diningService) findTable(id string) (*domain.DiningTable, error) {
+	tableID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid dining table ID format")
+	}
+
+	table, err := s.diningRepo.FindTableByID(tableID)
+	if err != nil {
+		return nil, errors.New("dining table not found")
+	}
+
+	return table, nil
+}
+
+// parseStoreID checks an optional store reference, empty means no store
+func (s *diningService) parseStoreID(id string) (*uuid.UUID, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	storeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %s", id)
+	}
+	if _, err := s.storeRepo.FindByID(storeID); err != nil {
+		return nil, errors.New("store not found")
+	}
+
+	return &storeID, nil
+}
+
+func toDiningAreaResponse(area *domain.DiningArea) *dto.DiningAreaResponse {
+	return &dto.DiningAreaResponse{
+		ID:        area.ID.String(),
+		StoreID:   formatOptionalUUID(area.StoreID),
+		Name:      area.Name,
+		SortOrder: area.SortOrder,
+		IsActive:  area.IsActive,
+		CreatedAt: area.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toDiningTableResponse(table *domain.DiningTable) *dto.DiningTableResponse {
+	response := &dto.DiningTableResponse{
+		ID:        table.ID.String(),
+		AreaID:    table.AreaID.String(),
+		Name:      table.Name,
+		Seats:     table.Seats,
+		IsActive:  table.IsActive,
+		CreatedAt: table.CreatedAt.Format(time.RFC3339),
+	}
+
+	if table.Area != nil {
+		response.AreaName = table.Area.Name
+		response.StoreID = formatOptionalUUID(table.Area.StoreID)
+	}
+
+	return response
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types pushed to live dashboards
+const (
+	EventTransactionCreated   = "transaction.created"
+	EventTransactionCancelled = "transaction.cancelled"
+	EventStockIssue           = "transaction.stock_issue"
+	EventLowStock             = "stock.low"
+)
+
+// Event is something that happened which live clients may want to see. StoreID
+// and UserID scope who receives it; nil means it isn't tied to a store or user.
+type Event struct {
+	ID         uint64      `json:"id"`
+	Type       string      `json:"type"`
+	StoreID    *uuid.UUID  `json:"store_id,omitempty"`
+	UserID     *uuid.UUID  `json:"user_id,omitempty"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// EventBus fans events out to in-process subscribers. Publishing never blocks:
+// a subscriber that falls behind its buffer misses events rather than holding
+// up a sale.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	nextID      atomic.Uint64
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events its filter accepts until it is closed
+type Subscription struct {
+	bus     *EventBus
+	filter  func(event Event) bool
+	events  chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Publish stamps the event with an ID and time and hands it to every matching subscriber
+func (b *EventBus) Publish(event Event) {
+	event.ID = b.nextID.Add(1)
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe registers a subscriber with room for buffer undelivered events
+func (b *EventBus) Subscribe(filter func(event Event) bool, buffer int) *Subscription {
+	sub := &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan Event, buffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Events returns the channel events are delivered on; it is closed by Close
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns how many events were skipped because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes; it is safe to call more than once
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subscribers, s)
+		s.bus.mu.Unlock()
+		close(s.events)
+	})
+}
+
+// EventBusStockAlertNotifier publishes low-stock alerts on the event bus
+type EventBusStockAlertNotifier struct {
+	bus *EventBus
+}
+
+func NewEventBusStockAlertNotifier(bus *EventBus) *EventBusStockAlertNotifier {
+	return &EventBusStockAlertNotifier{bus: bus}
+}
+
+func (n *EventBusStockAlertNotifier) NotifyLowStock(alert LowStockAlert) error {
+	n.bus.Publish(Event{
+		Type:       EventLowStock,
+		Data:       alert,
+		OccurredAt: alert.OccurredAt,
+	})
+	return nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultHoldReservationMinutes is used when the hold_reservation_minutes setting is missing
+const defaultHoldReservationMinutes = 30
+
+// HeldOrderService parks carts so a sale can be paused and resumed later, on
+// any device. Holding doesn't touch stock; a held order is sold through
+// TransactionService.Create like any other cart.
+type HeldOrderService interface {
+	Hold(req *dto.CreateHeldOrderRequest, actor domain.Actor) (*dto.HeldOrderResponse, error)
+	GetAll(page, limit int, filters domain.HeldOrderFilters) ([]*dto.HeldOrderResponse, int64, error)
+	GetByID(id string) (*dto.HeldOrderResponse, error)
+	Update(id string, req *dto.UpdateHeldOrderRequest, actor domain.Actor) (*dto.HeldOrderResponse, error)
+	// Resume records which device picked the order up and returns its cart
+	Resume(id string, actor domain.Actor) (*dto.HeldOrderResponse, error)
+	Complete(id string, req *dto.CompleteHeldOrderRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error)
+	Cancel(id string, actor domain.Actor) (*dto.HeldOrderResponse, error)
+}
+
+type heldOrderService struct {
+	heldOrderRepo      domain.HeldOrderRepository
+	modifierRepo       domain.ModifierRepository
+	productRepo        domain.ProductRepository
+	recipeRepo         domain.RecipeRepository
+	deviceRepo         domain.DeviceRepository
+	transactionService TransactionService
+	settingService     *SettingService
+	auditService       AuditService
+	db                 *gorm.DB
+}
+
+func NewHeldOrderService(
+	heldOrderRepo domain.HeldOrderRepository,
+	modifierRepo domain.ModifierRepository,
+	productRepo domain.ProductRepository,
+	recipeRepo domain.RecipeRepository,
+	deviceRepo domain.DeviceRepository,
+	transactionService TransactionService,
+	settingService *SettingService,
+	auditService AuditService,
+	db *gorm.DB,
+) HeldOrderService {
+	return &heldOrderService{
+		heldOrderRepo:      heldOrderRepo,
+		modifierRepo:       modifierRepo,
+		productRepo:        productRepo,
+		recipeRepo:         recipeRepo,
+		deviceRepo:         deviceRepo,
+		transactionService: transactionService,
+		settingService:     settingService,
+		auditService:       auditService,
+		db:                 db,
+	}
+}
+
+func (s *heldOrderService) Hold(req *dto.CreateHeldOrderRequest, actor domain.Actor) (*dto.HeldOrderResponse, error) {
+	storeID, err := s.resolveStoreID(req.StoreID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := actor.UserID
+	order := domain.HeldOrder{
+		Label:          req.Label,
+		Status:         domain.HeldOrderStatusHeld,
+		StoreID:        storeID,
+		UserID:         &userID,
+		DeviceID:       actor.DeviceID,
+		CustomerName:   req.CustomerName,
+		Notes:          req.Notes,
+		DiscountAmount: req.DiscountAmount,
+		ReserveStock:   req.ReserveStock,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.fillCart(tx, &order, req.Items); err != nil {
+			return err
+		}
+		if err := s.heldOrderRepo.CreateTx(tx, &order); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "held_order", order.ID.String(), nil, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(order.ID.String())
+}
+
+func (s *heldOrderService) GetAll(page, limit int, filters domain.HeldOrderFilters) ([]*dto.HeldOrderResponse, int64, error) {
+	orders, total, err := s.heldOrderRepo.FindAll(page, limit, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.HeldOrderResponse
+	for i := range orders {
+		responses = append(responses, toHeldOrderResponse(&orders[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *heldOrderService) GetByID(id string) (*dto.HeldOrderResponse, error) {
+	order, err := s.findOrder(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return toHeldOrderResponse(order), nil
+}
+
+func (s *heldOrderService) Update(id string, req *dto.UpdateHeldOrderRequest, actor domain.Actor) (*dto.HeldOrderResponse, error) {
+	order, err := s.findOrder(id, true)
+	if err != nil {
+		return nil, err
+	}
+	before := *order
+
+	order.Label = req.Label
+	order.CustomerName = req.CustomerName
+	order.Notes = req.Notes
+	order.DiscountAmount = req.DiscountAmount
+	order.ReserveStock = req.ReserveStock
+	order.User = nil
+	before.User = nil
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.fillCart(tx, order, req.Items); err != nil {
+			return err
+		}
+		if err := s.heldOrderRepo.ReplaceTx(tx, order); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "held_order", order.ID.String(), before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(order.ID.String())
+}
+
+func (s *heldOrderService) Resume(id string, actor domain.Actor) (*dto.HeldOrderResponse, error) {
+	order, err := s.findOrder(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order.ResumedBy = actor.DeviceID
+	order.ResumedAt = &now
+	if err := s.heldOrderRepo.Update(order); err != nil {
+		return nil, err
+	}
+
+	return toHeldOrderResponse(order), nil
+}
+
+func (s *heldOrderService) Complete(id string, req *dto.CompleteHeldOrderRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error) {
+	order, err := s.findOrder(id, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientTransactionID := req.ClientTransactionID
+	if clientTransactionID == "" {
+		clientTransactionID = "held-" + order.ID.String()
+	}
+
+	// A retry after the sale went through gets the same transaction back
+	if order.Status != domain.HeldOrderStatusHeld && order.TransactionID == nil {
+		return nil, nil, fmt.Errorf("held order is %s", order.Status)
+	}
+
+	txReq := dto.CreateTransactionRequest{
+		ClientTransactionID: clientTransactionID,
+		PaymentMethod:       req.PaymentMethod,
+		CustomerName:        order.CustomerName,
+		DiscountAmount:      order.DiscountAmount,
+		Notes:               order.Notes,
+		HeldOrderID:         &order.ID,
+		HeldOrderUpdatedAt:  order.UpdatedAt,
+	}
+	if order.StoreID != nil {
+		txReq.StoreID = order.StoreID.String()
+	}
+	for _, item := range order.Items {
+		txReq.Items = append(txReq.Items, dto.TransactionItemRequest{
+			ProductID:         item.ProductID.String(),
+			Quantity:          item.Quantity,
+			Price:             item.Price,
+			ModifierOptionIDs: item.Modifiers.OptionIDs(),
+		})
+	}
+
+	return s.transactionService.Create(&txReq, actor)
+}
+
+func (s *heldOrderService) Cancel(id string, actor domain.Actor) (*dto.HeldOrderResponse, error) {
+	order, err := s.findOrder(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = domain.HeldOrderStatusCancelled
+	order.ReservedUntil = nil
+	after := map[string]interface{}{"status": order.Status}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.heldOrderRepo.WithTx(tx).Update(order); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "cancel", "held_order", order.ID.String(), map[string]interface{}{"status": domain.HeldOrderStatusHeld}, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toHeldOrderResponse(order), nil
+}
+
+// Helper functions
+
+func (s *heldOrderService) findOrder(id string, mustBeHeld bool) (*domain.HeldOrder, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid held order ID format")
+	}
+
+	order, err := s.heldOrderRepo.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("held order not found")
+		}
+		return nil, err
+	}
+
+	if mustBeHeld && order.Status != domain.HeldOrderStatusHeld {
+		return nil, fmt.Errorf("held order is %s", order.Status)
+	}
+
+	return order, nil
+}
+
+// fillCart checks the requested items and sets them on the order. When the
+// order reserves stock, it locks the products and only reserves what other
+// held orders haven't already, starting a new reservation period.
+func (s *heldOrderService) fillCart(tx *gorm.DB, order *domain.HeldOrder, items []dto.TransactionItemRequest) error {
+	if len(items) == 0 {
+		return errors.New("a held order needs at least one item")
+	}
+	if order.DiscountAmount.IsNegative() {
+		return errors.New("discount amount cannot be negative")
+	}
+
+	var exclude *uuid.UUID
+	if order.ID != uuid.Nil {
+		exclude = &order.ID
+	}
+
+	order.Items = nil
+	order.Reservations = nil
+	requested := make(map[uuid.UUID]int)
+	var reservedProducts []uuid.UUID
+	for _, itemReq := range items {
+		productID, err := uuid.Parse(itemReq.ProductID)
+		if err != nil {
+			return fmt.Errorf("invalid product ID: %s", itemReq.ProductID)
+		}
+		if itemReq.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for product: %s", itemReq.ProductID)
+		}
+		if itemReq.Price.IsNegative() {
+			return fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
+		}
+
+		var product domain.Product
+		if err := tx.First(&product, productID).Error; err != nil {
+			return fmt.Errorf("product not found: %s", itemReq.ProductID)
+		}
+
+		groups, err := s.modifierRepo.FindByProductTx(tx, productID)
+		if err != nil {
+			return err
+		}
+		modifiers, err := selectModifiers(&product, groups, itemReq.ModifierOptionIDs)
+		if err != nil {
+			return err
+		}
+		selected := toSelectedModifiers(modifiers)
+		if itemReq.Price.Add(selected.UnitAmount()).IsNegative() {
+			return fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
+		}
+
+		order.Items = append(order.Items, domain.HeldOrderItem{
+			ProductID:   productID,
+			ProductName: product.Name,
+			Price:       itemReq.Price,
+			Quantity:    itemReq.Quantity,
+			Modifiers:   selected,
+		})
+
+		if !order.ReserveStock {
+			continue
+		}
+		// Reserve what the sale will take, see TransactionService.Create
+		needs, _, err := componentNeeds(tx, s.productRepo, s.recipeRepo, productID, itemReq.Quantity)
+		if err != nil {
+			return err
+		}
+		if len(needs) == 0 {
+			needs = append(needs, componentNeed{productID: productID, quantity: itemReq.Quantity})
+		}
+		for _, modifier := range modifiers {
+			if modifier.IngredientID != nil {
+				needs = append(needs, componentNeed{productID: *modifier.IngredientID, quantity: modifier.IngredientQuantity * modifier.Quantity * itemReq.Quantity})
+			}
+		}
+		for _, need := range needs {
+			if _, ok := requested[need.productID]; !ok {
+				reservedProducts = append(reservedProducts, need.productID)
+			}
+			requested[need.productID] += need.quantity
+		}
+	}
+
+	// Locked in a fixed order so two carts reserving the same products can't deadlock
+	sort.Slice(reservedProducts, func(i, j int) bool { return reservedProducts[i].String() < reservedProducts[j].String() })
+	for _, productID := range reservedProducts {
+		var product domain.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+			return fmt.Errorf("product not found: %s", productID)
+		}
+		reserved, err := s.heldOrderRepo.ReservedQuantityTx(tx, productID, exclude)
+		if err != nil {
+			return err
+		}
+		if available := product.Stock - reserved; available < requested[productID] {
+			return fmt.Errorf("not enough stock to reserve %s. Available: %d, Requested: %d", product.Name, available, requested[productID])
+		}
+		order.Reservations = append(order.Reservations, domain.HeldOrderReservation{ProductID: productID, Quantity: requested[productID]})
+	}
+
+	order.ReservedUntil = nil
+	if order.ReserveStock {
+		minutes := s.settingService.IntSetting("hold_reservation_minutes", defaultHoldReservationMinutes)
+		if minutes <= 0 {
+			minutes = defaultHoldReservationMinutes
+		}
+		reservedUntil := time.Now().Add(time.Duration(minutes) * time.Minute)
+		order.ReservedUntil = &reservedUntil
+	}
+	return nil
+}
+
+// resolveStoreID puts device holds in the device's store and falls back to
+// the requested store otherwise, like sales
+func (s *heldOrderService) resolveStoreID(requested string, actor domain.Actor) (*uuid.UUID, error) {
+	if actor.DeviceID != nil {
+		device, err := s.deviceRepo.FindByID(*actor.DeviceID)
+		if err != nil {
+			return nil, fmt.Errorf("device not found: %v", err)
+		}
+		return device.StoreID, nil
+	}
+
+	if requested == "" {
+		return nil, nil
+	}
+	storeID, err := uuid.Parse(requested)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %s", requested)
+	}
+	return &storeID, nil
+}
+
+func toHeldOrderResponse(order *domain.HeldOrder) *dto.HeldOrderResponse {
+	response := &dto.HeldOrderResponse{
+		ID:             order.ID.String(),
+		Label:          order.Label,
+		Status:         order.Status,
+		StoreID:        formatOptionalUUID(order.StoreID),
+		UserID:         formatOptionalUUID(order.UserID),
+		DeviceID:       formatOptionalUUID(order.DeviceID),
+		ResumedBy:      formatOptionalUUID(order.ResumedBy),
+		ResumedAt:      formatOptionalTime(order.ResumedAt),
+		CustomerName:   order.CustomerName,
+		Notes:          order.Notes,
+		Items:          []dto.HeldOrderItemResponse{},
+		DiscountAmount: order.DiscountAmount,
+		ReserveStock:   order.ReserveStock,
+		ReservedUntil:  formatOptionalTime(order.ReservedUntil),
+		Reserved:       order.Status == domain.HeldOrderStatusHeld && order.ReserveStock && order.ReservedUntil != nil && order.ReservedUntil.After(time.Now()),
+		TransactionID:  formatOptionalUUID(order.TransactionID),
+		CreatedAt:      order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      order.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if order.User != nil {
+		response.Username = order.User.Username
+	}
+
+	var total money.Money
+	for _, item := range order.Items {
+		subtotal := item.Price.Add(item.Modifiers.UnitAmount()).Mul(int64(item.Quantity))
+		total = total.Add(subtotal)
+		response.Items = append(response.Items, dto.HeldOrderItemResponse{
+			ProductID:   item.ProductID.String(),
+			ProductName: item.ProductName,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			Modifiers:   toSelectedModifierResponses(item.Modifiers),
+			Subtotal:    subtotal,
+		})
+	}
+	response.TotalAmount = total
+
+	return response
+}
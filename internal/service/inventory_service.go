@@ -0,0 +1,420 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryService interface {
+	// RecordIn and RecordOut post a movement, update the cost layers and save the
+	// product inside the caller's database transaction. The product must already
+	// be locked FOR UPDATE by the caller.
+	RecordIn(tx *gorm.DB, product *domain.Product, quantity int, totalCost money.Money, movement domain.InventoryMovement) (*domain.InventoryMovement, error)
+	RecordOut(tx *gorm.DB, product *domain.Product, quantity int, movement domain.InventoryMovement) (*domain.InventoryMovement, error)
+	Receive(req *dto.ReceiveStockRequest, actor domain.Actor) (*dto.InventoryMovementResponse, error)
+	Adjust(req *dto.StockAdjustmentRequest, actor domain.Actor) (*dto.InventoryMovementResponse, error)
+	GetMovements(page, limit int, filters domain.InventoryMovementFilters) ([]dto.InventoryMovementResponse, int64, error)
+	GetValuation(asOf time.Time, productID *uuid.UUID) (*dto.InventoryValuationResponse, error)
+	CostingMethod() string
+}
+
+type inventoryService struct {
+	inventoryRepo  domain.InventoryRepository
+	settingService *SettingService
+	reorderService ReorderService
+	auditService   AuditService
+	db             *gorm.DB
+}
+
+func NewInventoryService(inventoryRepo domain.InventoryRepository, settingService *SettingService, reorderService ReorderService, auditService AuditService, db *gorm.DB) InventoryService {
+	return &inventoryService{
+		inventoryRepo:  inventoryRepo,
+		settingService: settingService,
+		reorderService: reorderService,
+		auditService:   auditService,
+		db:             db,
+	}
+}
+
+func (s *inventoryService) RecordIn(tx *gorm.DB, product *domain.Product, quantity int, totalCost money.Money, movement domain.InventoryMovement) (*domain.InventoryMovement, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+	if totalCost.IsNegative() {
+		return nil, errors.New("cost cannot be negative")
+	}
+
+	unitCost := totalCost.Div(int64(quantity), money.RoundHalfUp)
+
+	movement.ProductID = product.ID
+	movement.Quantity = quantity
+	movement.UnitCost = unitCost
+	movement.TotalCost = totalCost
+	if movement.MovementType == "" {
+		movement.MovementType = "in"
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to create inventory movement: %v", err)
+	}
+
+	// Units that were sold while stock was negative have already been costed,
+	// so only the part that is actually back on the shelf opens a layer
+	stockBefore := product.Stock
+	layerQuantity := quantity
+	if stockBefore < 0 {
+		layerQuantity += stockBefore
+	}
+	if layerQuantity > 0 {
+		layer := domain.InventoryCostLayer{
+			ProductID:         product.ID,
+			MovementID:        &movement.ID,
+			Quantity:          quantity,
+			RemainingQuantity: layerQuantity,
+			UnitCost:          unitCost,
+			ReceivedAt:        time.Now(),
+		}
+		if err := tx.Create(&layer).Error; err != nil {
+			return nil, fmt.Errorf("failed to create cost layer: %v", err)
+		}
+	}
+
+	product.Stock += quantity
+	if err := s.updateProductCost(tx, product, stockBefore, quantity, totalCost); err != nil {
+		return nil, err
+	}
+	if err := s.saveProductStock(tx, product); err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+func (s *inventoryService) RecordOut(tx *gorm.DB, product *domain.Product, quantity int, movement domain.InventoryMovement) (*domain.InventoryMovement, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+
+	// Layers are always consumed oldest first so they stay in step with the
+	// physical stock, even while the average method decides the cost
+	fifoCost, err := s.consumeLayers(tx, product, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	cogs := product.Cost.Mul(int64(quantity))
+	if s.CostingMethod() == domain.CostingMethodFIFO {
+		cogs = fifoCost
+	}
+
+	movement.ProductID = product.ID
+	movement.Quantity = -quantity // Negative for outgoing
+	movement.UnitCost = cogs.Div(int64(quantity), money.RoundHalfUp)
+	movement.TotalCost = cogs.Neg()
+	if movement.MovementType == "" {
+		movement.MovementType = "out"
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, fmt.Errorf("failed to create inventory movement: %v", err)
+	}
+
+	stockBefore := product.Stock
+	product.Stock -= quantity
+	if err := s.updateProductCost(tx, product, stockBefore, -quantity, cogs.Neg()); err != nil {
+		return nil, err
+	}
+	if err := s.saveProductStock(tx, product); err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+func (s *inventoryService) Receive(req *dto.ReceiveStockRequest, actor domain.Actor) (*dto.InventoryMovementResponse, error) {
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return nil, errors.New("invalid product ID format")
+	}
+	if req.UnitCost.IsNegative() {
+		return nil, errors.New("unit cost cannot be negative")
+	}
+
+	var movement *domain.InventoryMovement
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		product, err := lockProduct(tx, productID)
+		if err != nil {
+			return err
+		}
+		if product.IsBundle {
+			return errors.New("bundle stock comes from its items, receive the items instead")
+		}
+
+		// Fall back to the current cost when the receipt doesn't say
+		unitCost := req.UnitCost
+		if unitCost.IsZero() {
+			unitCost = product.Cost
+		}
+
+		movement, err = s.RecordIn(tx, product, req.Quantity, unitCost.Mul(int64(req.Quantity)), domain.InventoryMovement{
+			MovementType:  "in",
+			ReferenceType: "purchase",
+			Notes:         req.Notes,
+			UserID:        &actor.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(tx, actor, "receive", "inventory_movement", movement.ID.String(), nil, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toInventoryMovementResponse(movement), nil
+}
+
+func (s *inventoryService) Adjust(req *dto.StockAdjustmentRequest, actor domain.Actor) (*dto.InventoryMovementResponse, error) {
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return nil, errors.New("invalid product ID format")
+	}
+	if req.Quantity == 0 {
+		return nil, errors.New("quantity cannot be zero")
+	}
+
+	var movement *domain.InventoryMovement
+	var product *domain.Product
+	var previousStock int
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		product, err = lockProduct(tx, productID)
+		if err != nil {
+			return err
+		}
+		if product.IsBundle {
+			return errors.New("bundle stock comes from its items, adjust the items instead")
+		}
+		previousStock = product.Stock
+
+		adjustment := domain.InventoryMovement{
+			MovementType:  "adjustment",
+			ReferenceType: "adjustment",
+			Notes:         req.Notes,
+			UserID:        &actor.UserID,
+		}
+		if req.Quantity > 0 {
+			movement, err = s.RecordIn(tx, product, req.Quantity, product.Cost.Mul(int64(req.Quantity)), adjustment)
+		} else {
+			movement, err = s.RecordOut(tx, product, -req.Quantity, adjustment)
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.auditService.RecordTx(tx, actor, "adjust", "inventory_movement", movement.ID.String(), nil, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.reorderService.CheckStockLevel(product, previousStock)
+
+	return toInventoryMovementResponse(movement), nil
+}
+
+func (s *inventoryService) GetMovements(page, limit int, filters domain.InventoryMovementFilters) ([]dto.InventoryMovementResponse, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	movements, total, err := s.inventoryRepo.FindMovements(page, limit, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []dto.InventoryMovementResponse
+	for i := range movements {
+		responses = append(responses, *toInventoryMovementResponse(&movements[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *inventoryService) GetValuation(asOf time.Time, productID *uuid.UUID) (*dto.InventoryValuationResponse, error) {
+	rows, err := s.inventoryRepo.Valuation(asOf, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation := &dto.InventoryValuationResponse{
+		AsOf:          asOf.Format(time.RFC3339),
+		CostingMethod: s.CostingMethod(),
+		Items:         []dto.InventoryValuationItem{},
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 && row.Value.IsZero() {
+			continue
+		}
+
+		item := dto.InventoryValuationItem{
+			ProductID:   row.ProductID.String(),
+			ProductName: row.ProductName,
+			SKU:         row.SKU,
+			Quantity:    row.Quantity,
+			Value:       row.Value,
+		}
+		if row.Quantity > 0 {
+			item.UnitCost = row.Value.Div(row.Quantity, money.RoundHalfUp)
+		}
+
+		valuation.Items = append(valuation.Items, item)
+		valuation.TotalQuantity += row.Quantity
+		valuation.TotalValue = valuation.TotalValue.Add(row.Value)
+	}
+
+	return valuation, nil
+}
+
+// CostingMethod returns the configured method, defaulting to moving weighted average
+func (s *inventoryService) CostingMethod() string {
+	setting, err := s.settingService.GetSettingByKey("costing_method")
+	if err != nil {
+		return domain.CostingMethodAverage
+	}
+
+	var method string
+	if err := json.Unmarshal([]byte(setting.Value), &method); err != nil {
+		method = setting.Value
+	}
+	if method == domain.CostingMethodFIFO {
+		return domain.CostingMethodFIFO
+	}
+	return domain.CostingMethodAverage
+}
+
+// Helper functions
+
+// consumeLayers takes quantity from the oldest open layers and returns their cost.
+// Anything beyond the open layers (negative stock) is costed at the product cost.
+func (s *inventoryService) consumeLayers(tx *gorm.DB, product *domain.Product, quantity int) (money.Money, error) {
+	var layers []domain.InventoryCostLayer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND remaining_quantity > 0", product.ID).
+		Order("received_at ASC, created_at ASC").
+		Find(&layers).Error; err != nil {
+		return money.Zero, fmt.Errorf("failed to load cost layers: %v", err)
+	}
+
+	var cost money.Money
+	remaining := quantity
+	for i := range layers {
+		if remaining == 0 {
+			break
+		}
+
+		take := layers[i].RemainingQuantity
+		if take > remaining {
+			take = remaining
+		}
+		cost = cost.Add(layers[i].UnitCost.Mul(int64(take)))
+		remaining -= take
+
+		if err := tx.Model(&layers[i]).Update("remaining_quantity", layers[i].RemainingQuantity-take).Error; err != nil {
+			return money.Zero, fmt.Errorf("failed to update cost layer: %v", err)
+		}
+	}
+
+	if remaining > 0 {
+		cost = cost.Add(product.Cost.Mul(int64(remaining)))
+	}
+
+	return cost, nil
+}
+
+// updateProductCost keeps Product.Cost at the unit cost the next sale will use:
+// the moving average, or the average of the open layers under FIFO
+func (s *inventoryService) updateProductCost(tx *gorm.DB, product *domain.Product, stockBefore, quantity int, totalCost money.Money) error {
+	if s.CostingMethod() == domain.CostingMethodFIFO {
+		var open struct {
+			Quantity int64
+			Value    money.Money
+		}
+		if err := tx.Model(&domain.InventoryCostLayer{}).
+			Select("COALESCE(SUM(remaining_quantity), 0) as quantity, COALESCE(SUM(remaining_quantity * unit_cost), 0) as value").
+			Where("product_id = ? AND remaining_quantity > 0", product.ID).
+			Scan(&open).Error; err != nil {
+			return fmt.Errorf("failed to load cost layers: %v", err)
+		}
+		if open.Quantity > 0 {
+			product.Cost = open.Value.Div(open.Quantity, money.RoundHalfUp)
+		}
+		return nil
+	}
+
+	// Outgoing stock leaves the average unchanged
+	if quantity <= 0 {
+		return nil
+	}
+	if stockBefore <= 0 {
+		product.Cost = totalCost.Div(int64(quantity), money.RoundHalfUp)
+		return nil
+	}
+	product.Cost = product.Cost.Mul(int64(stockBefore)).Add(totalCost).Div(int64(stockBefore+quantity), money.RoundHalfUp)
+	return nil
+}
+
+func (s *inventoryService) saveProductStock(tx *gorm.DB, product *domain.Product) error {
+	product.StockVersion++
+	now := time.Now()
+	product.LastStockUpdate = &now
+
+	if err := tx.Omit("Category").Save(product).Error; err != nil {
+		return fmt.Errorf("failed to update stock: %v", err)
+	}
+	return nil
+}
+
+func lockProduct(tx *gorm.DB, productID uuid.UUID) (*domain.Product, error) {
+	var product domain.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		return nil, fmt.Errorf("product not found: %s", productID)
+	}
+	return &product, nil
+}
+
+func toInventoryMovementResponse(movement *domain.InventoryMovement) *dto.InventoryMovementResponse {
+	response := &dto.InventoryMovementResponse{
+		ID:            movement.ID.String(),
+		ProductID:     movement.ProductID.String(),
+		MovementType:  movement.MovementType,
+		Quantity:      movement.Quantity,
+		UnitCost:      movement.UnitCost,
+		TotalCost:     movement.TotalCost,
+		ReferenceType: movement.ReferenceType,
+		Notes:         movement.Notes,
+		CreatedAt:     movement.CreatedAt.Format(time.RFC3339),
+	}
+
+	if movement.Product != nil {
+		response.ProductName = movement.Product.Name
+	}
+	if movement.ReferenceID != nil {
+		response.ReferenceID = movement.ReferenceID.String()
+	}
+	if movement.UserID != nil {
+		response.UserID = movement.UserID.String()
+	}
+
+	return response
+}
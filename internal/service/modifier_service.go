@@ -0,0 +1,416 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModifierService manages modifier groups and which products offer them.
+// The groups are checked and priced when a line is sold, held or sent to the
+// kitchen, see selectModifiers.
+type ModifierService interface {
+	GetAll(page, limit int) ([]*dto.ModifierGroupResponse, int64, error)
+	GetByID(id string) (*dto.ModifierGroupResponse, error)
+	Create(req *dto.CreateModifierGroupRequest, actor domain.Actor) (*dto.ModifierGroupResponse, error)
+	Update(id string, req *dto.UpdateModifierGroupRequest, actor domain.Actor) (*dto.ModifierGroupResponse, error)
+	Delete(id string, actor domain.Actor) error
+	GetProductGroups(productID string) ([]*dto.ModifierGroupResponse, error)
+	SetProductGroups(productID string, req *dto.SetProductModifierGroupsRequest, actor domain.Actor) ([]*dto.ModifierGroupResponse, error)
+}
+
+type modifierService struct {
+	modifierRepo domain.ModifierRepository
+	productRepo  domain.ProductRepository
+	auditService AuditService
+	db           *gorm.DB
+}
+
+func NewModifierService(modifierRepo domain.ModifierRepository, productRepo domain.ProductRepository, auditService AuditService, db *gorm.DB) ModifierService {
+	return &modifierService{
+		modifierRepo: modifierRepo,
+		productRepo:  productRepo,
+		auditService: auditService,
+		db:           db,
+	}
+}
+
+func (s *modifierService) GetAll(page, limit int) ([]*dto.ModifierGroupResponse, int64, error) {
+	groups, total, err := s.modifierRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.ModifierGroupResponse
+	for i := range groups {
+		responses = append(responses, toModifierGroupResponse(&groups[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *modifierService) GetByID(id string) (*dto.ModifierGroupResponse, error) {
+	group, err := s.findGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toModifierGroupResponse(group), nil
+}
+
+func (s *modifierService) Create(req *dto.CreateModifierGroupRequest, actor domain.Actor) (*dto.ModifierGroupResponse, error) {
+	group := domain.ModifierGroup{
+		Name:          req.Name,
+		MinSelections: req.MinSelections,
+		MaxSelections: req.MaxSelections,
+		IsActive:      true,
+	}
+	if err := s.setOptions(&group, req.Options); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.modifierRepo.WithTx(tx).Create(&group); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "modifier_group", group.ID.String(), nil, group)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(group.ID.String())
+}
+
+func (s *modifierService) Update(id string, req *dto.UpdateModifierGroupRequest, actor domain.Actor) (*dto.ModifierGroupResponse, error) {
+	group, err := s.findGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *group
+
+	group.Name = req.Name
+	group.MinSelections = req.MinSelections
+	group.MaxSelections = req.MaxSelections
+	group.IsActive = req.IsActive
+	if err := s.setOptions(group, req.Options); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.modifierRepo.WithTx(tx).Update(group); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "modifier_group", group.ID.String(), before, group)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(group.ID.String())
+}
+
+func (s *modifierService) Delete(id string, actor domain.Actor) error {
+	group, err := s.findGroup(id)
+	if err != nil {
+		return err
+	}
+
+	// Detach it from products first so their menus don't change by surprise
+	inUse, err := s.modifierRepo.IsInUse(group.ID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("modifier group is attached to products")
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.modifierRepo.WithTx(tx).Delete(group.ID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "modifier_group", group.ID.String(), group, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *modifierService) GetProductGroups(productID string) ([]*dto.ModifierGroupResponse, error) {
+	product, err := s.findProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.modifierRepo.FindByProduct(product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*dto.ModifierGroupResponse{}
+	for i := range groups {
+		responses = append(responses, toModifierGroupResponse(&groups[i]))
+	}
+
+	return responses, nil
+}
+
+func (s *modifierService) SetProductGroups(productID string, req *dto.SetProductModifierGroupsRequest, actor domain.Actor) ([]*dto.ModifierGroupResponse, error) {
+	product, err := s.findProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var groupIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range req.GroupIDs {
+		group, err := s.findGroup(id)
+		if err != nil {
+			return nil, err
+		}
+		if seen[group.ID] {
+			return nil, fmt.Errorf("modifier group %s is listed twice", group.Name)
+		}
+		seen[group.ID] = true
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	previous, err := s.modifierRepo.FindByProduct(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	var before []string
+	for _, group := range previous {
+		before = append(before, group.ID.String())
+	}
+
+	after := map[string]interface{}{"modifier_group_ids": req.GroupIDs}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.modifierRepo.WithTx(tx).SetProductGroups(product.ID, groupIDs); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "product_modifier_groups", product.ID.String(), map[string]interface{}{"modifier_group_ids": before}, after)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetProductGroups(product.ID.String())
+}
+
+// Helper functions
+
+func (s *modifierService) findGroup(id string) (*domain.ModifierGroup, error) {
+	groupID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid modifier group ID format")
+	}
+
+	group, err := s.modifierRepo.FindByID(groupID)
+	if err != nil {
+		return nil, errors.New("modifier group not found")
+	}
+
+	return group, nil
+}
+
+func (s *modifierService) findProduct(id string) (*domain.Product, error) {
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid product ID format")
+	}
+
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	return product, nil
+}
+
+// setOptions checks the selection limits and replaces the group's options,
+// keeping the IDs of existing ones so carts that chose them stay valid
+func (s *modifierService) setOptions(group *domain.ModifierGroup, options []dto.ModifierOptionRequest) error {
+	if group.MaxSelections > 0 && group.MinSelections > group.MaxSelections {
+		return errors.New("min selections cannot exceed max selections")
+	}
+
+	existing := make(map[uuid.UUID]domain.ModifierOption)
+	for _, option := range group.Options {
+		existing[option.ID] = option
+	}
+
+	var result []domain.ModifierOption
+	activeOptions := 0
+	for i, req := range options {
+		option := domain.ModifierOption{GroupID: group.ID}
+		if req.ID != "" {
+			optionID, err := uuid.Parse(req.ID)
+			if err != nil {
+				return fmt.Errorf("invalid modifier option ID: %s", req.ID)
+			}
+			current, ok := existing[optionID]
+			if !ok {
+				return fmt.Errorf("modifier option not found in this group: %s", req.ID)
+			}
+			option = current
+		}
+
+		option.Name = req.Name
+		option.PriceDelta = req.PriceDelta
+		option.SortOrder = i
+		option.IsActive = req.IsActive == nil || *req.IsActive
+		option.IngredientID = nil
+		option.IngredientQuantity = 0
+		option.Ingredient = nil
+		if req.IngredientID != "" {
+			ingredient, err := s.findProduct(req.IngredientID)
+			if err != nil {
+				return fmt.Errorf("ingredient %s: %v", req.IngredientID, err)
+			}
+			if req.IngredientQuantity <= 0 {
+				return fmt.Errorf("ingredient quantity is required for option %s", req.Name)
+			}
+			option.IngredientID = &ingredient.ID
+			option.IngredientQuantity = req.IngredientQuantity
+		}
+
+		if option.IsActive {
+			activeOptions++
+		}
+		result = append(result, option)
+	}
+
+	// A required choice needs at least as many options to choose from
+	if group.MinSelections > activeOptions {
+		return fmt.Errorf("min selections cannot exceed the %d active option(s)", activeOptions)
+	}
+
+	group.Options = result
+	return nil
+}
+
+// selectModifiers checks the options chosen for a line of product against the
+// product's active modifier groups and their selection limits. An option
+// listed more than once is chosen that many times. The result carries the
+// price and the ingredient to take per item.
+func selectModifiers(product *domain.Product, groups []domain.ModifierGroup, optionIDs []string) ([]domain.TransactionItemModifier, error) {
+	type choice struct {
+		group  *domain.ModifierGroup
+		option *domain.ModifierOption
+	}
+	available := make(map[uuid.UUID]choice)
+	for i := range groups {
+		if !groups[i].IsActive {
+			continue
+		}
+		for j := range groups[i].Options {
+			if groups[i].Options[j].IsActive {
+				available[groups[i].Options[j].ID] = choice{group: &groups[i], option: &groups[i].Options[j]}
+			}
+		}
+	}
+
+	var modifiers []domain.TransactionItemModifier
+	index := make(map[uuid.UUID]int)
+	perGroup := make(map[uuid.UUID]int)
+	for _, id := range optionIDs {
+		optionID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modifier option ID: %s", id)
+		}
+		chosen, ok := available[optionID]
+		if !ok {
+			return nil, fmt.Errorf("modifier option %s is not available for %s", id, product.Name)
+		}
+
+		perGroup[chosen.group.ID]++
+		if i, ok := index[optionID]; ok {
+			modifiers[i].Quantity++
+			continue
+		}
+		index[optionID] = len(modifiers)
+		modifiers = append(modifiers, domain.TransactionItemModifier{
+			ModifierOptionID:   &chosen.option.ID,
+			GroupName:          chosen.group.Name,
+			OptionName:         chosen.option.Name,
+			PriceDelta:         chosen.option.PriceDelta,
+			Quantity:           1,
+			IngredientID:       chosen.option.IngredientID,
+			IngredientQuantity: chosen.option.IngredientQuantity,
+		})
+	}
+
+	for _, group := range groups {
+		if !group.IsActive {
+			continue
+		}
+		count := perGroup[group.ID]
+		if count < group.MinSelections {
+			return nil, fmt.Errorf("%s for %s needs at least %d selection(s)", group.Name, product.Name, group.MinSelections)
+		}
+		if group.MaxSelections > 0 && count > group.MaxSelections {
+			return nil, fmt.Errorf("%s for %s allows at most %d selection(s)", group.Name, product.Name, group.MaxSelections)
+		}
+	}
+
+	return modifiers, nil
+}
+
+// toSelectedModifiers keeps what a cart line needs to show and re-sell its modifiers
+func toSelectedModifiers(modifiers []domain.TransactionItemModifier) domain.SelectedModifiers {
+	var selected domain.SelectedModifiers
+	for _, modifier := range modifiers {
+		selected = append(selected, domain.SelectedModifier{
+			OptionID:   *modifier.ModifierOptionID,
+			GroupName:  modifier.GroupName,
+			OptionName: modifier.OptionName,
+			PriceDelta: modifier.PriceDelta,
+			Quantity:   modifier.Quantity,
+		})
+	}
+	return selected
+}
+
+func toSelectedModifierResponses(modifiers domain.SelectedModifiers) []dto.SelectedModifierResponse {
+	var responses []dto.SelectedModifierResponse
+	for _, modifier := range modifiers {
+		responses = append(responses, dto.SelectedModifierResponse{
+			OptionID:   modifier.OptionID.String(),
+			GroupName:  modifier.GroupName,
+			OptionName: modifier.OptionName,
+			PriceDelta: modifier.PriceDelta,
+			Quantity:   modifier.Quantity,
+		})
+	}
+	return responses
+}
+
+func toModifierGroupResponse(group *domain.ModifierGroup) *dto.ModifierGroupResponse {
+	response := &dto.ModifierGroupResponse{
+		ID:            group.ID.String(),
+		Name:          group.Name,
+		MinSelections: group.MinSelections,
+		MaxSelections: group.MaxSelections,
+		IsActive:      group.IsActive,
+		Options:       []dto.ModifierOptionResponse{},
+		CreatedAt:     group.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, option := range group.Options {
+		response.Options = append(response.Options, dto.ModifierOptionResponse{
+			ID:                 option.ID.String(),
+			Name:               option.Name,
+			PriceDelta:         option.PriceDelta,
+			IngredientID:       formatOptionalUUID(option.IngredientID),
+			IngredientQuantity: option.IngredientQuantity,
+			SortOrder:          option.SortOrder,
+			IsActive:           option.IsActive,
+		})
+	}
+
+	return response
+}
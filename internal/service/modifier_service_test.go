@@ -0,0 +1,81 @@
+package service
+
+import (
+	"pos-backend/internal/domain"
+	"pos-backend/pkg/money"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSelectModifiers(t *testing.T) {
+	product := &domain.Product{Name: "Latte"}
+	milkID := uuid.New()
+
+	oat := domain.ModifierOption{ID: uuid.New(), Name: "Oat", PriceDelta: money.MustParse("5"), IngredientID: &milkID, IngredientQuantity: 1, IsActive: true}
+	soy := domain.ModifierOption{ID: uuid.New(), Name: "Soy", PriceDelta: money.MustParse("4"), IsActive: true}
+	almond := domain.ModifierOption{ID: uuid.New(), Name: "Almond", IsActive: false}
+	boba := domain.ModifierOption{ID: uuid.New(), Name: "Boba", PriceDelta: money.MustParse("3"), IsActive: true}
+	lessIce := domain.ModifierOption{ID: uuid.New(), Name: "Less ice", IsActive: true}
+
+	groups := []domain.ModifierGroup{
+		{ID: uuid.New(), Name: "Milk", MinSelections: 1, MaxSelections: 1, IsActive: true, Options: []domain.ModifierOption{oat, soy, almond}},
+		{ID: uuid.New(), Name: "Toppings", MaxSelections: 2, IsActive: true, Options: []domain.ModifierOption{boba}},
+		{ID: uuid.New(), Name: "Ice", MinSelections: 1, IsActive: false, Options: []domain.ModifierOption{lessIce}},
+	}
+
+	tests := []struct {
+		name      string
+		optionIDs []string
+		want      map[string]int // Option name to quantity
+		wantErr   string
+	}{
+		{name: "required choice made", optionIDs: []string{oat.ID.String()}, want: map[string]int{"Oat": 1}},
+		{name: "repeated option adds up", optionIDs: []string{oat.ID.String(), boba.ID.String(), boba.ID.String()}, want: map[string]int{"Oat": 1, "Boba": 2}},
+		{name: "required choice missing", optionIDs: []string{boba.ID.String()}, wantErr: "Milk for Latte needs at least 1"},
+		{name: "too many in one group", optionIDs: []string{oat.ID.String(), soy.ID.String()}, wantErr: "Milk for Latte allows at most 1"},
+		{name: "repeats count toward the limit", optionIDs: []string{oat.ID.String(), boba.ID.String(), boba.ID.String(), boba.ID.String()}, wantErr: "Toppings for Latte allows at most 2"},
+		{name: "inactive option", optionIDs: []string{almond.ID.String()}, wantErr: "is not available"},
+		{name: "option of an inactive group", optionIDs: []string{oat.ID.String(), lessIce.ID.String()}, wantErr: "is not available"},
+		{name: "malformed ID", optionIDs: []string{"oat"}, wantErr: "invalid modifier option ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modifiers, err := selectModifiers(product, groups, tt.optionIDs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make(map[string]int)
+			for _, modifier := range modifiers {
+				got[modifier.OptionName] = modifier.Quantity
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got modifiers %v, want %v", got, tt.want)
+			}
+			for name, quantity := range tt.want {
+				if got[name] != quantity {
+					t.Errorf("%s chosen %d time(s), want %d", name, got[name], quantity)
+				}
+			}
+		})
+	}
+
+	// The sold modifier keeps the option's price and ingredient
+	modifiers, err := selectModifiers(product, groups, []string{oat.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	modifier := modifiers[0]
+	if modifier.GroupName != "Milk" || modifier.PriceDelta != oat.PriceDelta || modifier.IngredientID == nil || *modifier.IngredientID != milkID || modifier.IngredientQuantity != 1 {
+		t.Errorf("got %+v, want the Oat option's group, price and ingredient", modifier)
+	}
+}
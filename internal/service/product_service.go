@@ -2,34 +2,54 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"pos-backend/internal/domain"
 	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ProductService interface {
 	GetAll(page, limit int) ([]*dto.ProductResponse, int64, error)
 	GetAllWithFilter(search string, categoryID string, page, limit int) ([]*dto.ProductResponse, int64, error)
 	GetByCategory(categoryID string, page, limit int) ([]*dto.ProductResponse, int64, error)
-	Create(req *dto.CreateProductRequest) (*dto.ProductResponse, error)
+	GetBundles(page, limit int) ([]*dto.ProductResponse, int64, error)
+	Create(req *dto.CreateProductRequest, actor domain.Actor) (*dto.ProductResponse, error)
 	GetByID(id string) (*dto.ProductResponse, error)
 	GetBySKU(sku string) (*dto.ProductResponse, error)
-	Update(id string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error)
-	Delete(id string) error
+	Update(id string, req *dto.UpdateProductRequest, actor domain.Actor) (*dto.ProductResponse, error)
+	Delete(id string, actor domain.Actor) error
 }
 
 type productService struct {
-	productRepo domain.ProductRepository
+	productRepo      domain.ProductRepository
+	taxClassRepo     domain.TaxClassRepository
+	recipeRepo       domain.RecipeRepository
+	inventoryService InventoryService
+	reorderService   ReorderService
+	auditService     AuditService
+	db               *gorm.DB
 }
 
-func NewProductService(productRepo domain.ProductRepository) ProductService {
+func NewProductService(productRepo domain.ProductRepository, taxClassRepo domain.TaxClassRepository, recipeRepo domain.RecipeRepository, inventoryService InventoryService, reorderService ReorderService, auditService AuditService, db *gorm.DB) ProductService {
 	return &productService{
-		productRepo: productRepo,
+		productRepo:      productRepo,
+		taxClassRepo:     taxClassRepo,
+		recipeRepo:       recipeRepo,
+		inventoryService: inventoryService,
+		reorderService:   reorderService,
+		auditService:     auditService,
+		db:               db,
 	}
 }
 
-func (s *productService) Create(req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
+func (s *productService) Create(req *dto.CreateProductRequest, actor domain.Actor) (*dto.ProductResponse, error) {
+	if req.Price.IsNegative() || req.Cost.IsNegative() {
+		return nil, errors.New("price and cost cannot be negative")
+	}
+
 	// Check if SKU already exists
 	existingProduct, _ := s.productRepo.FindBySKU(req.SKU)
 	if existingProduct != nil {
@@ -37,15 +57,27 @@ func (s *productService) Create(req *dto.CreateProductRequest) (*dto.ProductResp
 	}
 
 	product := domain.Product{
-		Name:        req.Name,
-		SKU:         req.SKU,
-		Description: req.Description,
-		Price:       req.Price,
-		Cost:        req.Cost,
-		Stock:       req.Stock,
-		MinStock:    req.MinStock,
-		ImageURL:    req.ImageURL,
-		IsActive:    req.IsActive,
+		Name:         req.Name,
+		SKU:          req.SKU,
+		Description:  req.Description,
+		Price:        req.Price,
+		Cost:         req.Cost,
+		MinStock:     req.MinStock,
+		LeadTimeDays: req.LeadTimeDays,
+		ImageURL:     req.ImageURL,
+		IsActive:     req.IsActive,
+		IsBundle:     req.IsBundle,
+	}
+
+	// A bundle has no stock or cost of its own, both come from its items
+	var bundleItems []domain.BundleItem
+	if req.IsBundle {
+		items, err := s.bundleItems(uuid.Nil, req.BundleItems)
+		if err != nil {
+			return nil, err
+		}
+		bundleItems = items
+		product.Cost = 0
 	}
 
 	// Set category ID if provided
@@ -57,11 +89,37 @@ func (s *productService) Create(req *dto.CreateProductRequest) (*dto.ProductResp
 		product.CategoryID = &categoryID
 	}
 
-	err := s.productRepo.Create(&product)
+	taxClassID, err := parseTaxClassID(s.taxClassRepo, req.TaxClassID)
 	if err != nil {
 		return nil, err
 	}
+	product.TaxClassID = taxClassID
 
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		productRepo := s.productRepo.WithTx(tx)
+		if err := productRepo.Create(&product); err != nil {
+			return err
+		}
+		// Opening stock goes through inventory so it is costed like any other receipt
+		if req.Stock != 0 && !product.IsBundle {
+			if err := s.adjustStockTx(tx, &product, req.Stock, "Initial stock", actor); err != nil {
+				return err
+			}
+		}
+		if product.IsBundle {
+			if err := productRepo.SetBundleItems(product.ID, bundleItems); err != nil {
+				return err
+			}
+			product.BundleItems = bundleItems
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "product", product.ID.String(), nil, product)
+	}); err != nil {
+		return nil, err
+	}
+
+	if product.IsBundle {
+		return s.GetByID(product.ID.String())
+	}
 	return s.toProductResponse(&product), nil
 }
 
@@ -88,16 +146,22 @@ func (s *productService) GetBySKU(sku string) (*dto.ProductResponse, error) {
 	return s.toProductResponse(product), nil
 }
 
-func (s *productService) Update(id string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+func (s *productService) Update(id string, req *dto.UpdateProductRequest, actor domain.Actor) (*dto.ProductResponse, error) {
 	productID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, errors.New("invalid product ID format")
 	}
 
+	if req.Price.IsNegative() {
+		return nil, errors.New("price cannot be negative")
+	}
+
 	product, err := s.productRepo.FindByID(productID)
 	if err != nil {
 		return nil, err
 	}
+	before := *product
+	before.Category = nil
 
 	// Check if SKU is being changed and if it already exists
 	if product.SKU != req.SKU {
@@ -111,12 +175,44 @@ func (s *productService) Update(id string, req *dto.UpdateProductRequest) (*dto.
 	product.SKU = req.SKU
 	product.Description = req.Description
 	product.Price = req.Price
-	product.Cost = req.Cost
-	product.Stock = req.Stock
 	product.MinStock = req.MinStock
+	product.LeadTimeDays = req.LeadTimeDays
 	product.ImageURL = req.ImageURL
 	product.IsActive = req.IsActive
 
+	// The bundle state and items only change when they are sent
+	isBundle := before.IsBundle
+	if req.IsBundle != nil {
+		isBundle = *req.IsBundle
+	}
+	replaceItems := isBundle != before.IsBundle || (isBundle && req.BundleItems != nil)
+
+	var bundleItems []domain.BundleItem
+	if isBundle && !before.IsBundle {
+		// Bundles don't nest
+		isComponent, err := s.productRepo.IsBundleComponent(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if isComponent {
+			return nil, errors.New("product is in a bundle and cannot be a bundle itself")
+		}
+		hasRecipe, err := s.hasRecipe(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasRecipe {
+			return nil, errors.New("product has a recipe and cannot be a bundle, delete the recipe first")
+		}
+	}
+	if isBundle && replaceItems {
+		bundleItems, err = s.bundleItems(product.ID, req.BundleItems)
+		if err != nil {
+			return nil, err
+		}
+	}
+	product.IsBundle = isBundle
+
 	// Update category ID if provided
 	if req.CategoryID != "" {
 		categoryID, err := uuid.Parse(req.CategoryID)
@@ -128,20 +224,96 @@ func (s *productService) Update(id string, req *dto.UpdateProductRequest) (*dto.
 		product.CategoryID = nil
 	}
 
-	err = s.productRepo.Update(product)
-	if err != nil {
+	// A class that was deactivated may stay on the products already using it
+	if req.TaxClassID == "" {
+		product.TaxClassID = nil
+	} else if before.TaxClassID == nil || before.TaxClassID.String() != req.TaxClassID {
+		taxClassID, err := parseTaxClassID(s.taxClassRepo, req.TaxClassID)
+		if err != nil {
+			return nil, err
+		}
+		product.TaxClassID = taxClassID
+	}
+
+	var previousStock int
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Stock and cost move with every sale and receipt, so they come from
+		// the locked row and are never written back from the earlier read
+		locked, err := lockProduct(tx, productID)
+		if err != nil {
+			return err
+		}
+		if isBundle && !before.IsBundle && locked.Stock != 0 {
+			return errors.New("product still has stock of its own, adjust it to zero before making it a bundle")
+		}
+		previousStock = locked.Stock
+		before.Stock, before.Cost, before.StockVersion, before.LastStockUpdate = locked.Stock, locked.Cost, locked.StockVersion, locked.LastStockUpdate
+
+		// Stock changes are booked as adjustments so valuation stays in step
+		if delta := req.Stock - locked.Stock; delta != 0 && !isBundle {
+			if err := s.adjustStockTx(tx, locked, delta, "Stock changed on product update", actor); err != nil {
+				return err
+			}
+		}
+		product.Stock, product.Cost, product.StockVersion, product.LastStockUpdate = locked.Stock, locked.Cost, locked.StockVersion, locked.LastStockUpdate
+
+		productRepo := s.productRepo.WithTx(tx)
+		if err := productRepo.Update(product); err != nil {
+			return err
+		}
+		if replaceItems {
+			if err := productRepo.SetBundleItems(product.ID, bundleItems); err != nil {
+				return err
+			}
+			product.BundleItems = bundleItems
+		}
+		after := *product
+		after.Category = nil
+		return s.auditService.RecordTx(tx, actor, "update", "product", product.ID.String(), before, after)
+	}); err != nil {
 		return nil, err
 	}
 
+	if product.Stock != previousStock {
+		s.reorderService.CheckStockLevel(product, previousStock)
+	}
+
+	if product.IsBundle {
+		return s.GetByID(product.ID.String())
+	}
 	return s.toProductResponse(product), nil
 }
 
-func (s *productService) Delete(id string) error {
+func (s *productService) Delete(id string, actor domain.Actor) error {
 	productID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid product ID format")
 	}
-	return s.productRepo.Delete(productID)
+
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return err
+	}
+	product.Category = nil
+
+	isComponent, err := s.productRepo.IsBundleComponent(productID)
+	if err != nil {
+		return err
+	}
+	if isComponent {
+		return errors.New("product is in a bundle, remove it from the bundle first")
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.productRepo.WithTx(tx).Delete(productID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "product", product.ID.String(), product, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (s *productService) GetAll(page, limit int) ([]*dto.ProductResponse, int64, error) {
@@ -203,7 +375,104 @@ func (s *productService) GetByCategory(categoryID string, page, limit int) ([]*d
 	return responses, totalData, nil
 }
 
-// Helper function to convert domain.Product to dto.ProductResponse
+func (s *productService) GetBundles(page, limit int) ([]*dto.ProductResponse, int64, error) {
+	products, totalData, err := s.productRepo.FindBundles(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.ProductResponse
+	for _, product := range products {
+		responses = append(responses, s.toProductResponse(&product))
+	}
+
+	return responses, totalData, nil
+}
+
+// bundleItems checks the items requested for a bundle. Components must be
+// products with stock of their own, so bundles don't nest.
+func (s *productService) bundleItems(bundleID uuid.UUID, reqs []dto.BundleItemRequest) ([]domain.BundleItem, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("a bundle needs at least one item")
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var items []domain.BundleItem
+	for _, req := range reqs {
+		componentID, err := uuid.Parse(req.ComponentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component ID format: %s", req.ComponentID)
+		}
+		if req.Quantity < 1 {
+			return nil, errors.New("bundle item quantity must be at least 1")
+		}
+		if componentID == bundleID {
+			return nil, errors.New("a bundle cannot contain itself")
+		}
+		if seen[componentID] {
+			return nil, fmt.Errorf("component %s is listed twice", req.ComponentID)
+		}
+		seen[componentID] = true
+
+		component, err := s.productRepo.FindByID(componentID)
+		if err != nil {
+			return nil, fmt.Errorf("component not found: %s", req.ComponentID)
+		}
+		if component.IsBundle {
+			return nil, fmt.Errorf("component %s is a bundle itself", component.Name)
+		}
+		// A bundle takes its items' own stock, which a recipe product doesn't keep
+		hasRecipe, err := s.hasRecipe(componentID)
+		if err != nil {
+			return nil, err
+		}
+		if hasRecipe {
+			return nil, fmt.Errorf("component %s has a recipe", component.Name)
+		}
+
+		items = append(items, domain.BundleItem{
+			ComponentID: componentID,
+			Quantity:    req.Quantity,
+		})
+	}
+
+	return items, nil
+}
+
+// hasRecipe reports whether the product has a recipe, active or not
+func (s *productService) hasRecipe(productID uuid.UUID) (bool, error) {
+	_, err := s.recipeRepo.FindByProduct(productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// adjustStockTx books quantity as a stock adjustment of product inside tx,
+// costed at the product's current cost. The product must be locked or created
+// in tx.
+func (s *productService) adjustStockTx(tx *gorm.DB, product *domain.Product, quantity int, notes string, actor domain.Actor) error {
+	adjustment := domain.InventoryMovement{
+		MovementType:  "adjustment",
+		ReferenceType: "adjustment",
+		Notes:         notes,
+		UserID:        &actor.UserID,
+	}
+
+	var movement *domain.InventoryMovement
+	var err error
+	if quantity > 0 {
+		movement, err = s.inventoryService.RecordIn(tx, product, quantity, product.Cost.Mul(int64(quantity)), adjustment)
+	} else {
+		movement, err = s.inventoryService.RecordOut(tx, product, -quantity, adjustment)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.auditService.RecordTx(tx, actor, "adjust", "inventory_movement", movement.ID.String(), nil, movement)
+}
+
 func (s *productService) toProductResponse(product *domain.Product) *dto.ProductResponse {
 	response := &dto.ProductResponse{
 		ID:           product.ID.String(),
@@ -212,11 +481,36 @@ func (s *productService) toProductResponse(product *domain.Product) *dto.Product
 		Description:  product.Description,
 		Price:        product.Price,
 		Cost:         product.Cost,
-		Stock:        product.Stock,
+		Stock:        product.AvailableStock(),
 		MinStock:     product.MinStock,
+		LeadTimeDays: product.LeadTimeDays,
 		StockVersion: product.StockVersion,
 		ImageURL:     product.ImageURL,
 		IsActive:     product.IsActive,
+		IsBundle:     product.IsBundle,
+	}
+
+	// A bundle costs what its items cost now, sales are costed from the stock taken
+	if product.IsBundle {
+		var cost money.Money
+		for _, item := range product.BundleItems {
+			itemResponse := dto.BundleItemResponse{
+				ComponentID: item.ComponentID.String(),
+				Quantity:    item.Quantity,
+			}
+			if item.Component != nil {
+				itemResponse.ComponentName = item.Component.Name
+				itemResponse.Price = item.Component.Price
+				itemResponse.Stock = item.Component.Stock
+				cost = cost.Add(item.Component.Cost.Mul(int64(item.Quantity)))
+			}
+			response.BundleItems = append(response.BundleItems, itemResponse)
+		}
+		response.Cost = cost
+	}
+
+	if product.TaxClassID != nil {
+		response.TaxClassID = product.TaxClassID.String()
 	}
 
 	if product.CategoryID != nil {
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuarantineService interface {
+	GetAll(page, limit int, status string) ([]*dto.QuarantinedTransactionResponse, int64, error)
+	GetByID(id string) (*dto.QuarantinedTransactionResponse, error)
+	Approve(id string, req *dto.ReviewQuarantineRequest, actor domain.Actor) (*dto.TransactionResponse, error)
+	Reject(id string, req *dto.ReviewQuarantineRequest, actor domain.Actor) (*dto.QuarantinedTransactionResponse, error)
+}
+
+type quarantineService struct {
+	quarantineRepo     domain.QuarantineRepository
+	transactionService TransactionService
+	auditService       AuditService
+	db                 *gorm.DB
+}
+
+func NewQuarantineService(quarantineRepo domain.QuarantineRepository, transactionService TransactionService, auditService AuditService, db *gorm.DB) QuarantineService {
+	return &quarantineService{
+		quarantineRepo:     quarantineRepo,
+		transactionService: transactionService,
+		auditService:       auditService,
+		db:                 db,
+	}
+}
+
+func (s *quarantineService) GetAll(page, limit int, status string) ([]*dto.QuarantinedTransactionResponse, int64, error) {
+	entries, totalData, err := s.quarantineRepo.FindAll(page, limit, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.QuarantinedTransactionResponse
+	for _, entry := range entries {
+		responses = append(responses, s.toQuarantineResponse(&entry))
+	}
+
+	return responses, totalData, nil
+}
+
+func (s *quarantineService) GetByID(id string) (*dto.QuarantinedTransactionResponse, error) {
+	entry, err := s.findEntry(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toQuarantineResponse(entry), nil
+}
+
+// Approve posts the quarantined sale as the original cashier and device after manual review
+func (s *quarantineService) Approve(id string, req *dto.ReviewQuarantineRequest, actor domain.Actor) (*dto.TransactionResponse, error) {
+	entry, err := s.findEntry(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.UserID == nil {
+		return nil, errors.New("quarantined transaction has no cashier to post it for")
+	}
+
+	var txReq dto.CreateTransactionRequest
+	if err := json.Unmarshal([]byte(entry.Payload), &txReq); err != nil {
+		return nil, errors.New("quarantined payload is corrupt")
+	}
+
+	postingActor := domain.Actor{
+		UserID:    *entry.UserID,
+		Username:  actor.Username,
+		DeviceID:  entry.DeviceID,
+		IPAddress: actor.IPAddress,
+		RequestID: actor.RequestID,
+	}
+
+	// The sale and the review commit together, so an entry can't be posted
+	// twice or left pending after its sale went through
+	transaction, _, err := s.transactionService.CreateWithTx(&txReq, postingActor, func(tx *gorm.DB, transaction *domain.Transaction) error {
+		return s.markReviewed(tx, entry, "approved", req.Notes, &transaction.ID, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (s *quarantineService) Reject(id string, req *dto.ReviewQuarantineRequest, actor domain.Actor) (*dto.QuarantinedTransactionResponse, error) {
+	entry, err := s.findEntry(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return s.markReviewed(tx, entry, "rejected", req.Notes, nil, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toQuarantineResponse(entry), nil
+}
+
+// Helper functions
+
+func (s *quarantineService) findEntry(id string, mustBePending bool) (*domain.QuarantinedTransaction, error) {
+	entryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid quarantine ID format")
+	}
+
+	entry, err := s.quarantineRepo.FindByID(entryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quarantined transaction not found")
+		}
+		return nil, err
+	}
+
+	if mustBePending && entry.Status != "pending" {
+		return nil, errors.New("quarantined transaction has already been reviewed")
+	}
+
+	return entry, nil
+}
+
+func (s *quarantineService) markReviewed(tx *gorm.DB, entry *domain.QuarantinedTransaction, status, notes string, transactionID *uuid.UUID, actor domain.Actor) error {
+	before := map[string]interface{}{"status": entry.Status}
+
+	now := time.Now()
+	entry.Status = status
+	entry.ReviewNotes = notes
+	entry.ReviewedAt = &now
+	entry.TransactionID = transactionID
+	if actor.UserID != uuid.Nil {
+		reviewerID := actor.UserID
+		entry.ReviewedBy = &reviewerID
+	}
+
+	if err := s.quarantineRepo.ReviewTx(tx, entry); err != nil {
+		return err
+	}
+
+	after := map[string]interface{}{"status": entry.Status, "review_notes": entry.ReviewNotes}
+	if err := s.auditService.RecordTx(tx, actor, status, "quarantined_transaction", entry.ID.String(), before, after); err != nil {
+		return fmt.Errorf("failed to record audit log: %v", err)
+	}
+
+	return nil
+}
+
+func (s *quarantineService) toQuarantineResponse(entry *domain.QuarantinedTransaction) *dto.QuarantinedTransactionResponse {
+	response := &dto.QuarantinedTransactionResponse{
+		ID:                  entry.ID.String(),
+		ClientTransactionID: entry.ClientTransactionID,
+		Payload:             json.RawMessage(entry.Payload),
+		SigningKeyID:        entry.SigningKeyID,
+		Reason:              entry.Reason,
+		Status:              entry.Status,
+		ReviewedAt:          formatOptionalTime(entry.ReviewedAt),
+		ReviewNotes:         entry.ReviewNotes,
+		CreatedAt:           entry.CreatedAt.Format(time.RFC3339),
+	}
+
+	if entry.DeviceID != nil {
+		response.DeviceID = entry.DeviceID.String()
+	}
+	if entry.UserID != nil {
+		response.UserID = entry.UserID.String()
+	}
+	if entry.ReviewedBy != nil {
+		response.ReviewedBy = entry.ReviewedBy.String()
+	}
+	if entry.TransactionID != nil {
+		response.TransactionID = entry.TransactionID.String()
+	}
+
+	return response
+}
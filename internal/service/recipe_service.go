@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecipeService manages the bills of materials of composite products. Sales
+// of a product with an active recipe take the components out of stock, see
+// TransactionService.Create.
+type RecipeService interface {
+	GetAll(page, limit int) ([]*dto.RecipeResponse, int64, error)
+	GetByProduct(productID string) (*dto.RecipeResponse, error)
+	Save(productID string, req *dto.SaveRecipeRequest, actor domain.Actor) (*dto.RecipeResponse, error)
+	Delete(productID string, actor domain.Actor) error
+}
+
+type recipeService struct {
+	recipeRepo   domain.RecipeRepository
+	productRepo  domain.ProductRepository
+	auditService AuditService
+	db           *gorm.DB
+}
+
+func NewRecipeService(recipeRepo domain.RecipeRepository, productRepo domain.ProductRepository, auditService AuditService, db *gorm.DB) RecipeService {
+	return &recipeService{
+		recipeRepo:   recipeRepo,
+		productRepo:  productRepo,
+		auditService: auditService,
+		db:           db,
+	}
+}
+
+func (s *recipeService) GetAll(page, limit int) ([]*dto.RecipeResponse, int64, error) {
+	recipes, total, err := s.recipeRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.RecipeResponse
+	for i := range recipes {
+		responses = append(responses, toRecipeResponse(&recipes[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *recipeService) GetByProduct(productID string) (*dto.RecipeResponse, error) {
+	product, err := s.findProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.recipeRepo.FindByProduct(product.ID)
+	if err != nil {
+		return nil, errors.New("recipe not found")
+	}
+
+	return toRecipeResponse(recipe), nil
+}
+
+func (s *recipeService) Save(productID string, req *dto.SaveRecipeRequest, actor domain.Actor) (*dto.RecipeResponse, error) {
+	product, err := s.findProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.IsBundle {
+		return nil, errors.New("bundles take stock from their items and cannot have a recipe")
+	}
+	inBundle, err := s.productRepo.IsBundleComponent(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if inBundle {
+		return nil, errors.New("product is in a bundle, which takes its stock directly, and cannot have a recipe")
+	}
+
+	// Recipes don't nest, a component is always taken from its own stock
+	isComponent, err := s.recipeRepo.IsComponent(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if isComponent {
+		return nil, errors.New("product is a component of another recipe")
+	}
+
+	recipe := &domain.Recipe{ProductID: product.ID}
+	var before *domain.Recipe
+	existing, err := s.recipeRepo.FindByProduct(product.ID)
+	if err == nil {
+		snapshot := *existing
+		snapshot.Product = nil
+		before = &snapshot
+		recipe = existing
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	recipe.YieldQuantity = req.YieldQuantity
+	if recipe.YieldQuantity == 0 {
+		recipe.YieldQuantity = 1
+	}
+	recipe.WastePercent = req.WastePercent
+	recipe.IsActive = req.IsActive == nil || *req.IsActive
+	recipe.Notes = req.Notes
+	recipe.Product = nil
+	recipe.Components = nil
+
+	seen := make(map[uuid.UUID]bool)
+	for _, componentReq := range req.Components {
+		component, err := s.findProduct(componentReq.ComponentID)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %v", componentReq.ComponentID, err)
+		}
+		if component.ID == product.ID {
+			return nil, errors.New("a product cannot be a component of itself")
+		}
+		if component.IsBundle {
+			return nil, fmt.Errorf("component %s is a bundle", component.Name)
+		}
+		if seen[component.ID] {
+			return nil, fmt.Errorf("component %s is listed twice", component.Name)
+		}
+		if _, err := s.recipeRepo.FindByProduct(component.ID); err == nil {
+			return nil, fmt.Errorf("component %s has its own recipe", component.Name)
+		}
+		seen[component.ID] = true
+
+		recipeComponent := domain.RecipeComponent{
+			ComponentID: component.ID,
+			Quantity:    componentReq.Quantity,
+		}
+		// Stock is whole units and every sold line rounds up, so a fraction
+		// of a unit per portion would take a whole unit each time
+		if recipe.PortionQuantity(recipeComponent) < 1-1e-9 {
+			return nil, fmt.Errorf("component %s takes less than one unit per portion, count it in smaller units or lower the yield", component.Name)
+		}
+		recipe.Components = append(recipe.Components, recipeComponent)
+	}
+
+	action := "create"
+	if before != nil {
+		action = "update"
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.recipeRepo.WithTx(tx).Save(recipe); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, action, "recipe", recipe.ID.String(), before, recipe)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetByProduct(product.ID.String())
+}
+
+func (s *recipeService) Delete(productID string, actor domain.Actor) error {
+	product, err := s.findProduct(productID)
+	if err != nil {
+		return err
+	}
+
+	recipe, err := s.recipeRepo.FindByProduct(product.ID)
+	if err != nil {
+		return errors.New("recipe not found")
+	}
+
+	recipe.Product = nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.recipeRepo.WithTx(tx).Delete(product.ID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "recipe", recipe.ID.String(), recipe, nil)
+	})
+}
+
+// Helper functions
+
+func (s *recipeService) findProduct(id string) (*domain.Product, error) {
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid product ID format")
+	}
+
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	return product, nil
+}
+
+func toRecipeResponse(recipe *domain.Recipe) *dto.RecipeResponse {
+	response := &dto.RecipeResponse{
+		ID:            recipe.ID.String(),
+		ProductID:     recipe.ProductID.String(),
+		YieldQuantity: recipe.YieldQuantity,
+		WastePercent:  recipe.WastePercent,
+		IsActive:      recipe.IsActive,
+		Notes:         recipe.Notes,
+		Components:    []dto.RecipeComponentResponse{},
+		CreatedAt:     recipe.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     recipe.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if recipe.Product != nil {
+		response.ProductName = recipe.Product.Name
+	}
+
+	yield := int64(recipe.YieldQuantity)
+	if yield <= 0 {
+		yield = 1
+	}
+
+	var total money.Money
+	for _, component := range recipe.Components {
+		componentResponse := dto.RecipeComponentResponse{
+			ComponentID: component.ComponentID.String(),
+			Quantity:    component.Quantity,
+		}
+		if component.Component != nil {
+			componentResponse.ComponentName = component.Component.Name
+			componentResponse.UnitCost = component.Component.Cost
+			componentResponse.Stock = component.Component.Stock
+			componentResponse.PortionCost = component.Component.Cost.Mul(int64(component.Quantity)).
+				Percent(100+recipe.WastePercent, money.RoundHalfUp).
+				Div(yield, money.RoundHalfUp)
+		}
+		total = total.Add(componentResponse.PortionCost)
+		response.Components = append(response.Components, componentResponse)
+	}
+	response.EstimatedUnitCost = total
+
+	return response
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"log"
+	"math"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reorder statuses, most urgent first
+const (
+	ReorderStatusOutOfStock = "out_of_stock"
+	ReorderStatusBelowMin   = "below_min"
+	ReorderStatusReorder    = "reorder"
+	ReorderStatusOK         = "ok"
+)
+
+// Defaults used when the reorder settings are missing
+const (
+	defaultReorderLeadTimeDays    = 7
+	defaultReorderSafetyStockDays = 3
+	defaultReorderReviewDays      = 14
+	defaultReorderVelocityDays    = 28
+)
+
+type ReorderService interface {
+	// GetSuggestions works out velocity, days of cover and order quantities for
+	// active products, optionally only those that need reordering
+	GetSuggestions(categoryID *uuid.UUID, onlyReorder bool) ([]dto.ReorderSuggestionResponse, error)
+	// CheckStockLevel sends a low-stock alert when stock has just dropped to or
+	// below MinStock. Call it once the stock change is committed.
+	CheckStockLevel(product *domain.Product, previousStock int)
+}
+
+type reorderService struct {
+	productRepo    domain.ProductRepository
+	inventoryRepo  domain.InventoryRepository
+	recipeRepo     domain.RecipeRepository
+	settingService *SettingService
+	notifier       StockAlertNotifier
+}
+
+func NewReorderService(productRepo domain.ProductRepository, inventoryRepo domain.InventoryRepository, recipeRepo domain.RecipeRepository, settingService *SettingService, notifier StockAlertNotifier) ReorderService {
+	return &reorderService{
+		productRepo:    productRepo,
+		inventoryRepo:  inventoryRepo,
+		recipeRepo:     recipeRepo,
+		settingService: settingService,
+		notifier:       notifier,
+	}
+}
+
+// reorderPolicy holds the store-wide reorder settings
+type reorderPolicy struct {
+	leadTimeDays    int
+	safetyStockDays int
+	reviewDays      int
+	velocityDays    int
+}
+
+func (s *reorderService) GetSuggestions(categoryID *uuid.UUID, onlyReorder bool) ([]dto.ReorderSuggestionResponse, error) {
+	products, err := s.productRepo.FindAllActive(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := s.policy()
+	sold, err := s.inventoryRepo.SoldQuantities(time.Now().AddDate(0, 0, -policy.velocityDays), nil)
+	if err != nil {
+		return nil, err
+	}
+	soldByProduct := make(map[uuid.UUID]int64, len(sold))
+	for _, row := range sold {
+		soldByProduct[row.ProductID] = row.Quantity
+	}
+
+	recipeProductIDs, err := s.recipeRepo.ActiveProductIDs()
+	if err != nil {
+		return nil, err
+	}
+	hasRecipe := make(map[uuid.UUID]bool, len(recipeProductIDs))
+	for _, id := range recipeProductIDs {
+		hasRecipe[id] = true
+	}
+
+	suggestions := []dto.ReorderSuggestionResponse{}
+	for i := range products {
+		// Bundles and recipe products are never bought in, their items are
+		if products[i].IsBundle || hasRecipe[products[i].ID] {
+			continue
+		}
+		suggestion := suggestReorder(&products[i], soldByProduct[products[i].ID], policy)
+		if onlyReorder && suggestion.Status == ReorderStatusOK {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// Most urgent first, then whatever runs out soonest
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if reorderUrgency(a.Status) != reorderUrgency(b.Status) {
+			return reorderUrgency(a.Status) < reorderUrgency(b.Status)
+		}
+		if (a.DaysOfCover == nil) != (b.DaysOfCover == nil) {
+			return a.DaysOfCover != nil
+		}
+		if a.DaysOfCover != nil && *a.DaysOfCover != *b.DaysOfCover {
+			return *a.DaysOfCover < *b.DaysOfCover
+		}
+		return a.Stock < b.Stock
+	})
+
+	return suggestions, nil
+}
+
+func (s *reorderService) CheckStockLevel(product *domain.Product, previousStock int) {
+	if previousStock <= product.MinStock || product.Stock > product.MinStock {
+		return
+	}
+
+	alert := LowStockAlert{
+		ProductID:     product.ID,
+		ProductName:   product.Name,
+		SKU:           product.SKU,
+		PreviousStock: previousStock,
+		Stock:         product.Stock,
+		MinStock:      product.MinStock,
+		OccurredAt:    time.Now(),
+	}
+
+	policy := s.policy()
+	sold, err := s.inventoryRepo.SoldQuantities(time.Now().AddDate(0, 0, -policy.velocityDays), &product.ID)
+	if err != nil {
+		log.Printf("Warning: failed to compute reorder quantity for %s: %v", product.SKU, err)
+	} else {
+		var quantity int64
+		if len(sold) > 0 {
+			quantity = sold[0].Quantity
+		}
+		alert.SuggestedQuantity = suggestReorder(product, quantity, policy).SuggestedQuantity
+	}
+
+	if err := s.notifier.NotifyLowStock(alert); err != nil {
+		log.Printf("Warning: failed to send low stock alert for %s: %v", product.SKU, err)
+	}
+}
+
+// Helper functions
+
+func (s *reorderService) policy() reorderPolicy {
+	policy := reorderPolicy{
+		leadTimeDays:    s.settingService.IntSetting("reorder_lead_time_days", defaultReorderLeadTimeDays),
+		safetyStockDays: s.settingService.IntSetting("reorder_safety_stock_days", defaultReorderSafetyStockDays),
+		reviewDays:      s.settingService.IntSetting("reorder_review_days", defaultReorderReviewDays),
+		velocityDays:    s.settingService.IntSetting("reorder_velocity_days", defaultReorderVelocityDays),
+	}
+	if policy.velocityDays < 1 {
+		policy.velocityDays = defaultReorderVelocityDays
+	}
+	return policy
+}
+
+// suggestReorder applies a reorder-point policy: reorder once stock can't cover
+// sales over the lead time plus safety stock, and order enough to also cover the
+// review period. MinStock acts as a floor for the reorder point.
+func suggestReorder(product *domain.Product, sold int64, policy reorderPolicy) dto.ReorderSuggestionResponse {
+	leadTimeDays := product.LeadTimeDays
+	if leadTimeDays <= 0 {
+		leadTimeDays = policy.leadTimeDays
+	}
+
+	velocity := 0.0
+	if sold > 0 {
+		velocity = float64(sold) / float64(policy.velocityDays)
+	}
+
+	safetyStock := int(math.Ceil(velocity * float64(policy.safetyStockDays)))
+	reorderPoint := int(math.Ceil(velocity*float64(leadTimeDays))) + safetyStock
+	if reorderPoint < product.MinStock {
+		reorderPoint = product.MinStock
+	}
+
+	suggestion := dto.ReorderSuggestionResponse{
+		ProductID:     product.ID.String(),
+		ProductName:   product.Name,
+		SKU:           product.SKU,
+		Stock:         product.Stock,
+		MinStock:      product.MinStock,
+		DailyVelocity: math.Round(velocity*100) / 100,
+		LeadTimeDays:  leadTimeDays,
+		SafetyStock:   safetyStock,
+		ReorderPoint:  reorderPoint,
+		Status:        ReorderStatusOK,
+	}
+
+	if velocity > 0 {
+		cover := 0.0
+		if product.Stock > 0 {
+			cover = math.Round(float64(product.Stock)/velocity*10) / 10
+		}
+		suggestion.DaysOfCover = &cover
+	}
+
+	switch {
+	case product.Stock <= 0:
+		suggestion.Status = ReorderStatusOutOfStock
+	case product.Stock <= product.MinStock:
+		suggestion.Status = ReorderStatusBelowMin
+	case product.Stock <= reorderPoint:
+		suggestion.Status = ReorderStatusReorder
+	}
+
+	// Without sales or a minimum there is no demand signal to order against
+	if suggestion.Status != ReorderStatusOK && (velocity > 0 || product.MinStock > 0) {
+		target := int(math.Ceil(velocity*float64(leadTimeDays+policy.reviewDays))) + safetyStock
+		if target <= reorderPoint {
+			target = reorderPoint + 1
+		}
+		suggestion.SuggestedQuantity = target - product.Stock
+		suggestion.EstimatedCost = product.Cost.Mul(int64(suggestion.SuggestedQuantity))
+	}
+
+	return suggestion
+}
+
+func reorderUrgency(status string) int {
+	switch status {
+	case ReorderStatusOutOfStock:
+		return 0
+	case ReorderStatusBelowMin:
+		return 1
+	case ReorderStatusReorder:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"pos-backend/internal/domain"
+	"pos-backend/pkg/money"
+	"testing"
+)
+
+func TestSuggestReorder(t *testing.T) {
+	// 30 days of history, so 60 units sold is a velocity of 2 a day
+	policy := reorderPolicy{leadTimeDays: 3, safetyStockDays: 2, reviewDays: 7, velocityDays: 30}
+
+	tests := []struct {
+		name             string
+		stock            int
+		minStock         int
+		leadTimeDays     int
+		sold             int64
+		wantStatus       string
+		wantLeadTime     int
+		wantReorderPoint int
+		wantQuantity     int
+		wantCover        *float64
+	}{
+		{name: "no sales and no minimum", stock: 5, wantStatus: ReorderStatusOK, wantLeadTime: 3},
+		{name: "out of stock without sales or a minimum suggests nothing", stock: 0, wantStatus: ReorderStatusOutOfStock, wantLeadTime: 3},
+		{name: "at the minimum without sales orders past it", stock: 10, minStock: 10, wantStatus: ReorderStatusBelowMin, wantLeadTime: 3, wantReorderPoint: 10, wantQuantity: 1},
+		{name: "at the minimum with sales", stock: 10, minStock: 10, sold: 60, wantStatus: ReorderStatusBelowMin, wantLeadTime: 3, wantReorderPoint: 10, wantQuantity: 14, wantCover: cover(5)},
+		{name: "above the reorder point", stock: 11, sold: 60, wantStatus: ReorderStatusOK, wantLeadTime: 3, wantReorderPoint: 10, wantCover: cover(5.5)},
+		{name: "at the reorder point", stock: 10, sold: 60, wantStatus: ReorderStatusReorder, wantLeadTime: 3, wantReorderPoint: 10, wantQuantity: 14, wantCover: cover(5)},
+		{name: "zero stock", stock: 0, sold: 60, wantStatus: ReorderStatusOutOfStock, wantLeadTime: 3, wantReorderPoint: 10, wantQuantity: 24, wantCover: cover(0)},
+		{name: "oversold stock is made up too", stock: -3, sold: 60, wantStatus: ReorderStatusOutOfStock, wantLeadTime: 3, wantReorderPoint: 10, wantQuantity: 27, wantCover: cover(0)},
+		{name: "product lead time overrides the setting", stock: 20, leadTimeDays: 10, sold: 60, wantStatus: ReorderStatusReorder, wantLeadTime: 10, wantReorderPoint: 24, wantQuantity: 18, wantCover: cover(10)},
+		{name: "minimum raises the reorder point", stock: 15, minStock: 20, sold: 60, wantStatus: ReorderStatusBelowMin, wantLeadTime: 3, wantReorderPoint: 20, wantQuantity: 9, wantCover: cover(7.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := &domain.Product{
+				Name:         "Arabica beans",
+				Stock:        tt.stock,
+				MinStock:     tt.minStock,
+				LeadTimeDays: tt.leadTimeDays,
+				Cost:         money.MustParse("1500"),
+			}
+
+			got := suggestReorder(product, tt.sold, policy)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.LeadTimeDays != tt.wantLeadTime || got.ReorderPoint != tt.wantReorderPoint {
+				t.Errorf("lead time %d reorder point %d, want %d and %d", got.LeadTimeDays, got.ReorderPoint, tt.wantLeadTime, tt.wantReorderPoint)
+			}
+			if got.SuggestedQuantity != tt.wantQuantity {
+				t.Errorf("suggested quantity = %d, want %d", got.SuggestedQuantity, tt.wantQuantity)
+			}
+			if want := product.Cost.Mul(int64(tt.wantQuantity)); got.EstimatedCost != want {
+				t.Errorf("estimated cost = %s, want %s", got.EstimatedCost, want)
+			}
+			if (got.DaysOfCover == nil) != (tt.wantCover == nil) || (got.DaysOfCover != nil && *got.DaysOfCover != *tt.wantCover) {
+				t.Errorf("days of cover = %v, want %v", formatCover(got.DaysOfCover), formatCover(tt.wantCover))
+			}
+		})
+	}
+}
+
+func cover(days float64) *float64 {
+	return &days
+}
+
+func formatCover(days *float64) interface{} {
+	if days == nil {
+		return "none"
+	}
+	return *days
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"log"
+	"pos-backend/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SalesSummaryService keeps the daily sales summaries that closed-day reports
+// read. Sales are booked on the business day they were made, so a cancellation
+// takes the sale back out of that day rather than the day it was cancelled.
+type SalesSummaryService interface {
+	// RecordSaleTx and RecordCancellationTx update the summaries inside the
+	// caller's database transaction, so they commit or roll back with the sale
+	RecordSaleTx(tx *gorm.DB, transaction *domain.Transaction) error
+	RecordCancellationTx(tx *gorm.DB, transaction *domain.Transaction) error
+	// Rebuild recomputes the inclusive YYYY-MM-DD business dates from the
+	// transactions; empty dates leave that side of the range open
+	Rebuild(startDate, endDate string) error
+	// EnsureBuilt builds the summaries from history when none exist yet
+	EnsureBuilt() error
+}
+
+type salesSummaryService struct {
+	summaryRepo    domain.SalesSummaryRepository
+	settingService *SettingService
+}
+
+func NewSalesSummaryService(summaryRepo domain.SalesSummaryRepository, settingService *SettingService) SalesSummaryService {
+	return &salesSummaryService{summaryRepo: summaryRepo, settingService: settingService}
+}
+
+func (s *salesSummaryService) RecordSaleTx(tx *gorm.DB, transaction *domain.Transaction) error {
+	summary, products := s.summarize(transaction, 1)
+	return s.summaryRepo.AddTx(tx, summary, products)
+}
+
+func (s *salesSummaryService) RecordCancellationTx(tx *gorm.DB, transaction *domain.Transaction) error {
+	summary, products := s.summarize(transaction, -1)
+	summary.CancelledCount = 1
+	summary.CancelledAmount = transaction.FinalAmount
+	return s.summaryRepo.AddTx(tx, summary, products)
+}
+
+func (s *salesSummaryService) Rebuild(startDate, endDate string) error {
+	calendar := s.settingService.BusinessCalendar()
+	start, end, err := calendar.Range(startDate, endDate)
+	if err != nil {
+		return err
+	}
+	return s.summaryRepo.Rebuild(calendar, start, end)
+}
+
+func (s *salesSummaryService) EnsureBuilt() error {
+	empty, err := s.summaryRepo.IsEmpty()
+	if err != nil || !empty {
+		return err
+	}
+
+	log.Println("Building daily sales summaries from transaction history")
+	return s.Rebuild("", "")
+}
+
+// Helper functions
+
+// summarize turns a transaction into summary rows, with sign 1 adding the sale
+// and -1 taking it back out
+func (s *salesSummaryService) summarize(transaction *domain.Transaction, sign int64) (domain.DailySalesSummary, []domain.DailyProductSales) {
+	businessDate := s.settingService.BusinessCalendar().BusinessDate(transaction.CreatedAt)
+	storeID := uuid.Nil
+	if transaction.StoreID != nil {
+		storeID = *transaction.StoreID
+	}
+
+	summary := domain.DailySalesSummary{
+		BusinessDate:     businessDate,
+		StoreID:          storeID,
+		PaymentMethod:    transaction.PaymentMethod,
+		TransactionCount: sign,
+		TotalAmount:      transaction.TotalAmount.Mul(sign),
+		DiscountAmount:   transaction.DiscountAmount.Mul(sign),
+		TaxAmount:        transaction.TaxAmount.Mul(sign),
+		Revenue:          transaction.FinalAmount.Mul(sign),
+	}
+	if !transaction.RoundingAmount.IsZero() {
+		summary.RoundedCount = sign
+		if transaction.RoundingAmount.IsNegative() {
+			summary.RoundingLoss = transaction.RoundingAmount.Neg().Mul(sign)
+		} else {
+			summary.RoundingGain = transaction.RoundingAmount.Mul(sign)
+		}
+	}
+
+	// A sale may list the same product on several lines
+	type productKey struct {
+		productID    uuid.UUID
+		name         string
+		categoryID   uuid.UUID
+		categoryName string
+	}
+	index := make(map[productKey]int)
+	products := []domain.DailyProductSales{}
+	for _, item := range transaction.Items {
+		summary.ItemsSold += int64(item.Quantity) * sign
+
+		key := productKey{productID: uuid.Nil, name: item.ProductName}
+		if item.ProductID != nil {
+			key.productID = *item.ProductID
+		}
+		if item.CategoryID != nil {
+			key.categoryID = *item.CategoryID
+			key.categoryName = item.CategoryName
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(products)
+			index[key] = i
+			products = append(products, domain.DailyProductSales{
+				BusinessDate: businessDate,
+				StoreID:      storeID,
+				ProductID:    key.productID,
+				ProductName:  item.ProductName,
+				CategoryID:   key.categoryID,
+				CategoryName: key.categoryName,
+			})
+		}
+		products[i].Quantity += int64(item.Quantity) * sign
+		products[i].Revenue = products[i].Revenue.Add(item.Subtotal.Mul(sign))
+		products[i].Cost = products[i].Cost.Add(item.TotalCost.Mul(sign))
+		if item.ProductPrice.Add(item.ModifierAmount).Cmp(item.UnitCost) < 0 {
+			products[i].BelowCostLines += sign
+		}
+	}
+
+	return summary, products
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"pos-backend/internal/domain"
+	"pos-backend/pkg/businessday"
+	"regexp"
+)
+
+// Setting value types
+const (
+	SettingTypeString = "string"
+	SettingTypeInt    = "int"
+	SettingTypeNumber = "number"
+	SettingTypeBool   = "bool"
+)
+
+// SettingDefinition describes a setting key: its type, the values it accepts,
+// its default and which roles may change it
+type SettingDefinition struct {
+	Key         string
+	Category    string
+	Type        string
+	Default     interface{}
+	Min         *float64 // Numbers: smallest value; strings: shortest length
+	Max         *float64 // Numbers: largest value; strings: longest length
+	Enum        []string
+	Check       func(value interface{}) error // Extra rule beyond type, range and enum
+	EditRoles   []string
+	Scopes      []string // Levels below global that may override it
+	Description string
+}
+
+// Roles that may change a setting
+var (
+	adminOnly       = []string{"admin"}
+	adminAndManager = []string{"admin", "manager"}
+)
+
+// Levels a setting may be overridden at. Settings that must agree across the
+// business, like the timezone or costing method, are global only.
+var (
+	storeScope          = []string{domain.SettingScopeStore}
+	storeAndDeviceScope = []string{domain.SettingScopeStore, domain.SettingScopeDevice}
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// What the service charge is worked out on
+const (
+	ServiceChargeBeforeDiscount = "before_discount"
+	ServiceChargeAfterDiscount  = "after_discount"
+)
+
+// How cash totals are rounded to the rounding unit
+const (
+	CashRoundingNearest = "nearest"
+	CashRoundingDown    = "down"
+	CashRoundingUp      = "up"
+)
+
+// settingSchema lists every known setting, in the order they are shown
+var settingSchema = []SettingDefinition{
+	// Store settings
+	{Key: "store_name", Category: "store", Type: SettingTypeString, Default: "My POS Store", Min: bound(1), Max: bound(255), EditRoles: adminAndManager, Scopes: storeScope, Description: "Name printed on receipts and exports"},
+	{Key: "store_address", Category: "store", Type: SettingTypeString, Default: "Jl. Example No. 123, Jakarta", Max: bound(500), EditRoles: adminAndManager, Scopes: storeScope, Description: "Address printed on receipts"},
+	{Key: "store_phone", Category: "store", Type: SettingTypeString, Default: "021-12345678", Max: bound(50), EditRoles: adminAndManager, Scopes: storeScope, Description: "Phone number printed on receipts"},
+	{Key: "store_email", Category: "store", Type: SettingTypeString, Default: "store@example.com", Max: bound(255), Check: checkEmail, EditRoles: adminAndManager, Scopes: storeScope, Description: "Contact email, may be empty"},
+	{Key: "timezone", Category: "store", Type: SettingTypeString, Default: defaultTimezone, Check: checkTimezone, EditRoles: adminOnly, Description: "IANA timezone business days and report hours are counted in"},
+	{Key: "business_day_cutoff", Category: "store", Type: SettingTypeString, Default: "00:00", Check: checkCutoff, EditRoles: adminOnly, Description: "HH:MM before which sales count towards the previous day"},
+
+	// Tax settings
+	{Key: "tax_enabled", Category: "tax", Type: SettingTypeBool, Default: true, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether tax is charged, off sells everything without tax"},
+	{Key: "tax_rate", Category: "tax", Type: SettingTypeNumber, Default: 10, Min: bound(0), Max: bound(100), EditRoles: adminOnly, Scopes: storeScope, Description: "Tax rate in percent for products without a tax class, added on top of the price"},
+	{Key: "tax_label", Category: "tax", Type: SettingTypeString, Default: "PPN", Min: bound(1), Max: bound(20), EditRoles: adminOnly, Scopes: storeScope, Description: "Tax name shown on receipts"},
+	{Key: "currency", Category: "tax", Type: SettingTypeString, Default: "IDR", Check: checkCurrency, EditRoles: adminOnly, Description: "ISO 4217 currency code"},
+	{Key: "currency_symbol", Category: "tax", Type: SettingTypeString, Default: "Rp", Min: bound(1), Max: bound(5), EditRoles: adminOnly, Description: "Symbol shown before amounts"},
+
+	// Service charge and cash rounding settings
+	{Key: "service_charge_enabled", Category: "charges", Type: SettingTypeBool, Default: false, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether a service charge is added to sales"},
+	{Key: "service_charge_rate", Category: "charges", Type: SettingTypeNumber, Default: 5, Min: bound(0), Max: bound(100), EditRoles: adminOnly, Scopes: storeScope, Description: "Service charge in percent"},
+	{Key: "service_charge_base", Category: "charges", Type: SettingTypeString, Default: ServiceChargeAfterDiscount, Enum: []string{ServiceChargeBeforeDiscount, ServiceChargeAfterDiscount}, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether the service charge is worked out before or after the discount"},
+	{Key: "service_charge_taxable", Category: "charges", Type: SettingTypeBool, Default: true, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether tax is charged on the service charge"},
+	{Key: "cash_rounding_enabled", Category: "charges", Type: SettingTypeBool, Default: false, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether cash totals are rounded to a coin denomination"},
+	{Key: "cash_rounding_unit", Category: "charges", Type: SettingTypeInt, Default: 100, Check: checkCashRoundingUnit, EditRoles: adminOnly, Scopes: storeScope, Description: "Denomination cash totals are rounded to"},
+	{Key: "cash_rounding_mode", Category: "charges", Type: SettingTypeString, Default: CashRoundingNearest, Enum: []string{CashRoundingNearest, CashRoundingDown, CashRoundingUp}, EditRoles: adminOnly, Scopes: storeScope, Description: "Whether cash totals round to the nearest unit, down or up"},
+
+	// Inventory settings
+	{Key: "costing_method", Category: "inventory", Type: SettingTypeString, Default: domain.CostingMethodAverage, Enum: []string{domain.CostingMethodAverage, domain.CostingMethodFIFO}, EditRoles: adminOnly, Description: "How cost of goods sold is valued"},
+	{Key: "reorder_lead_time_days", Category: "inventory", Type: SettingTypeInt, Default: defaultReorderLeadTimeDays, Min: bound(0), Max: bound(365), EditRoles: adminAndManager, Description: "Supplier lead time for products without their own"},
+	{Key: "reorder_safety_stock_days", Category: "inventory", Type: SettingTypeInt, Default: defaultReorderSafetyStockDays, Min: bound(0), Max: bound(365), EditRoles: adminAndManager, Description: "Extra days of sales kept as buffer"},
+	{Key: "reorder_review_days", Category: "inventory", Type: SettingTypeInt, Default: defaultReorderReviewDays, Min: bound(1), Max: bound(365), EditRoles: adminAndManager, Description: "Days of sales each order should cover"},
+	{Key: "reorder_velocity_days", Category: "inventory", Type: SettingTypeInt, Default: defaultReorderVelocityDays, Min: bound(1), Max: bound(365), EditRoles: adminAndManager, Description: "Sales history used to measure velocity"},
+	{Key: "hold_reservation_minutes", Category: "inventory", Type: SettingTypeInt, Default: defaultHoldReservationMinutes, Min: bound(1), Max: bound(1440), EditRoles: adminAndManager, Description: "How long a held order may reserve its stock"},
+
+	// Receipt settings
+	{Key: "show_logo", Category: "receipt", Type: SettingTypeBool, Default: true, EditRoles: adminAndManager, Scopes: storeAndDeviceScope, Description: "Print the logo on receipts"},
+	{Key: "show_address", Category: "receipt", Type: SettingTypeBool, Default: true, EditRoles: adminAndManager, Scopes: storeAndDeviceScope, Description: "Print the store address on receipts"},
+	{Key: "show_phone", Category: "receipt", Type: SettingTypeBool, Default: true, EditRoles: adminAndManager, Scopes: storeAndDeviceScope, Description: "Print the store phone on receipts"},
+	{Key: "footer_text", Category: "receipt", Type: SettingTypeString, Default: "Terima kasih atas kunjungan Anda!", Max: bound(500), EditRoles: adminAndManager, Scopes: storeAndDeviceScope, Description: "Text printed at the bottom of receipts"},
+
+	// System settings
+	{Key: "auto_sync_enabled", Category: "system", Type: SettingTypeBool, Default: true, EditRoles: adminOnly, Scopes: storeAndDeviceScope, Description: "Whether terminals sync offline sales automatically"},
+	{Key: "sync_interval", Category: "system", Type: SettingTypeInt, Default: 5, Min: bound(1), Max: bound(1440), EditRoles: adminOnly, Scopes: storeAndDeviceScope, Description: "Minutes between automatic syncs"},
+	{Key: "offline_mode_enabled", Category: "system", Type: SettingTypeBool, Default: true, EditRoles: adminOnly, Scopes: storeAndDeviceScope, Description: "Whether terminals may sell while offline"},
+	{Key: "theme", Category: "system", Type: SettingTypeString, Default: "light", Enum: []string{"light", "dark", "system"}, EditRoles: adminAndManager, Scopes: storeAndDeviceScope, Description: "Client colour theme"},
+}
+
+// settingDefinition looks up a key in the schema
+func settingDefinition(key string) (SettingDefinition, bool) {
+	for _, definition := range settingSchema {
+		if definition.Key == key {
+			return definition, true
+		}
+	}
+	return SettingDefinition{}, false
+}
+
+// CanEdit reports whether role may change the setting
+func (d SettingDefinition) CanEdit(role string) bool {
+	for _, editRole := range d.EditRoles {
+		if editRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanOverride reports whether the setting may be overridden at scope
+func (d SettingDefinition) CanOverride(scope string) bool {
+	for _, allowed := range d.Scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a decoded JSON value against the definition and returns it
+// normalised, with whole numbers as int64 for int settings
+func (d SettingDefinition) Validate(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case SettingTypeBool:
+		if _, ok := value.(bool); !ok {
+			return nil, fmt.Errorf("must be true or false")
+		}
+	case SettingTypeInt, SettingTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		if d.Type == SettingTypeInt && number != math.Trunc(number) {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		if d.Min != nil && number < *d.Min {
+			return nil, fmt.Errorf("must be at least %v", *d.Min)
+		}
+		if d.Max != nil && number > *d.Max {
+			return nil, fmt.Errorf("must be at most %v", *d.Max)
+		}
+		if d.Type == SettingTypeInt {
+			value = int64(number)
+		}
+	case SettingTypeString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		length := float64(len([]rune(text)))
+		if d.Min != nil && length < *d.Min {
+			return nil, fmt.Errorf("must be at least %v characters", *d.Min)
+		}
+		if d.Max != nil && length > *d.Max {
+			return nil, fmt.Errorf("must be at most %v characters", *d.Max)
+		}
+		if len(d.Enum) > 0 {
+			allowed := false
+			for _, option := range d.Enum {
+				if text == option {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return nil, fmt.Errorf("must be one of %v", d.Enum)
+			}
+		}
+	}
+
+	if d.Check != nil {
+		if err := d.Check(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// Helper functions
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+func checkTimezone(value interface{}) error {
+	if _, err := businessday.New(value.(string), ""); err != nil || value.(string) == "" {
+		return fmt.Errorf("must be an IANA timezone such as Asia/Jakarta")
+	}
+	return nil
+}
+
+func checkCutoff(value interface{}) error {
+	if _, err := businessday.ParseCutoff(value.(string)); err != nil {
+		return fmt.Errorf("must be a time between 00:00 and 23:59 in HH:MM format")
+	}
+	return nil
+}
+
+func checkCurrency(value interface{}) error {
+	if !currencyCode.MatchString(value.(string)) {
+		return fmt.Errorf("must be a three-letter ISO 4217 code such as IDR")
+	}
+	return nil
+}
+
+func checkCashRoundingUnit(value interface{}) error {
+	if unit := value.(int64); unit != 100 && unit != 500 {
+		return fmt.Errorf("must be 100 or 500")
+	}
+	return nil
+}
+
+func checkEmail(value interface{}) error {
+	if value.(string) == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(value.(string)); err != nil {
+		return fmt.Errorf("must be a valid email address")
+	}
+	return nil
+}
@@ -2,42 +2,194 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/businessday"
+	"pos-backend/pkg/money"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// defaultTimezone is used until a store timezone is configured
+const defaultTimezone = "Asia/Jakarta"
+
 type SettingService struct {
-	repo domain.SettingRepository
+	repo         domain.SettingRepository
+	storeRepo    domain.StoreRepository
+	deviceRepo   domain.DeviceRepository
+	auditService AuditService
+	db           *gorm.DB
 }
 
-func NewSettingService(repo domain.SettingRepository) *SettingService {
-	return &SettingService{repo: repo}
+func NewSettingService(repo domain.SettingRepository, storeRepo domain.StoreRepository, deviceRepo domain.DeviceRepository, auditService AuditService, db *gorm.DB) *SettingService {
+	return &SettingService{repo: repo, storeRepo: storeRepo, deviceRepo: deviceRepo, auditService: auditService, db: db}
 }
 
-// GetSettings returns all settings grouped by category
-func (s *SettingService) GetSettings() (map[string]map[string]interface{}, error) {
-	settings, err := s.repo.GetAll()
+// SettingScope selects whose settings to resolve. Leaving StoreID empty with a
+// DeviceID uses the device's own store; leaving both empty gives the global settings.
+type SettingScope struct {
+	StoreID  *uuid.UUID
+	DeviceID *uuid.UUID
+}
+
+// GetSettings returns the settings in effect for scope grouped by category:
+// global values, overridden by the store's, overridden by the device's
+func (s *SettingService) GetSettings(scope SettingScope) (map[string]map[string]interface{}, error) {
+	_, resolved, err := s.resolve(scope)
 	if err != nil {
 		return nil, err
 	}
 
 	// Group by category
 	result := make(map[string]map[string]interface{})
-
-	for _, setting := range settings {
+	for _, setting := range resolved {
 		if result[setting.Category] == nil {
 			result[setting.Category] = make(map[string]interface{})
 		}
+		result[setting.Category][setting.Key] = setting.Value
+	}
+
+	return result, nil
+}
+
+// GetResolvedSettings lists the settings in effect for scope and which level each came from
+func (s *SettingService) GetResolvedSettings(scope SettingScope) (*dto.ResolvedSettingsResponse, error) {
+	scope, resolved, err := s.resolve(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ResolvedSettingsResponse{Settings: resolved}
+	if scope.StoreID != nil {
+		response.StoreID = scope.StoreID.String()
+	}
+	if scope.DeviceID != nil {
+		response.DeviceID = scope.DeviceID.String()
+	}
+	return response, nil
+}
+
+// UpdateOverrides sets store or device overrides, validated like global
+// settings and limited to keys the schema lets that level override. A null
+// value removes the override so the setting is inherited again. All the
+// changes are saved together as one settings version.
+func (s *SettingService) UpdateOverrides(scope string, scopeID uuid.UUID, settingsMap map[string]map[string]interface{}, role string, actor domain.Actor) error {
+	switch scope {
+	case domain.SettingScopeStore:
+		if _, err := s.storeRepo.FindByID(scopeID); err != nil {
+			return errors.New("store not found")
+		}
+	case domain.SettingScopeDevice:
+		if _, err := s.deviceRepo.FindByID(scopeID); err != nil {
+			return errors.New("device not found")
+		}
+	default:
+		return fmt.Errorf("unknown setting scope %q", scope)
+	}
+
+	var overrides []domain.SettingOverride
+	var removals []string
+	var fieldErrors []dto.SettingFieldError
+
+	for category, values := range settingsMap {
+		for key, value := range values {
+			field := category + "." + key
+			definition, ok := settingDefinition(key)
+			if !ok {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "unknown_key", Message: "unknown setting"})
+				continue
+			}
+			if definition.Category != category {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "wrong_category", Message: fmt.Sprintf("belongs to category %q", definition.Category)})
+				continue
+			}
+			if !definition.CanOverride(scope) {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "not_overridable", Message: fmt.Sprintf("cannot be overridden per %s", scope)})
+				continue
+			}
+			if !definition.CanEdit(role) {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "forbidden", Message: fmt.Sprintf("can only be changed by %s", strings.Join(definition.EditRoles, " or "))})
+				continue
+			}
+			if value == nil {
+				removals = append(removals, key)
+				continue
+			}
+			normalized, err := definition.Validate(value)
+			if err != nil {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "invalid", Message: err.Error()})
+				continue
+			}
+
+			valueBytes, err := json.Marshal(normalized)
+			if err != nil {
+				return err
+			}
+			overrides = append(overrides, domain.SettingOverride{
+				Scope:    scope,
+				ScopeID:  scopeID,
+				Key:      key,
+				Value:    string(valueBytes),
+				Category: category,
+			})
+		}
+	}
 
-		// Try to parse JSON value, otherwise use as string
-		var value interface{}
-		if err := json.Unmarshal([]byte(setting.Value), &value); err != nil {
-			value = setting.Value
+	if len(fieldErrors) > 0 {
+		sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+		return &SettingValidationError{Errors: fieldErrors}
+	}
+
+	var changes []settingOverrideChange
+	for _, override := range overrides {
+		existing, err := s.repo.FindOverride(scope, scopeID, override.Key)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if existing != nil && existing.Value == override.Value {
+			continue
 		}
+		changes = append(changes, settingOverrideChange{override: override, existing: existing})
+	}
 
-		result[setting.Category][setting.Key] = value
+	for _, key := range removals {
+		existing, err := s.repo.FindOverride(scope, scopeID, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		changes = append(changes, settingOverrideChange{override: *existing, existing: existing, remove: true})
 	}
 
-	return result, nil
+	_, err := s.saveVersion(nil, changes, domain.SettingVersionOverride, nil, actor)
+	return err
+}
+
+// GetSchema describes every known setting
+func (s *SettingService) GetSchema() []dto.SettingDefinitionResponse {
+	definitions := make([]dto.SettingDefinitionResponse, 0, len(settingSchema))
+	for _, definition := range settingSchema {
+		definitions = append(definitions, dto.SettingDefinitionResponse{
+			Key:         definition.Key,
+			Category:    definition.Category,
+			Type:        definition.Type,
+			Default:     definition.Default,
+			Min:         definition.Min,
+			Max:         definition.Max,
+			Enum:        definition.Enum,
+			EditRoles:   definition.EditRoles,
+			Description: definition.Description,
+		})
+	}
+	return definitions
 }
 
 // GetSettingByKey returns a single setting by key
@@ -45,14 +197,152 @@ func (s *SettingService) GetSettingByKey(key string) (*domain.Setting, error) {
 	return s.repo.GetByKey(key)
 }
 
-// UpdateSettings updates multiple settings
-func (s *SettingService) UpdateSettings(settingsMap map[string]map[string]interface{}) error {
+// BusinessCalendar returns the store timezone and business-day cutoff used to
+// decide which day a sale belongs to. Missing or invalid settings fall back to
+// WIB with a midnight cutoff.
+func (s *SettingService) BusinessCalendar() businessday.Calendar {
+	timezone := defaultTimezone
+	if setting, err := s.repo.GetByKey("timezone"); err == nil {
+		timezone = settingStringValue(setting.Value)
+	}
+
+	cutoff := ""
+	if setting, err := s.repo.GetByKey("business_day_cutoff"); err == nil {
+		cutoff = settingStringValue(setting.Value)
+	}
+
+	calendar, err := businessday.New(timezone, cutoff)
+	if err != nil {
+		log.Printf("Warning: %v, using %s with a midnight cutoff", err, defaultTimezone)
+		calendar, _ = businessday.New(defaultTimezone, "")
+	}
+	return calendar
+}
+
+// IntSetting reads a numeric setting, falling back when it is missing, invalid or negative
+func (s *SettingService) IntSetting(key string, fallback int) int {
+	setting, err := s.repo.GetByKey(key)
+	if err != nil {
+		return fallback
+	}
+
+	var value float64
+	if err := json.Unmarshal([]byte(setting.Value), &value); err != nil || value < 0 {
+		log.Printf("Warning: invalid %s setting %q, using %d", key, setting.Value, fallback)
+		return fallback
+	}
+	return int(value)
+}
+
+// ChargeRules are the tax, service charge and cash rounding a sale is made under
+type ChargeRules struct {
+	TaxEnabled           bool
+	TaxRate              float64 // Percent charged on lines without a tax class
+	ServiceChargeRate    float64 // Percent, 0 when there is no service charge
+	ServiceChargeBase    string  // ServiceChargeBeforeDiscount or ServiceChargeAfterDiscount
+	ServiceChargeTaxable bool
+	CashRoundingUnit     money.Money // Zero when cash totals are not rounded
+	CashRoundingMode     money.RoundingMode
+}
+
+// ChargeRules resolves the tax, service charge and cash rounding settings for
+// scope. Invalid values fall back to their defaults.
+func (s *SettingService) ChargeRules(scope SettingScope) ChargeRules {
+	_, resolved, err := s.resolve(scope)
+	if err != nil {
+		log.Printf("Warning: failed to resolve charge settings: %v, using global settings", err)
+		if _, resolved, err = s.resolve(SettingScope{}); err != nil {
+			log.Printf("Warning: failed to resolve charge settings: %v, using defaults", err)
+		}
+	}
+
+	values := make(map[string]interface{}, len(resolved))
+	for _, setting := range resolved {
+		values[setting.Key] = setting.Value
+	}
+	value := func(key string) interface{} {
+		definition, _ := settingDefinition(key)
+		if v, err := definition.Validate(values[key]); err == nil {
+			return v
+		}
+		return definition.Default
+	}
+
+	rules := ChargeRules{
+		TaxEnabled:           value("tax_enabled").(bool),
+		TaxRate:              settingNumber(value("tax_rate")),
+		ServiceChargeBase:    value("service_charge_base").(string),
+		ServiceChargeTaxable: value("service_charge_taxable").(bool),
+		CashRoundingMode:     money.RoundHalfUp,
+	}
+	if value("service_charge_enabled").(bool) {
+		rules.ServiceChargeRate = settingNumber(value("service_charge_rate"))
+	}
+	if value("cash_rounding_enabled").(bool) {
+		rules.CashRoundingUnit = money.FromInt(int64(settingNumber(value("cash_rounding_unit"))))
+		switch value("cash_rounding_mode") {
+		case CashRoundingDown:
+			rules.CashRoundingMode = money.RoundDown
+		case CashRoundingUp:
+			rules.CashRoundingMode = money.RoundUp
+		}
+	}
+	return rules
+}
+
+// SettingValidationError lists every submitted setting that was rejected
+type SettingValidationError struct {
+	Errors []dto.SettingFieldError
+}
+
+func (e *SettingValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Field+": "+fieldError.Message)
+	}
+	return "invalid settings: " + strings.Join(messages, "; ")
+}
+
+// Forbidden reports whether any setting was rejected because of the caller's role
+func (e *SettingValidationError) Forbidden() bool {
+	for _, fieldError := range e.Errors {
+		if fieldError.Code == "forbidden" {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateSettings validates the settings against the schema and role, then saves
+// them as one new version. Nothing is saved unless every setting is valid.
+func (s *SettingService) UpdateSettings(settingsMap map[string]map[string]interface{}, role string, actor domain.Actor) error {
 	var settings []domain.Setting
+	var fieldErrors []dto.SettingFieldError
 
 	for category, values := range settingsMap {
 		for key, value := range values {
+			field := category + "." + key
+			definition, ok := settingDefinition(key)
+			if !ok {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "unknown_key", Message: "unknown setting"})
+				continue
+			}
+			if definition.Category != category {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "wrong_category", Message: fmt.Sprintf("belongs to category %q", definition.Category)})
+				continue
+			}
+			if !definition.CanEdit(role) {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "forbidden", Message: fmt.Sprintf("can only be changed by %s", strings.Join(definition.EditRoles, " or "))})
+				continue
+			}
+			normalized, err := definition.Validate(value)
+			if err != nil {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: field, Code: "invalid", Message: err.Error()})
+				continue
+			}
+
 			// Convert value to JSON string
-			valueBytes, err := json.Marshal(value)
+			valueBytes, err := json.Marshal(normalized)
 			if err != nil {
 				return err
 			}
@@ -65,43 +355,609 @@ func (s *SettingService) UpdateSettings(settingsMap map[string]map[string]interf
 		}
 	}
 
-	return s.repo.BulkUpsert(settings)
+	if len(fieldErrors) > 0 {
+		sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+		return &SettingValidationError{Errors: fieldErrors}
+	}
+
+	_, err := s.saveVersion(settings, nil, domain.SettingVersionUpdate, nil, actor)
+	return err
 }
 
-// InitializeDefaultSettings creates default settings if they don't exist
-func (s *SettingService) InitializeDefaultSettings() error {
+// InitializeDefaultSettings creates default settings if they don't exist. When
+// settings already exist but were never versioned, they become the baseline version.
+func (s *SettingService) InitializeDefaultSettings(actor domain.Actor) error {
 	// Check if settings already exist
 	existing, _ := s.repo.GetAll()
 	if len(existing) > 0 {
-		return nil // Settings already initialized
+		versioned, err := s.repo.HasVersions()
+		if err != nil || versioned {
+			return err
+		}
+		return s.repo.SaveVersion(nil, s.newVersion(domain.SettingVersionBaseline, nil, nil, actor))
+	}
+
+	defaultSettings := make([]domain.Setting, 0, len(settingSchema))
+	for _, definition := range settingSchema {
+		value, err := json.Marshal(definition.Default)
+		if err != nil {
+			return err
+		}
+		defaultSettings = append(defaultSettings, domain.Setting{
+			Key:      definition.Key,
+			Value:    string(value),
+			Category: definition.Category,
+		})
 	}
 
-	defaultSettings := []domain.Setting{
-		// Store settings
-		{Key: "store_name", Value: `"My POS Store"`, Category: "store"},
-		{Key: "store_address", Value: `"Jl. Example No. 123, Jakarta"`, Category: "store"},
-		{Key: "store_phone", Value: `"021-12345678"`, Category: "store"},
-		{Key: "store_email", Value: `"store@example.com"`, Category: "store"},
+	_, err := s.saveVersion(defaultSettings, nil, domain.SettingVersionInitialize, nil, actor)
+	return err
+}
 
-		// Tax settings
-		{Key: "tax_enabled", Value: `true`, Category: "tax"},
-		{Key: "tax_rate", Value: `10`, Category: "tax"},
-		{Key: "tax_label", Value: `"PPN"`, Category: "tax"},
-		{Key: "currency", Value: `"IDR"`, Category: "tax"},
-		{Key: "currency_symbol", Value: `"Rp"`, Category: "tax"},
+// GetVersions lists setting versions, newest first
+func (s *SettingService) GetVersions(page, limit int) ([]dto.SettingVersionResponse, int64, error) {
+	versions, total, err := s.repo.FindVersions(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		// Receipt settings
-		{Key: "show_logo", Value: `true`, Category: "receipt"},
-		{Key: "show_address", Value: `true`, Category: "receipt"},
-		{Key: "show_phone", Value: `true`, Category: "receipt"},
-		{Key: "footer_text", Value: `"Terima kasih atas kunjungan Anda!"`, Category: "receipt"},
+	responses := make([]dto.SettingVersionResponse, 0, len(versions))
+	for i := range versions {
+		responses = append(responses, toSettingVersionResponse(&versions[i]))
+	}
+	return responses, total, nil
+}
 
-		// System settings
-		{Key: "auto_sync_enabled", Value: `true`, Category: "system"},
-		{Key: "sync_interval", Value: `5`, Category: "system"},
-		{Key: "offline_mode_enabled", Value: `true`, Category: "system"},
-		{Key: "theme", Value: `"light"`, Category: "system"},
+// GetVersion returns a version with the full settings and overrides as they
+// were after it
+func (s *SettingService) GetVersion(version int64) (*dto.SettingVersionDetailResponse, error) {
+	settingVersion, snapshot, err := s.findVersion(version)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.BulkUpsert(defaultSettings)
+	settings := make(map[string]map[string]json.RawMessage)
+	for key, entry := range snapshot {
+		if settings[entry.Category] == nil {
+			settings[entry.Category] = make(map[string]json.RawMessage)
+		}
+		settings[entry.Category][key] = entry.Value
+	}
+
+	overrides, _, err := settingOverrideSnapshot(settingVersion)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	overrideResponses := make([]dto.SettingOverrideEntry, 0, len(ids))
+	for _, id := range ids {
+		entry := overrides[id]
+		overrideResponses = append(overrideResponses, dto.SettingOverrideEntry{
+			Scope:    entry.Scope,
+			ScopeID:  entry.ScopeID.String(),
+			Key:      entry.Key,
+			Category: entry.Category,
+			Value:    entry.Value,
+		})
+	}
+
+	return &dto.SettingVersionDetailResponse{
+		SettingVersionResponse: toSettingVersionResponse(settingVersion),
+		Settings:               settings,
+		Overrides:              overrideResponses,
+	}, nil
+}
+
+// DiffVersions compares the settings and overrides after version from with
+// those after version to. Version 0 stands for before the first version.
+// Overrides are only compared when both versions snapshotted them.
+func (s *SettingService) DiffVersions(from, to int64) (*dto.SettingDiffResponse, error) {
+	before := domain.SettingSnapshot{}
+	overridesBefore := map[string]domain.SettingOverrideSnapshotEntry{}
+	hadOverrides := true
+	if from > 0 {
+		settingVersion, snapshot, err := s.findVersion(from)
+		if err != nil {
+			return nil, err
+		}
+		before = snapshot
+		if overridesBefore, hadOverrides, err = settingOverrideSnapshot(settingVersion); err != nil {
+			return nil, err
+		}
+	}
+	settingVersion, after, err := s.findVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	overridesAfter, hasOverrides, err := settingOverrideSnapshot(settingVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &dto.SettingDiffResponse{From: from, To: to, Changes: []dto.SettingDiffEntry{}}
+	for key, entry := range after {
+		previous, ok := before[key]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, dto.SettingDiffEntry{Key: key, Category: entry.Category, Status: "added", After: entry.Value})
+		case !sameSettingValue(previous, entry):
+			diff.Changes = append(diff.Changes, dto.SettingDiffEntry{Key: key, Category: entry.Category, Status: "changed", Before: previous.Value, After: entry.Value})
+		}
+	}
+	for key, entry := range before {
+		if _, ok := after[key]; !ok {
+			diff.Changes = append(diff.Changes, dto.SettingDiffEntry{Key: key, Category: entry.Category, Status: "removed", Before: entry.Value})
+		}
+	}
+
+	if hadOverrides && hasOverrides {
+		for id, entry := range overridesAfter {
+			change := dto.SettingDiffEntry{Scope: entry.Scope, ScopeID: entry.ScopeID.String(), Key: entry.Key, Category: entry.Category, After: entry.Value}
+			previous, ok := overridesBefore[id]
+			switch {
+			case !ok:
+				change.Status = "added"
+			case previous.Category != entry.Category || string(previous.Value) != string(entry.Value):
+				change.Status = "changed"
+				change.Before = previous.Value
+			default:
+				continue
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+		for id, entry := range overridesBefore {
+			if _, ok := overridesAfter[id]; !ok {
+				diff.Changes = append(diff.Changes, dto.SettingDiffEntry{Scope: entry.Scope, ScopeID: entry.ScopeID.String(), Key: entry.Key, Category: entry.Category, Status: "removed", Before: entry.Value})
+			}
+		}
+	}
+
+	// Global settings first, then overrides by store or device
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.ScopeID != b.ScopeID {
+			return a.ScopeID < b.ScopeID
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Key < b.Key
+	})
+	return diff, nil
+}
+
+// Rollback restores the settings and overrides to how they were after version,
+// recorded as a new version. Keys that didn't exist yet at that version keep
+// their value, overrides added since are removed, and values the current
+// schema no longer accepts block the rollback. Versions saved before overrides
+// were versioned leave the overrides as they are.
+func (s *SettingService) Rollback(version int64, actor domain.Actor) (*dto.SettingVersionResponse, error) {
+	settingVersion, snapshot, err := s.findVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	overrideSnapshot, hasOverrides, err := settingOverrideSnapshot(settingVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var settings []domain.Setting
+	var fieldErrors []dto.SettingFieldError
+	for _, key := range keys {
+		entry := snapshot[key]
+		if definition, ok := settingDefinition(key); ok {
+			var value interface{}
+			if err := json.Unmarshal(entry.Value, &value); err != nil {
+				return nil, err
+			}
+			if _, err := definition.Validate(value); err != nil {
+				fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: entry.Category + "." + key, Code: "invalid", Message: err.Error()})
+				continue
+			}
+		}
+		settings = append(settings, domain.Setting{Key: key, Value: string(entry.Value), Category: entry.Category})
+	}
+
+	var overrides []settingOverrideChange
+	if hasOverrides {
+		current, err := s.repo.FindAllOverrides()
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]*domain.SettingOverride, len(current))
+		for i := range current {
+			existing[settingOverrideEntityID(&current[i])] = &current[i]
+		}
+
+		ids := make([]string, 0, len(overrideSnapshot))
+		for id := range overrideSnapshot {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			entry := overrideSnapshot[id]
+			if definition, ok := settingDefinition(entry.Key); ok {
+				var value interface{}
+				if err := json.Unmarshal(entry.Value, &value); err != nil {
+					return nil, err
+				}
+				if _, err := definition.Validate(value); err != nil {
+					fieldErrors = append(fieldErrors, dto.SettingFieldError{Field: id, Code: "invalid", Message: err.Error()})
+					continue
+				}
+			}
+
+			override := domain.SettingOverride{Scope: entry.Scope, ScopeID: entry.ScopeID, Key: entry.Key, Value: string(entry.Value), Category: entry.Category}
+			previous := existing[id]
+			if previous != nil && previous.Value == override.Value && previous.Category == override.Category {
+				continue
+			}
+			overrides = append(overrides, settingOverrideChange{override: override, existing: previous})
+		}
+
+		for i := range current {
+			if _, ok := overrideSnapshot[settingOverrideEntityID(&current[i])]; !ok {
+				overrides = append(overrides, settingOverrideChange{override: current[i], existing: &current[i], remove: true})
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &SettingValidationError{Errors: fieldErrors}
+	}
+
+	saved, err := s.saveVersion(settings, overrides, domain.SettingVersionRollback, &version, actor)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, errors.New("settings already match this version")
+	}
+
+	response := toSettingVersionResponse(saved)
+	return &response, nil
+}
+
+// settingOverrideChange is an override to save, or to remove when remove is
+// set, with the override it replaces
+type settingOverrideChange struct {
+	override domain.SettingOverride
+	existing *domain.SettingOverride
+	remove   bool
+}
+
+// saveVersion saves the settings that changed and the override changes as a new
+// version and records one audit entry per changed key. Nothing is recorded when
+// nothing changed.
+func (s *SettingService) saveVersion(settings []domain.Setting, overrides []settingOverrideChange, action string, restoredVersion *int64, actor domain.Actor) (*domain.SettingVersion, error) {
+	var changed []domain.Setting
+	var changes []domain.SettingChange
+	previous := make(map[string]*domain.Setting)
+	for _, setting := range settings {
+		existing, err := s.repo.GetByKey(setting.Key)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		change := domain.SettingChange{Key: setting.Key, Category: setting.Category, After: settingJSON(setting.Value)}
+		if existing != nil {
+			if existing.Value == setting.Value && existing.Category == setting.Category {
+				continue
+			}
+			previous[setting.Key] = existing
+			change.Before = settingJSON(existing.Value)
+		}
+		changed = append(changed, setting)
+		changes = append(changes, change)
+	}
+
+	for _, override := range overrides {
+		scopeID := override.override.ScopeID
+		change := domain.SettingChange{Scope: override.override.Scope, ScopeID: &scopeID, Key: override.override.Key, Category: override.override.Category}
+		if !override.remove {
+			change.After = settingJSON(override.override.Value)
+		}
+		if override.existing != nil {
+			change.Before = settingJSON(override.existing.Value)
+		}
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	version := s.newVersion(action, restoredVersion, changes, actor)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		// Overrides go first so the version's snapshot includes them
+		for i := range overrides {
+			override := &overrides[i]
+			if override.remove {
+				if err := repo.DeleteOverride(override.override.Scope, override.override.ScopeID, override.override.Key); err != nil {
+					return err
+				}
+				if err := s.auditService.RecordTx(tx, actor, "delete", "setting_override", settingOverrideEntityID(override.existing), settingOverrideAuditValue(override.existing), nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			action := "create"
+			var before interface{}
+			if override.existing != nil {
+				action = "update"
+				before = settingOverrideAuditValue(override.existing)
+			}
+			if err := repo.UpsertOverride(&override.override); err != nil {
+				return err
+			}
+			if err := s.auditService.RecordTx(tx, actor, action, "setting_override", settingOverrideEntityID(&override.override), before, settingOverrideAuditValue(&override.override)); err != nil {
+				return err
+			}
+		}
+
+		if err := repo.SaveVersion(changed, version); err != nil {
+			return err
+		}
+
+		for _, setting := range changed {
+			action := "create"
+			var before interface{}
+			if existing := previous[setting.Key]; existing != nil {
+				action = "update"
+				before = settingAuditValue(existing)
+			}
+
+			if err := s.auditService.RecordTx(tx, actor, action, "setting", setting.Key, before, settingAuditValue(&setting)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func (s *SettingService) newVersion(action string, restoredVersion *int64, changes []domain.SettingChange, actor domain.Actor) *domain.SettingVersion {
+	if changes == nil {
+		changes = []domain.SettingChange{}
+	}
+	changesJSON, _ := json.Marshal(changes)
+
+	version := &domain.SettingVersion{
+		Action:          action,
+		RestoredVersion: restoredVersion,
+		ActorName:       actor.Username,
+		Changes:         string(changesJSON),
+	}
+	if actor.UserID != uuid.Nil {
+		actorID := actor.UserID
+		version.ActorID = &actorID
+	}
+	return version
+}
+
+func (s *SettingService) findVersion(version int64) (*domain.SettingVersion, domain.SettingSnapshot, error) {
+	settingVersion, err := s.repo.FindVersion(version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("setting version not found")
+		}
+		return nil, nil, err
+	}
+
+	var snapshot domain.SettingSnapshot
+	if err := json.Unmarshal([]byte(settingVersion.Snapshot), &snapshot); err != nil {
+		return nil, nil, fmt.Errorf("corrupt snapshot for version %d: %v", version, err)
+	}
+	return settingVersion, snapshot, nil
+}
+
+// settingOverrideSnapshot reads the overrides a version snapshotted, keyed by
+// settingOverrideEntityID. It reports false for versions saved before
+// overrides were versioned.
+func settingOverrideSnapshot(version *domain.SettingVersion) (map[string]domain.SettingOverrideSnapshotEntry, bool, error) {
+	if version.OverrideSnapshot == "" {
+		return nil, false, nil
+	}
+
+	var entries []domain.SettingOverrideSnapshotEntry
+	if err := json.Unmarshal([]byte(version.OverrideSnapshot), &entries); err != nil {
+		return nil, false, fmt.Errorf("corrupt override snapshot for version %d: %v", version.Version, err)
+	}
+	snapshot := make(map[string]domain.SettingOverrideSnapshotEntry, len(entries))
+	for _, entry := range entries {
+		snapshot[settingOverrideEntityID(&domain.SettingOverride{Scope: entry.Scope, ScopeID: entry.ScopeID, Key: entry.Key})] = entry
+	}
+	return snapshot, true, nil
+}
+
+func toSettingVersionResponse(version *domain.SettingVersion) dto.SettingVersionResponse {
+	response := dto.SettingVersionResponse{
+		Version:         version.Version,
+		Action:          version.Action,
+		RestoredVersion: version.RestoredVersion,
+		ActorName:       version.ActorName,
+		Changes:         []dto.SettingChangeResponse{},
+		CreatedAt:       version.CreatedAt.Format(time.RFC3339),
+	}
+	if version.ActorID != nil {
+		response.ActorID = version.ActorID.String()
+	}
+
+	var changes []domain.SettingChange
+	if err := json.Unmarshal([]byte(version.Changes), &changes); err == nil {
+		for _, change := range changes {
+			entry := dto.SettingChangeResponse{
+				Scope:    change.Scope,
+				Key:      change.Key,
+				Category: change.Category,
+				Before:   change.Before,
+				After:    change.After,
+			}
+			if change.ScopeID != nil {
+				entry.ScopeID = change.ScopeID.String()
+			}
+			response.Changes = append(response.Changes, entry)
+		}
+	}
+	return response
+}
+
+// settingJSON returns a stored value as JSON, quoting raw strings
+func settingJSON(value string) json.RawMessage {
+	if json.Valid([]byte(value)) {
+		return json.RawMessage(value)
+	}
+	quoted, _ := json.Marshal(value)
+	return quoted
+}
+
+func sameSettingValue(a, b domain.SettingSnapshotEntry) bool {
+	return a.Category == b.Category && string(a.Value) == string(b.Value)
+}
+
+// resolve works out the value and source of every setting for scope, in schema
+// order followed by any stored keys the schema doesn't know. It returns the
+// scope with the device's store filled in.
+func (s *SettingService) resolve(scope SettingScope) (SettingScope, []dto.ResolvedSettingResponse, error) {
+	if scope.DeviceID != nil && scope.StoreID == nil {
+		device, err := s.deviceRepo.FindByID(*scope.DeviceID)
+		if err != nil {
+			return scope, nil, errors.New("device not found")
+		}
+		scope.StoreID = device.StoreID
+	}
+
+	resolved := make([]dto.ResolvedSettingResponse, 0, len(settingSchema))
+	index := make(map[string]int, len(settingSchema))
+	for _, definition := range settingSchema {
+		index[definition.Key] = len(resolved)
+		resolved = append(resolved, dto.ResolvedSettingResponse{
+			Key:      definition.Key,
+			Category: definition.Category,
+			Value:    definition.Default,
+			Source:   "default",
+		})
+	}
+
+	settings, err := s.repo.GetAll()
+	if err != nil {
+		return scope, nil, err
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	for _, setting := range settings {
+		i, ok := index[setting.Key]
+		if !ok {
+			i = len(resolved)
+			index[setting.Key] = i
+			resolved = append(resolved, dto.ResolvedSettingResponse{Key: setting.Key})
+		}
+		resolved[i].Category = setting.Category
+		resolved[i].Value = settingValue(setting.Value)
+		resolved[i].Source = "global"
+	}
+
+	levels := []struct {
+		scope string
+		id    *uuid.UUID
+	}{
+		{domain.SettingScopeStore, scope.StoreID},
+		{domain.SettingScopeDevice, scope.DeviceID},
+	}
+	for _, level := range levels {
+		if level.id == nil {
+			continue
+		}
+		overrides, err := s.repo.FindOverrides(level.scope, *level.id)
+		if err != nil {
+			return scope, nil, err
+		}
+		for _, override := range overrides {
+			// The schema decides what may be overridden, even for overrides saved earlier
+			definition, ok := settingDefinition(override.Key)
+			if !ok || !definition.CanOverride(level.scope) {
+				continue
+			}
+			i := index[override.Key]
+			resolved[i].Value = settingValue(override.Value)
+			resolved[i].Source = level.scope
+			resolved[i].SourceID = level.id.String()
+		}
+	}
+
+	return scope, resolved, nil
+}
+
+// settingValue decodes a stored JSON value, otherwise returning it as a string
+func settingValue(value string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+func settingOverrideEntityID(override *domain.SettingOverride) string {
+	return override.Scope + "/" + override.ScopeID.String() + "/" + override.Key
+}
+
+func settingOverrideAuditValue(override *domain.SettingOverride) map[string]interface{} {
+	return map[string]interface{}{
+		"scope":    override.Scope,
+		"scope_id": override.ScopeID,
+		"category": override.Category,
+		"value":    settingJSON(override.Value),
+	}
+}
+
+// settingNumber reads a validated number setting or a schema default
+func settingNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// settingStringValue reads a JSON-encoded string setting, tolerating raw values
+func settingStringValue(value string) string {
+	var s string
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return value
+	}
+	return s
+}
+
+func settingAuditValue(setting *domain.Setting) map[string]interface{} {
+	var value interface{} = json.RawMessage(setting.Value)
+	if !json.Valid([]byte(setting.Value)) {
+		value = setting.Value
+	}
+	return map[string]interface{}{
+		"category": setting.Category,
+		"value":    value,
+	}
 }
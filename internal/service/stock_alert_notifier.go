@@ -0,0 +1,51 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LowStockAlert is raised when a product's stock drops to or below its MinStock
+type LowStockAlert struct {
+	ProductID         uuid.UUID `json:"product_id"`
+	ProductName       string    `json:"product_name"`
+	SKU               string    `json:"sku"`
+	PreviousStock     int       `json:"previous_stock"`
+	Stock             int       `json:"stock"`
+	MinStock          int       `json:"min_stock"`
+	SuggestedQuantity int       `json:"suggested_quantity"`
+	OccurredAt        time.Time `json:"occurred_at"`
+}
+
+// StockAlertNotifier delivers low-stock alerts, e.g. to a log, chat or e-mail
+type StockAlertNotifier interface {
+	NotifyLowStock(alert LowStockAlert) error
+}
+
+// LogStockAlertNotifier writes alerts to the application log
+type LogStockAlertNotifier struct{}
+
+func NewLogStockAlertNotifier() *LogStockAlertNotifier {
+	return &LogStockAlertNotifier{}
+}
+
+func (n *LogStockAlertNotifier) NotifyLowStock(alert LowStockAlert) error {
+	log.Printf("Low stock: %s (%s) dropped from %d to %d, minimum %d, suggest ordering %d",
+		alert.ProductName, alert.SKU, alert.PreviousStock, alert.Stock, alert.MinStock, alert.SuggestedQuantity)
+	return nil
+}
+
+// StockAlertNotifiers fans an alert out to several notifiers, one failing doesn't stop the rest
+type StockAlertNotifiers []StockAlertNotifier
+
+func (n StockAlertNotifiers) NotifyLowStock(alert LowStockAlert) error {
+	var firstErr error
+	for _, notifier := range n {
+		if err := notifier.NotifyLowStock(alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
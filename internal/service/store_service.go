@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StoreService interface {
+	GetAll(page, limit int) ([]*dto.StoreResponse, int64, error)
+	GetByID(id string) (*dto.StoreResponse, error)
+	Create(req *dto.CreateStoreRequest, actor domain.Actor) (*dto.StoreResponse, error)
+	Update(id string, req *dto.UpdateStoreRequest, actor domain.Actor) (*dto.StoreResponse, error)
+	Delete(id string, actor domain.Actor) error
+}
+
+type storeService struct {
+	storeRepo    domain.StoreRepository
+	auditService AuditService
+	db           *gorm.DB
+}
+
+func NewStoreService(storeRepo domain.StoreRepository, auditService AuditService, db *gorm.DB) StoreService {
+	return &storeService{
+		storeRepo:    storeRepo,
+		auditService: auditService,
+		db:           db,
+	}
+}
+
+func (s *storeService) GetAll(page, limit int) ([]*dto.StoreResponse, int64, error) {
+	stores, total, err := s.storeRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.StoreResponse
+	for i := range stores {
+		responses = append(responses, toStoreResponse(&stores[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *storeService) GetByID(id string) (*dto.StoreResponse, error) {
+	storeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid store ID format")
+	}
+
+	store, err := s.storeRepo.FindByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+
+	return toStoreResponse(store), nil
+}
+
+func (s *storeService) Create(req *dto.CreateStoreRequest, actor domain.Actor) (*dto.StoreResponse, error) {
+	if existing, _ := s.storeRepo.FindByCode(req.Code); existing != nil {
+		return nil, errors.New("store with this code already exists")
+	}
+
+	store := domain.Store{
+		Code:     req.Code,
+		Name:     req.Name,
+		Address:  req.Address,
+		Phone:    req.Phone,
+		IsActive: true,
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.storeRepo.WithTx(tx).Create(&store); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "store", store.ID.String(), nil, store)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toStoreResponse(&store), nil
+}
+
+func (s *storeService) Update(id string, req *dto.UpdateStoreRequest, actor domain.Actor) (*dto.StoreResponse, error) {
+	storeID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid store ID format")
+	}
+
+	store, err := s.storeRepo.FindByID(storeID)
+	if err != nil {
+		return nil, errors.New("store not found")
+	}
+	before := *store
+
+	if store.Code != req.Code {
+		if existing, _ := s.storeRepo.FindByCode(req.Code); existing != nil {
+			return nil, errors.New("store with this code already exists")
+		}
+	}
+
+	store.Code = req.Code
+	store.Name = req.Name
+	store.Address = req.Address
+	store.Phone = req.Phone
+	store.IsActive = req.IsActive
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.storeRepo.WithTx(tx).Update(store); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "store", store.ID.String(), before, store)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toStoreResponse(store), nil
+}
+
+func (s *storeService) Delete(id string, actor domain.Actor) error {
+	storeID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid store ID format")
+	}
+
+	store, err := s.storeRepo.FindByID(storeID)
+	if err != nil {
+		return errors.New("store not found")
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.storeRepo.WithTx(tx).Delete(storeID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "store", store.ID.String(), store, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Helper functions
+
+func toStoreResponse(store *domain.Store) *dto.StoreResponse {
+	return &dto.StoreResponse{
+		ID:        store.ID.String(),
+		Code:      store.Code,
+		Name:      store.Name,
+		Address:   store.Address,
+		Phone:     store.Phone,
+		IsActive:  store.IsActive,
+		CreatedAt: store.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxClassService manages the tax classes products and categories are sold
+// under. A class's rate and pricing are copied onto each sale line, so
+// changing a class only affects later sales.
+type TaxClassService interface {
+	GetAll(page, limit int) ([]*dto.TaxClassResponse, int64, error)
+	GetByID(id string) (*dto.TaxClassResponse, error)
+	Create(req *dto.CreateTaxClassRequest, actor domain.Actor) (*dto.TaxClassResponse, error)
+	Update(id string, req *dto.UpdateTaxClassRequest, actor domain.Actor) (*dto.TaxClassResponse, error)
+	Delete(id string, actor domain.Actor) error
+}
+
+type taxClassService struct {
+	taxClassRepo domain.TaxClassRepository
+	auditService AuditService
+	db           *gorm.DB
+}
+
+func NewTaxClassService(taxClassRepo domain.TaxClassRepository, auditService AuditService, db *gorm.DB) TaxClassService {
+	return &taxClassService{
+		taxClassRepo: taxClassRepo,
+		auditService: auditService,
+		db:           db,
+	}
+}
+
+func (s *taxClassService) GetAll(page, limit int) ([]*dto.TaxClassResponse, int64, error) {
+	taxClasses, total, err := s.taxClassRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.TaxClassResponse
+	for i := range taxClasses {
+		responses = append(responses, toTaxClassResponse(&taxClasses[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *taxClassService) GetByID(id string) (*dto.TaxClassResponse, error) {
+	taxClassID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid tax class ID format")
+	}
+
+	taxClass, err := s.taxClassRepo.FindByID(taxClassID)
+	if err != nil {
+		return nil, errors.New("tax class not found")
+	}
+
+	return toTaxClassResponse(taxClass), nil
+}
+
+func (s *taxClassService) Create(req *dto.CreateTaxClassRequest, actor domain.Actor) (*dto.TaxClassResponse, error) {
+	if existing, _ := s.taxClassRepo.FindByCode(req.Code); existing != nil {
+		return nil, errors.New("tax class with this code already exists")
+	}
+
+	taxClass := domain.TaxClass{
+		Code:      req.Code,
+		Name:      req.Name,
+		Rate:      req.Rate,
+		Inclusive: req.Inclusive,
+		IsActive:  true,
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.taxClassRepo.WithTx(tx).Create(&taxClass); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "tax_class", taxClass.ID.String(), nil, taxClass)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toTaxClassResponse(&taxClass), nil
+}
+
+func (s *taxClassService) Update(id string, req *dto.UpdateTaxClassRequest, actor domain.Actor) (*dto.TaxClassResponse, error) {
+	taxClassID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid tax class ID format")
+	}
+
+	taxClass, err := s.taxClassRepo.FindByID(taxClassID)
+	if err != nil {
+		return nil, errors.New("tax class not found")
+	}
+	before := *taxClass
+
+	if taxClass.Code != req.Code {
+		if existing, _ := s.taxClassRepo.FindByCode(req.Code); existing != nil {
+			return nil, errors.New("tax class with this code already exists")
+		}
+	}
+
+	taxClass.Code = req.Code
+	taxClass.Name = req.Name
+	taxClass.Rate = req.Rate
+	taxClass.Inclusive = req.Inclusive
+	taxClass.IsActive = req.IsActive
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.taxClassRepo.WithTx(tx).Update(taxClass); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "tax_class", taxClass.ID.String(), before, taxClass)
+	}); err != nil {
+		return nil, err
+	}
+
+	return toTaxClassResponse(taxClass), nil
+}
+
+func (s *taxClassService) Delete(id string, actor domain.Actor) error {
+	taxClassID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid tax class ID format")
+	}
+
+	taxClass, err := s.taxClassRepo.FindByID(taxClassID)
+	if err != nil {
+		return errors.New("tax class not found")
+	}
+
+	// Products would silently stop being taxed, reassign them first
+	inUse, err := s.taxClassRepo.IsInUse(taxClassID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("tax class is assigned to products or categories")
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.taxClassRepo.WithTx(tx).Delete(taxClassID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "tax_class", taxClass.ID.String(), taxClass, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Helper functions
+
+// parseTaxClassID validates an optional tax class reference from a request,
+// returning nil when it is empty
+func parseTaxClassID(taxClassRepo domain.TaxClassRepository, id string) (*uuid.UUID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	taxClassID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid tax class ID format")
+	}
+	taxClass, err := taxClassRepo.FindByID(taxClassID)
+	if err != nil {
+		return nil, errors.New("tax class not found")
+	}
+	if !taxClass.IsActive {
+		return nil, errors.New("tax class is inactive")
+	}
+	return &taxClassID, nil
+}
+
+func toTaxClassResponse(taxClass *domain.TaxClass) *dto.TaxClassResponse {
+	return &dto.TaxClassResponse{
+		ID:        taxClass.ID.String(),
+		Code:      taxClass.Code,
+		Name:      taxClass.Name,
+		Rate:      taxClass.Rate,
+		Inclusive: taxClass.Inclusive,
+		IsActive:  taxClass.IsActive,
+		CreatedAt: taxClass.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketService runs table service: a ticket is opened on a table, items are
+// sent to the kitchen in rounds and tracked there, and the ticket is paid at
+// the end through TransactionService.Create like any other cart. Sending a
+// round doesn't touch stock, settling does.
+type TicketService interface {
+	Open(req *dto.OpenTicketRequest, actor domain.Actor) (*dto.TicketResponse, error)
+	GetAll(page, limit int, filters domain.TicketFilters) ([]*dto.TicketResponse, int64, error)
+	GetByID(id string) (*dto.TicketResponse, error)
+	Update(id string, req *dto.UpdateTicketRequest, actor domain.Actor) (*dto.TicketResponse, error)
+	SendRound(id string, req *dto.SendRoundRequest, actor domain.Actor) (*dto.TicketResponse, error)
+	// VoidItem takes an item off the ticket while the kitchen hasn't started it
+	VoidItem(id, itemID string, actor domain.Actor) (*dto.TicketResponse, error)
+	Settle(id string, req *dto.SettleTicketRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error)
+	Void(id string, actor domain.Actor) (*dto.TicketResponse, error)
+	KitchenQueue(filters domain.KitchenQueueFilters) ([]*dto.KitchenItemResponse, error)
+	UpdateKitchenStatus(itemID string, req *dto.UpdateKitchenStatusRequest, actor domain.Actor) (*dto.KitchenItemResponse, error)
+}
+
+type ticketService struct {
+	ticketRepo         domain.TicketRepository
+	diningRepo         domain.DiningRepository
+	modifierRepo       domain.ModifierRepository
+	productRepo        domain.ProductRepository
+	deviceRepo         domain.DeviceRepository
+	transactionService TransactionService
+	auditService       AuditService
+	db                 *gorm.DB
+}
+
+func NewTicketService(
+	ticketRepo domain.TicketRepository,
+	diningRepo domain.DiningRepository,
+	modifierRepo domain.ModifierRepository,
+	productRepo domain.ProductRepository,
+	deviceRepo domain.DeviceRepository,
+	transactionService TransactionService,
+	auditService AuditService,
+	db *gorm.DB,
+) TicketService {
+	return &ticketService{
+		ticketRepo:         ticketRepo,
+		diningRepo:         diningRepo,
+		modifierRepo:       modifierRepo,
+		productRepo:        productRepo,
+		deviceRepo:         deviceRepo,
+		transactionService: transactionService,
+		auditService:       auditService,
+		db:                 db,
+	}
+}
+
+func (s *ticketService) Open(req *dto.OpenTicketRequest, actor domain.Actor) (*dto.TicketResponse, error) {
+	if req.Guests < 0 {
+		return nil, errors.New("guests cannot be negative")
+	}
+
+	userID := actor.UserID
+	ticket := domain.Ticket{
+		Status:       domain.TicketStatusOpen,
+		Guests:       req.Guests,
+		CustomerName: req.CustomerName,
+		Notes:        req.Notes,
+		OpenedBy:     &userID,
+	}
+
+	if req.TableID != "" {
+		table, err := s.freeTable(req.TableID)
+		if err != nil {
+			return nil, err
+		}
+		ticket.TableID = &table.ID
+		ticket.StoreID = table.Area.StoreID
+	} else {
+		storeID, err := s.resolveStoreID(req.StoreID, actor)
+		if err != nil {
+			return nil, err
+		}
+		ticket.StoreID = storeID
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.ticketRepo.WithTx(tx).Create(&ticket); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "create", "ticket", ticket.ID.String(), nil, ticket)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ticket.ID.String())
+}
+
+func (s *ticketService) GetAll(page, limit int, filters domain.TicketFilters) ([]*dto.TicketResponse, int64, error) {
+	tickets, total, err := s.ticketRepo.FindAll(page, limit, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var responses []*dto.TicketResponse
+	for i := range tickets {
+		responses = append(responses, toTicketResponse(&tickets[i]))
+	}
+
+	return responses, total, nil
+}
+
+func (s *ticketService) GetByID(id string) (*dto.TicketResponse, error) {
+	ticket, err := s.findTicket(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTicketResponse(ticket), nil
+}
+
+func (s *ticketService) Update(id string, req *dto.UpdateTicketRequest, actor domain.Actor) (*dto.TicketResponse, error) {
+	if req.Guests < 0 {
+		return nil, errors.New("guests cannot be negative")
+	}
+
+	ticket, err := s.findTicket(id, true)
+	if err != nil {
+		return nil, err
+	}
+	before := map[string]interface{}{"table_id": ticket.TableID, "guests": ticket.Guests, "customer_name": ticket.CustomerName, "notes": ticket.Notes}
+
+	if req.TableID != "" && (ticket.TableID == nil || ticket.TableID.String() != req.TableID) {
+		table, err := s.freeTable(req.TableID)
+		if err != nil {
+			return nil, err
+		}
+		ticket.TableID = &table.ID
+		ticket.StoreID = table.Area.StoreID
+	}
+	ticket.Guests = req.Guests
+	ticket.CustomerName = req.CustomerName
+	ticket.Notes = req.Notes
+
+	after := map[string]interface{}{"table_id": ticket.TableID, "guests": ticket.Guests, "customer_name": ticket.CustomerName, "notes": ticket.Notes}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.ticketRepo.WithTx(tx).Update(ticket); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "ticket", ticket.ID.String(), before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ticket.ID.String())
+}
+
+func (s *ticketService) SendRound(id string, req *dto.SendRoundRequest, actor domain.Actor) (*dto.TicketResponse, error) {
+	ticket, err := s.findTicket(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("a round needs at least one item")
+	}
+
+	var items []domain.TicketItem
+	for _, itemReq := range req.Items {
+		productID, err := uuid.Parse(itemReq.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %s", itemReq.ProductID)
+		}
+		if itemReq.Quantity < 1 {
+			return nil, fmt.Errorf("invalid quantity for product: %s", itemReq.ProductID)
+		}
+		if itemReq.Price.IsNegative() {
+			return nil, fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
+		}
+
+		product, err := s.productRepo.FindByID(productID)
+		if err != nil {
+			return nil, fmt.Errorf("product not found: %s", itemReq.ProductID)
+		}
+		if !product.IsActive {
+			return nil, fmt.Errorf("product is not active: %s", product.Name)
+		}
+
+		groups, err := s.modifierRepo.FindByProduct(productID)
+		if err != nil {
+			return nil, err
+		}
+		modifiers, err := selectModifiers(product, groups, itemReq.ModifierOptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		selected := toSelectedModifiers(modifiers)
+		if itemReq.Price.Add(selected.UnitAmount()).IsNegative() {
+			return nil, fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
+		}
+
+		items = append(items, domain.TicketItem{
+			ProductID:   productID,
+			ProductName: product.Name,
+			Price:       itemReq.Price,
+			Quantity:    itemReq.Quantity,
+			Modifiers:   selected,
+			Notes:       itemReq.Notes,
+		})
+	}
+
+	var round int
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		round, err = s.ticketRepo.AddRoundTx(tx, ticket.ID, items)
+		if err != nil {
+			return err
+		}
+		after := map[string]interface{}{"round": round, "items": items}
+		return s.auditService.RecordTx(tx, actor, "send_round", "ticket", ticket.ID.String(), nil, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ticket.ID.String())
+}
+
+func (s *ticketService) VoidItem(id, itemID string, actor domain.Actor) (*dto.TicketResponse, error) {
+	ticket, err := s.findTicket(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.findItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.TicketID != ticket.ID {
+		return nil, errors.New("ticket item not found")
+	}
+	if item.KitchenStatus != domain.KitchenStatusQueued {
+		return nil, fmt.Errorf("ticket item is already %s", item.KitchenStatus)
+	}
+
+	item.Ticket = nil
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.ticketRepo.WithTx(tx).DeleteItem(item.ID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "void_item", "ticket", ticket.ID.String(), item, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ticket.ID.String())
+}
+
+func (s *ticketService) Settle(id string, req *dto.SettleTicketRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error) {
+	switch req.PaymentMethod {
+	case "cash", "card", "qris":
+	default:
+		return nil, nil, fmt.Errorf("unknown payment method %q", req.PaymentMethod)
+	}
+
+	ticket, err := s.findTicket(id, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientTransactionID := req.ClientTransactionID
+	if clientTransactionID == "" {
+		clientTransactionID = "ticket-" + ticket.ID.String()
+	}
+
+	// A retry after the sale went through gets the same transaction back
+	if ticket.Status != domain.TicketStatusOpen && ticket.TransactionID == nil {
+		return nil, nil, fmt.Errorf("ticket is %s", ticket.Status)
+	}
+	if len(ticket.Items) == 0 {
+		return nil, nil, errors.New("ticket has no items")
+	}
+
+	txReq := dto.CreateTransactionRequest{
+		ClientTransactionID: clientTransactionID,
+		PaymentMethod:       req.PaymentMethod,
+		CustomerName:        ticket.CustomerName,
+		DiscountAmount:      req.DiscountAmount,
+		Notes:               ticket.Notes,
+		TicketID:            &ticket.ID,
+		TicketUpdatedAt:     ticket.UpdatedAt,
+	}
+	if ticket.StoreID != nil {
+		txReq.StoreID = ticket.StoreID.String()
+	}
+	for _, item := range ticket.Items {
+		txReq.Items = append(txReq.Items, dto.TransactionItemRequest{
+			ProductID:         item.ProductID.String(),
+			Quantity:          item.Quantity,
+			Price:             item.Price,
+			ModifierOptionIDs: item.Modifiers.OptionIDs(),
+		})
+	}
+
+	return s.transactionService.Create(&txReq, actor)
+}
+
+func (s *ticketService) Void(id string, actor domain.Actor) (*dto.TicketResponse, error) {
+	ticket, err := s.findTicket(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ticket.Status = domain.TicketStatusVoid
+	ticket.ClosedAt = &now
+	after := map[string]interface{}{"status": ticket.Status}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.ticketRepo.WithTx(tx).Update(ticket); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "void", "ticket", ticket.ID.String(), map[string]interface{}{"status": domain.TicketStatusOpen}, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toTicketResponse(ticket), nil
+}
+
+func (s *ticketService) KitchenQueue(filters domain.KitchenQueueFilters) ([]*dto.KitchenItemResponse, error) {
+	items, err := s.ticketRepo.FindKitchenQueue(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*dto.KitchenItemResponse{}
+	for i := range items {
+		responses = append(responses, toKitchenItemResponse(&items[i]))
+	}
+
+	return responses, nil
+}
+
+func (s *ticketService) UpdateKitchenStatus(itemID string, req *dto.UpdateKitchenStatusRequest, actor domain.Actor) (*dto.KitchenItemResponse, error) {
+	item, err := s.findItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Ticket == nil || item.Ticket.Status != domain.TicketStatusOpen {
+		return nil, errors.New("ticket is no longer open")
+	}
+	switch req.Status {
+	case domain.KitchenStatusQueued, domain.KitchenStatusPreparing, domain.KitchenStatusReady, domain.KitchenStatusServed:
+	default:
+		return nil, fmt.Errorf("unknown kitchen status %q", req.Status)
+	}
+	before := map[string]interface{}{"kitchen_status": item.KitchenStatus}
+
+	if item.KitchenStatus != req.Status {
+		ticket := item.Ticket
+		item.KitchenStatus = req.Status
+		item.StatusChangedAt = time.Now()
+		item.Ticket = nil
+		after := map[string]interface{}{"kitchen_status": item.KitchenStatus}
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := s.ticketRepo.WithTx(tx).UpdateItem(item); err != nil {
+				return err
+			}
+			return s.auditService.RecordTx(tx, actor, "update", "ticket_item", item.ID.String(), before, after)
+		})
+		if err != nil {
+			return nil, err
+		}
+		item.Ticket = ticket
+	}
+
+	return toKitchenItemResponse(item), nil
+}
+
+// Helper functions
+
+func (s *ticketService) findTicket(id string, mustBeOpen bool) (*domain.Ticket, error) {
+	ticketID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid ticket ID format")
+	}
+
+	ticket, err := s.ticketRepo.FindByID(ticketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ticket not found")
+		}
+		return nil, err
+	}
+
+	if mustBeOpen && ticket.Status != domain.TicketStatusOpen {
+		return nil, fmt.Errorf("ticket is %s", ticket.Status)
+	}
+
+	return ticket, nil
+}
+
+func (s *ticketService) findItem(id string) (*domain.TicketItem, error) {
+	itemID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid ticket item ID format")
+	}
+
+	item, err := s.ticketRepo.FindItem(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ticket item not found")
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// freeTable returns an active table without an open ticket
+func (s *ticketService) freeTable(id string) (*domain.DiningTable, error) {
+	tableID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.New("invalid dining table ID format")
+	}
+
+	table, err := s.diningRepo.FindTableByID(tableID)
+	if err != nil {
+		return nil, errors.New("dining table not found")
+	}
+	if !table.IsActive || table.Area == nil || !table.Area.IsActive {
+		return nil, errors.New("dining table is not active")
+	}
+	if _, err := s.ticketRepo.FindOpenByTable(table.ID); err == nil {
+		return nil, errors.New("dining table already has an open ticket")
+	}
+
+	return table, nil
+}
+
+// resolveStoreID puts tickets without a table in the device's store and falls
+// back to the requested store otherwise, like sales
+func (s *ticketService) resolveStoreID(requested string, actor domain.Actor) (*uuid.UUID, error) {
+	if actor.DeviceID != nil {
+		device, err := s.deviceRepo.FindByID(*actor.DeviceID)
+		if err != nil {
+			return nil, fmt.Errorf("device not found: %v", err)
+		}
+		return device.StoreID, nil
+	}
+
+	if requested == "" {
+		return nil, nil
+	}
+	storeID, err := uuid.Parse(requested)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %s", requested)
+	}
+	return &storeID, nil
+}
+
+func toTicketResponse(ticket *domain.Ticket) *dto.TicketResponse {
+	response := &dto.TicketResponse{
+		ID:            ticket.ID.String(),
+		TableID:       formatOptionalUUID(ticket.TableID),
+		StoreID:       formatOptionalUUID(ticket.StoreID),
+		Status:        ticket.Status,
+		Guests:        ticket.Guests,
+		CustomerName:  ticket.CustomerName,
+		Notes:         ticket.Notes,
+		Rounds:        ticket.Rounds,
+		OpenedBy:      formatOptionalUUID(ticket.OpenedBy),
+		Items:         []dto.TicketItemResponse{},
+		ClosedAt:      formatOptionalTime(ticket.ClosedAt),
+		TransactionID: formatOptionalUUID(ticket.TransactionID),
+		CreatedAt:     ticket.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     ticket.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if ticket.Table != nil {
+		response.TableName = ticket.Table.Name
+	}
+	if ticket.User != nil {
+		response.Username = ticket.User.Username
+	}
+
+	var total money.Money
+	for _, item := range ticket.Items {
+		subtotal := item.Price.Add(item.Modifiers.UnitAmount()).Mul(int64(item.Quantity))
+		total = total.Add(subtotal)
+		response.Items = append(response.Items, dto.TicketItemResponse{
+			ID:              item.ID.String(),
+			Round:           item.Round,
+			ProductID:       item.ProductID.String(),
+			ProductName:     item.ProductName,
+			Price:           item.Price,
+			Quantity:        item.Quantity,
+			Modifiers:       toSelectedModifierResponses(item.Modifiers),
+			Subtotal:        subtotal,
+			Notes:           item.Notes,
+			KitchenStatus:   item.KitchenStatus,
+			SentAt:          item.SentAt.Format(time.RFC3339),
+			StatusChangedAt: item.StatusChangedAt.Format(time.RFC3339),
+		})
+	}
+	response.TotalAmount = total
+
+	return response
+}
+
+func toKitchenItemResponse(item *domain.TicketItem) *dto.KitchenItemResponse {
+	response := &dto.KitchenItemResponse{
+		ID:              item.ID.String(),
+		TicketID:        item.TicketID.String(),
+		Round:           item.Round,
+		ProductID:       item.ProductID.String(),
+		ProductName:     item.ProductName,
+		Quantity:        item.Quantity,
+		Notes:           item.Notes,
+		KitchenStatus:   item.KitchenStatus,
+		SentAt:          item.SentAt.Format(time.RFC3339),
+		StatusChangedAt: item.StatusChangedAt.Format(time.RFC3339),
+	}
+
+	for _, modifier := range item.Modifiers {
+		name := modifier.OptionName
+		if modifier.Quantity > 1 {
+			name = fmt.Sprintf("%dx %s", modifier.Quantity, modifier.OptionName)
+		}
+		response.Modifiers = append(response.Modifiers, name)
+	}
+
+	if item.Ticket != nil && item.Ticket.Table != nil {
+		response.TableName = item.Ticket.Table.Name
+	}
+
+	return response
+}
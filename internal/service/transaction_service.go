@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"pos-backend/internal/domain"
 	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,36 +17,92 @@ import (
 )
 
 type TransactionService interface {
-	Create(req *dto.CreateTransactionRequest, userID uuid.UUID) (*dto.TransactionResponse, []dto.StockWarning, error)
-	BulkSync(req *dto.BulkSyncTransactionRequest, userID uuid.UUID) (*dto.BulkSyncResponse, error)
+	Create(req *dto.CreateTransactionRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error)
+	// CreateWithTx is Create with beforeCommit run in the sale's database
+	// transaction, so work that must land together with the sale is atomic
+	// with it. When the sale already exists it runs on its own against it.
+	CreateWithTx(req *dto.CreateTransactionRequest, actor domain.Actor, beforeCommit func(tx *gorm.DB, transaction *domain.Transaction) error) (*dto.TransactionResponse, []dto.StockWarning, error)
+	BulkSync(req *dto.BulkSyncTransactionRequest, actor domain.Actor) (*dto.BulkSyncResponse, error)
 	GetByID(id string) (*dto.TransactionResponse, error)
 	GetAll(page, limit int, filters domain.TransactionFilters) ([]*dto.TransactionResponse, int64, error)
-	Cancel(id string) error
+	// Export calls fn for every matching transaction without loading them all at once
+	Export(filters domain.TransactionFilters, fn func(transaction *dto.TransactionResponse) error) error
+	Cancel(id string, req *dto.CancelTransactionRequest, actor domain.Actor) error
 }
 
 type transactionService struct {
-	transactionRepo domain.TransactionRepository
-	productRepo     domain.ProductRepository
-	db              *gorm.DB
+	transactionRepo  domain.TransactionRepository
+	productRepo      domain.ProductRepository
+	deviceRepo       domain.DeviceRepository
+	signingKeyRepo   domain.DeviceSigningKeyRepository
+	quarantineRepo   domain.QuarantineRepository
+	heldOrderRepo    domain.HeldOrderRepository
+	ticketRepo       domain.TicketRepository
+	modifierRepo     domain.ModifierRepository
+	recipeRepo       domain.RecipeRepository
+	inventoryService InventoryService
+	reorderService   ReorderService
+	summaryService   SalesSummaryService
+	settingService   *SettingService
+	auditService     AuditService
+	eventBus         *EventBus
+	db               *gorm.DB
 }
 
 func NewTransactionService(
 	transactionRepo domain.TransactionRepository,
 	productRepo domain.ProductRepository,
+	deviceRepo domain.DeviceRepository,
+	signingKeyRepo domain.DeviceSigningKeyRepository,
+	quarantineRepo domain.QuarantineRepository,
+	heldOrderRepo domain.HeldOrderRepository,
+	ticketRepo domain.TicketRepository,
+	modifierRepo domain.ModifierRepository,
+	recipeRepo domain.RecipeRepository,
+	inventoryService InventoryService,
+	reorderService ReorderService,
+	summaryService SalesSummaryService,
+	settingService *SettingService,
+	auditService AuditService,
+	eventBus *EventBus,
 	db *gorm.DB,
 ) TransactionService {
 	return &transactionService{
-		transactionRepo: transactionRepo,
-		productRepo:     productRepo,
-		db:              db,
+		transactionRepo:  transactionRepo,
+		productRepo:      productRepo,
+		deviceRepo:       deviceRepo,
+		signingKeyRepo:   signingKeyRepo,
+		quarantineRepo:   quarantineRepo,
+		heldOrderRepo:    heldOrderRepo,
+		ticketRepo:       ticketRepo,
+		modifierRepo:     modifierRepo,
+		recipeRepo:       recipeRepo,
+		inventoryService: inventoryService,
+		reorderService:   reorderService,
+		summaryService:   summaryService,
+		settingService:   settingService,
+		auditService:     auditService,
+		eventBus:         eventBus,
+		db:               db,
 	}
 }
 
-func (s *transactionService) Create(req *dto.CreateTransactionRequest, userID uuid.UUID) (*dto.TransactionResponse, []dto.StockWarning, error) {
+func (s *transactionService) Create(req *dto.CreateTransactionRequest, actor domain.Actor) (*dto.TransactionResponse, []dto.StockWarning, error) {
+	return s.CreateWithTx(req, actor, nil)
+}
+
+func (s *transactionService) CreateWithTx(req *dto.CreateTransactionRequest, actor domain.Actor, beforeCommit func(tx *gorm.DB, transaction *domain.Transaction) error) (*dto.TransactionResponse, []dto.StockWarning, error) {
+	userID := actor.UserID
+
 	// Check for duplicate client transaction ID (idempotency)
 	if req.ClientTransactionID != "" {
 		existing, _ := s.transactionRepo.FindByClientTransactionID(req.ClientTransactionID)
 		if existing != nil {
+			if beforeCommit != nil {
+				if err := s.db.Transaction(func(tx *gorm.DB) error { return beforeCommit(tx, existing) }); err != nil {
+					return nil, nil, err
+				}
+			}
 			return s.toTransactionResponse(existing), nil, nil
 		}
 	}
@@ -59,9 +118,16 @@ func (s *transactionService) Create(req *dto.CreateTransactionRequest, userID uu
 		}
 	}()
 
+	// Generated up front so inventory movements can reference the sale
+	transactionID := uuid.New()
+
 	// Validate and prepare transaction items
 	var transactionItems []domain.TransactionItem
-	var totalAmount float64
+	var stockChanges []stockChange
+	var totalAmount money.Money
+	var bundleLines []int
+	categories := make(map[uuid.UUID]*domain.Category)
+	taxClasses := make(map[uuid.UUID]*domain.TaxClass)
 
 	for _, itemReq := range req.Items {
 		productID, err := uuid.Parse(itemReq.ProductID)
@@ -70,79 +136,228 @@ func (s *transactionService) Create(req *dto.CreateTransactionRequest, userID uu
 			return nil, nil, fmt.Errorf("invalid product ID: %s", itemReq.ProductID)
 		}
 
-		// Get product with lock (prevent race condition)
-		var product domain.Product
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		if itemReq.Price.IsNegative() {
 			tx.Rollback()
-			return nil, nil, fmt.Errorf("product not found: %s", itemReq.ProductID)
-		}
-
-		// Check stock availability and create warning if needed
-		if product.Stock < itemReq.Quantity {
-			shortage := itemReq.Quantity - product.Stock
-			warning := dto.StockWarning{
-				ProductID:      product.ID.String(),
-				ProductName:    product.Name,
-				SoldQuantity:   itemReq.Quantity,
-				AvailableStock: product.Stock,
-				Shortage:       shortage,
-				Message:        fmt.Sprintf("Stock shortage detected for %s. Available: %d, Requested: %d, Short: %d", product.Name, product.Stock, itemReq.Quantity, shortage),
-			}
-			warnings = append(warnings, warning)
-			stockIssueDetails = append(stockIssueDetails, warning.Message)
+			return nil, nil, fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
 		}
 
-		// Update product stock (allow negative)
-		product.Stock -= itemReq.Quantity
-		product.StockVersion++
-		now := time.Now()
-		product.LastStockUpdate = &now
+		// Update product stock (allow negative) and cost the goods sold
+		baseMovement := domain.InventoryMovement{
+			MovementType:  "out",
+			ReferenceType: "transaction",
+			ReferenceID:   &transactionID,
+			UserID:        &userID,
+		}
+		// Bundles and products made to a recipe take their components from
+		// stock instead of themselves
+		needs, isBundle, err := componentNeeds(tx, s.productRepo, s.recipeRepo, productID, itemReq.Quantity)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
 
-		if err := tx.Save(&product).Error; err != nil {
+		var product domain.Product
+		var unitCost, totalCost money.Money
+		var components []domain.TransactionItemComponent
+		if len(needs) > 0 {
+			if err := tx.First(&product, productID).Error; err != nil {
+				tx.Rollback()
+				return nil, nil, fmt.Errorf("product not found: %s", productID)
+			}
+			for _, need := range needs {
+				componentMovement, componentChange, componentWarning, err := s.takeStock(tx, need.productID, need.quantity, req.HeldOrderID, baseMovement)
+				if err != nil {
+					tx.Rollback()
+					return nil, nil, err
+				}
+				stockChanges = append(stockChanges, *componentChange)
+				if componentWarning != nil {
+					warnings = append(warnings, *componentWarning)
+					stockIssueDetails = append(stockIssueDetails, componentWarning.Message)
+				}
+
+				component := domain.TransactionItemComponent{
+					ProductID:   need.productID,
+					ProductName: componentChange.product.Name,
+					Quantity:    need.quantity,
+					TotalCost:   componentMovement.TotalCost.Neg(),
+				}
+				if isBundle {
+					component.ListValue = componentChange.product.Price.Mul(int64(need.quantity))
+				}
+				components = append(components, component)
+				totalCost = totalCost.Add(component.TotalCost)
+			}
+			unitCost = totalCost.Div(int64(itemReq.Quantity), money.RoundHalfUp)
+		} else {
+			movement, change, warning, err := s.takeStock(tx, productID, itemReq.Quantity, req.HeldOrderID, baseMovement)
+			if err != nil {
+				tx.Rollback()
+				return nil, nil, err
+			}
+			product = change.product
+			stockChanges = append(stockChanges, *change)
+			if warning != nil {
+				warnings = append(warnings, *warning)
+				stockIssueDetails = append(stockIssueDetails, warning.Message)
+			}
+			unitCost = movement.UnitCost
+			totalCost = movement.TotalCost.Neg()
+		}
+
+		// Modifiers are priced by the server and may take ingredients from stock
+		groups, err := s.modifierRepo.FindByProductTx(tx, productID)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		modifiers, err := selectModifiers(&product, groups, itemReq.ModifierOptionIDs)
+		if err != nil {
 			tx.Rollback()
-			return nil, nil, fmt.Errorf("failed to update stock: %v", err)
+			return nil, nil, err
 		}
 
-		// Create transaction item
-		subtotal := itemReq.Price * float64(itemReq.Quantity)
-		transactionItems = append(transactionItems, domain.TransactionItem{
-			ProductID:    &productID,
-			ProductName:  product.Name,
-			ProductPrice: itemReq.Price,
-			Quantity:     itemReq.Quantity,
-			Subtotal:     subtotal,
-		})
+		var modifierAmount money.Money
+		for i := range modifiers {
+			modifierAmount = modifierAmount.Add(modifiers[i].PriceDelta.Mul(int64(modifiers[i].Quantity)))
+			if modifiers[i].IngredientID == nil {
+				continue
+			}
 
-		totalAmount += subtotal
+			consumed := modifiers[i].IngredientQuantity * modifiers[i].Quantity * itemReq.Quantity
+			ingredientMovement, ingredientChange, ingredientWarning, err := s.takeStock(tx, *modifiers[i].IngredientID, consumed, req.HeldOrderID, baseMovement)
+			if err != nil {
+				tx.Rollback()
+				return nil, nil, err
+			}
+			stockChanges = append(stockChanges, *ingredientChange)
+			if ingredientWarning != nil {
+				warnings = append(warnings, *ingredientWarning)
+				stockIssueDetails = append(stockIssueDetails, ingredientWarning.Message)
+			}
 
-		// Create inventory movement record
-		inventoryMovement := domain.InventoryMovement{
-			ProductID:     productID,
-			MovementType:  "out",
-			Quantity:      -itemReq.Quantity, // Negative for outgoing
-			ReferenceType: "transaction",
-			UserID:        &userID,
+			modifiers[i].IngredientQuantity = consumed
+			modifiers[i].IngredientCost = ingredientMovement.TotalCost.Neg()
+			totalCost = totalCost.Add(modifiers[i].IngredientCost)
+			unitCost = totalCost.Div(int64(itemReq.Quantity), money.RoundHalfUp)
+		}
+
+		unitPrice := itemReq.Price.Add(modifierAmount)
+		if unitPrice.IsNegative() {
+			tx.Rollback()
+			return nil, nil, fmt.Errorf("invalid price for product: %s", itemReq.ProductID)
+		}
+
+		// Create transaction item
+		subtotal := unitPrice.Mul(int64(itemReq.Quantity))
+		item := domain.TransactionItem{
+			ProductID:      &productID,
+			ProductName:    product.Name,
+			ProductPrice:   itemReq.Price,
+			Quantity:       itemReq.Quantity,
+			ModifierAmount: modifierAmount,
+			Subtotal:       subtotal,
+			UnitCost:       unitCost,
+			TotalCost:      totalCost,
+			Modifiers:      modifiers,
+			Components:     components,
 		}
-		if err := tx.Create(&inventoryMovement).Error; err != nil {
+		// Reports group by the category the product was in when it was sold
+		category, err := s.categoryOf(tx, &product, categories)
+		if err != nil {
 			tx.Rollback()
-			return nil, nil, fmt.Errorf("failed to create inventory movement: %v", err)
+			return nil, nil, err
+		}
+		if category != nil {
+			item.CategoryID = &category.ID
+			item.CategoryName = category.Name
 		}
+		taxClass, err := s.taxClassFor(tx, &product, category, taxClasses)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		if taxClass != nil {
+			item.TaxClassID = &taxClass.ID
+			item.TaxRate = taxClass.Rate
+			item.TaxInclusive = taxClass.Inclusive
+		}
+		if isBundle {
+			bundleLines = append(bundleLines, len(transactionItems))
+		}
+		transactionItems = append(transactionItems, item)
+
+		totalAmount = totalAmount.Add(subtotal)
 	}
 
 	// Calculate final amount
-	finalAmount := totalAmount - req.DiscountAmount + req.TaxAmount
+	if req.DiscountAmount.IsNegative() || req.TaxAmount.IsNegative() {
+		tx.Rollback()
+		return nil, nil, errors.New("discount and tax amounts cannot be negative")
+	}
+	if req.DiscountAmount.Cmp(totalAmount) > 0 {
+		tx.Rollback()
+		return nil, nil, errors.New("discount amount cannot exceed the total")
+	}
+
+	storeID, err := s.resolveStoreID(req, actor)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	rules := s.settingService.ChargeRules(SettingScope{StoreID: storeID, DeviceID: actor.DeviceID})
+
+	serviceChargeBase := totalAmount
+	if rules.ServiceChargeBase == ServiceChargeAfterDiscount {
+		serviceChargeBase = totalAmount.Sub(req.DiscountAmount)
+	}
+	serviceCharge := serviceChargeBase.Percent(rules.ServiceChargeRate, money.RoundHalfUp)
+
+	// Tax is worked out per line, under the line's tax class or else at the
+	// store's tax rate, and the client's tax amount is ignored
+	for i := range transactionItems {
+		item := &transactionItems[i]
+		if !rules.TaxEnabled {
+			item.TaxClassID = nil
+			item.TaxRate = 0
+			item.TaxInclusive = false
+		} else if item.TaxClassID == nil {
+			item.TaxRate = rules.TaxRate
+		}
+	}
+	taxAmount, addedTax := applyLineTaxes(transactionItems, totalAmount, req.DiscountAmount, serviceCharge, rules.ServiceChargeTaxable)
+
+	// Bundle sales are shared out to their items for reporting
+	for _, i := range bundleLines {
+		allocateBundleRevenue(&transactionItems[i])
+	}
+	finalAmount := totalAmount.Sub(req.DiscountAmount).Add(serviceCharge).Add(addedTax)
+
+	// Cash is paid in coins, so the amount due is rounded and the difference kept
+	var roundingAmount money.Money
+	if req.PaymentMethod == "cash" && rules.CashRoundingUnit > 0 {
+		rounded := finalAmount.Round(rules.CashRoundingUnit, rules.CashRoundingMode)
+		roundingAmount = rounded.Sub(finalAmount)
+		finalAmount = rounded
+	}
 
 	// Generate transaction code
 	transactionCode := s.generateTransactionCode()
 
 	// Create transaction
 	transaction := domain.Transaction{
+		ID:                  transactionID,
 		TransactionCode:     transactionCode,
 		ClientTransactionID: req.ClientTransactionID,
 		UserID:              &userID,
+		DeviceID:            actor.DeviceID,
+		StoreID:             storeID,
 		TotalAmount:         totalAmount,
 		DiscountAmount:      req.DiscountAmount,
-		TaxAmount:           req.TaxAmount,
+		TaxAmount:           taxAmount,
+		ServiceChargeRate:   rules.ServiceChargeRate,
+		ServiceChargeAmount: serviceCharge,
+		RoundingAmount:      roundingAmount,
 		FinalAmount:         finalAmount,
 		PaymentMethod:       req.PaymentMethod,
 		PaymentStatus:       "completed",
@@ -168,16 +383,64 @@ func (s *transactionService) Create(req *dto.CreateTransactionRequest, userID uu
 		return nil, nil, fmt.Errorf("failed to create transaction: %v", err)
 	}
 
+	if req.HeldOrderID != nil {
+		if err := s.heldOrderRepo.CompleteTx(tx, *req.HeldOrderID, transaction.ID, req.HeldOrderUpdatedAt); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+	if req.TicketID != nil {
+		if err := s.ticketRepo.SettleTx(tx, *req.TicketID, transaction.ID, req.TicketUpdatedAt); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+
+	if err := s.summaryService.RecordSaleTx(tx, &transaction); err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to update sales summary: %v", err)
+	}
+
+	if err := s.auditService.RecordTx(tx, actor, "create", "transaction", transaction.ID.String(), nil, transaction); err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to record audit log: %v", err)
+	}
+
 	// Update inventory movement reference IDs
 	for i := range transactionItems {
 		transactionItems[i].TransactionID = transaction.ID
 	}
 
+	if beforeCommit != nil {
+		if err := beforeCommit(tx, &transaction); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
+	for i := range stockChanges {
+		s.reorderService.CheckStockLevel(&stockChanges[i].product, stockChanges[i].previousStock)
+	}
+
+	s.publishTransactionEvent(EventTransactionCreated, &transaction)
+	if len(warnings) > 0 {
+		s.eventBus.Publish(Event{
+			Type:    EventStockIssue,
+			StoreID: transaction.StoreID,
+			UserID:  transaction.UserID,
+			Data: dto.StockIssueEvent{
+				TransactionID:   transaction.ID.String(),
+				TransactionCode: transaction.TransactionCode,
+				Warnings:        warnings,
+			},
+		})
+	}
+
 	// Reload transaction with relations
 	createdTransaction, err := s.transactionRepo.FindByID(transaction.ID)
 	if err != nil {
@@ -187,7 +450,7 @@ func (s *transactionService) Create(req *dto.CreateTransactionRequest, userID uu
 	return s.toTransactionResponse(createdTransaction), warnings, nil
 }
 
-func (s *transactionService) BulkSync(req *dto.BulkSyncTransactionRequest, userID uuid.UUID) (*dto.BulkSyncResponse, error) {
+func (s *transactionService) BulkSync(req *dto.BulkSyncTransactionRequest, actor domain.Actor) (*dto.BulkSyncResponse, error) {
 	response := &dto.BulkSyncResponse{
 		Transactions: []dto.TransactionResponse{},
 		Warnings:     []dto.StockWarning{},
@@ -195,7 +458,44 @@ func (s *transactionService) BulkSync(req *dto.BulkSyncTransactionRequest, userI
 	}
 
 	for _, txReq := range req.Transactions {
-		txResponse, warnings, err := s.Create(&txReq, userID)
+		quarantine := func(reason string) {
+			if err := s.quarantine(&txReq, actor, reason); err != nil {
+				response.FailedCount++
+				response.Errors = append(response.Errors, err.Error())
+				return
+			}
+			response.QuarantinedCount++
+			response.Quarantined = append(response.Quarantined, txReq.ClientTransactionID)
+		}
+
+		// Offline sales must be signed by the registered terminal that made
+		// them, anything else waits for a manager to review it
+		verifyErr := errors.New("offline sales must be uploaded by a registered device")
+		if actor.DeviceID != nil {
+			verifyErr = verifyTransactionSignature(&txReq, *actor.DeviceID, s.signingKeyRepo)
+		}
+		if verifyErr == nil && txReq.FinalAmount == nil {
+			verifyErr = errors.New("offline sales must include the amount charged")
+		}
+		if verifyErr != nil {
+			quarantine(verifyErr.Error())
+			continue
+		}
+
+		// Tax, service charge and rounding are worked out again here, a
+		// different total means the device charged under other prices or rules
+		var mismatch error
+		txResponse, warnings, err := s.CreateWithTx(&txReq, actor, func(tx *gorm.DB, transaction *domain.Transaction) error {
+			if transaction.FinalAmount != *txReq.FinalAmount {
+				mismatch = fmt.Errorf("device charged %s but the sale comes to %s", txReq.FinalAmount.String(), transaction.FinalAmount.String())
+				return mismatch
+			}
+			return nil
+		})
+		if mismatch != nil {
+			quarantine(mismatch.Error())
+			continue
+		}
 		if err != nil {
 			response.FailedCount++
 			response.Errors = append(response.Errors, err.Error())
@@ -210,6 +510,20 @@ func (s *transactionService) BulkSync(req *dto.BulkSyncTransactionRequest, userI
 		}
 	}
 
+	// Record sync status for the terminal that uploaded the batch
+	if actor.DeviceID != nil {
+		status := "success"
+		if response.FailedCount > 0 || response.QuarantinedCount > 0 {
+			status = "partial"
+			if response.SuccessCount == 0 {
+				status = "failed"
+			}
+		}
+		if err := s.deviceRepo.UpdateSyncStatus(*actor.DeviceID, status, response.SuccessCount, time.Now()); err != nil {
+			log.Printf("Warning: failed to update device sync status: %v", err)
+		}
+	}
+
 	return response, nil
 }
 
@@ -241,7 +555,18 @@ func (s *transactionService) GetAll(page, limit int, filters domain.TransactionF
 	return responses, totalData, nil
 }
 
-func (s *transactionService) Cancel(id string) error {
+func (s *transactionService) Export(filters domain.TransactionFilters, fn func(transaction *dto.TransactionResponse) error) error {
+	return s.transactionRepo.FindAllInBatches(filters, 500, func(transactions []domain.Transaction) error {
+		for i := range transactions {
+			if err := fn(s.toTransactionResponse(&transactions[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *transactionService) Cancel(id string, req *dto.CancelTransactionRequest, actor domain.Actor) error {
 	transactionID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid transaction ID format")
@@ -264,52 +589,410 @@ func (s *transactionService) Cancel(id string) error {
 		}
 	}()
 
-	// Restore stock for each item
+	// Restore stock for each item, or the bundle or recipe components it took,
+	// and the ingredients its modifiers took, at the cost they left with
+	restock := domain.InventoryMovement{
+		MovementType:  "in",
+		ReferenceType: "transaction_cancel",
+		ReferenceID:   &transaction.ID,
+		UserID:        &actor.UserID,
+	}
 	for _, item := range transaction.Items {
-		if item.ProductID != nil {
-			var product domain.Product
-			if err := tx.First(&product, item.ProductID).Error; err != nil {
+		productCost := item.TotalCost
+		for _, modifier := range item.Modifiers {
+			if modifier.IngredientID == nil || modifier.IngredientQuantity == 0 {
+				continue
+			}
+			productCost = productCost.Sub(modifier.IngredientCost)
+			if err := s.returnStock(tx, *modifier.IngredientID, modifier.IngredientQuantity, modifier.IngredientCost, restock); err != nil {
 				tx.Rollback()
-				return fmt.Errorf("product not found: %v", err)
+				return err
 			}
+		}
 
-			product.Stock += item.Quantity
-			product.StockVersion++
-			now := time.Now()
-			product.LastStockUpdate = &now
-
-			if err := tx.Save(&product).Error; err != nil {
+		for _, component := range item.Components {
+			productCost = productCost.Sub(component.TotalCost)
+			if err := s.returnStock(tx, component.ProductID, component.Quantity, component.TotalCost, restock); err != nil {
 				tx.Rollback()
-				return fmt.Errorf("failed to restore stock: %v", err)
+				return err
 			}
+		}
 
-			// Create inventory movement record
-			inventoryMovement := domain.InventoryMovement{
-				ProductID:     *item.ProductID,
-				MovementType:  "in",
-				Quantity:      item.Quantity,
-				ReferenceType: "transaction_cancel",
-				ReferenceID:   &transaction.ID,
-			}
-			if err := tx.Create(&inventoryMovement).Error; err != nil {
+		if item.ProductID != nil && len(item.Components) == 0 {
+			if err := s.returnStock(tx, *item.ProductID, item.Quantity, productCost, restock); err != nil {
 				tx.Rollback()
-				return fmt.Errorf("failed to create inventory movement: %v", err)
+				return err
 			}
 		}
 	}
 
-	// Update transaction status
+	// Update transaction status and who cancelled it, which may not be who sold it
+	before := map[string]interface{}{"payment_status": transaction.PaymentStatus}
+	now := time.Now()
 	transaction.PaymentStatus = "cancelled"
+	transaction.CancelledAt = &now
+	transaction.CancellationReason = req.Reason
+	if actor.UserID != uuid.Nil {
+		cancelledBy := actor.UserID
+		transaction.CancelledBy = &cancelledBy
+	}
 	if err := tx.Save(transaction).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to cancel transaction: %v", err)
 	}
 
-	return tx.Commit().Error
+	if err := s.summaryService.RecordCancellationTx(tx, transaction); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update sales summary: %v", err)
+	}
+
+	after := map[string]interface{}{
+		"payment_status":      transaction.PaymentStatus,
+		"cancelled_by":        transaction.CancelledBy,
+		"cancellation_reason": transaction.CancellationReason,
+	}
+	if err := s.auditService.RecordTx(tx, actor, "cancel", "transaction", transaction.ID.String(), before, after); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record audit log: %v", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.publishTransactionEvent(EventTransactionCancelled, transaction)
+	return nil
 }
 
 // Helper functions
 
+// publishTransactionEvent tells live dashboards about a committed sale or cancellation
+func (s *transactionService) publishTransactionEvent(eventType string, transaction *domain.Transaction) {
+	payload := dto.TransactionEvent{
+		TransactionID:      transaction.ID.String(),
+		TransactionCode:    transaction.TransactionCode,
+		FinalAmount:        transaction.FinalAmount,
+		PaymentMethod:      transaction.PaymentMethod,
+		PaymentStatus:      transaction.PaymentStatus,
+		HasStockIssue:      transaction.HasStockIssue,
+		CancellationReason: transaction.CancellationReason,
+	}
+	for _, item := range transaction.Items {
+		payload.ItemCount += item.Quantity
+	}
+	if transaction.StoreID != nil {
+		payload.StoreID = transaction.StoreID.String()
+	}
+	if transaction.UserID != nil {
+		payload.UserID = transaction.UserID.String()
+	}
+	if transaction.CancelledBy != nil {
+		payload.CancelledBy = transaction.CancelledBy.String()
+	}
+
+	s.eventBus.Publish(Event{
+		Type:    eventType,
+		StoreID: transaction.StoreID,
+		UserID:  transaction.UserID,
+		Data:    payload,
+	})
+}
+
+// stockChange remembers a product's stock before a sale so alerts can fire after commit
+type stockChange struct {
+	product       domain.Product
+	previousStock int
+}
+
+// componentNeed is stock a line takes from one component of a bundle or recipe
+type componentNeed struct {
+	productID uuid.UUID
+	quantity  int
+}
+
+// componentNeeds lists what selling quantity of a product takes from its bundle
+// items, or else from the components of its active recipe. It is empty when
+// the product's own stock is taken.
+func componentNeeds(tx *gorm.DB, productRepo domain.ProductRepository, recipeRepo domain.RecipeRepository, productID uuid.UUID, quantity int) ([]componentNeed, bool, error) {
+	bundleItems, err := productRepo.FindBundleItemsTx(tx, productID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(bundleItems) > 0 {
+		needs := make([]componentNeed, 0, len(bundleItems))
+		for _, bundleItem := range bundleItems {
+			needs = append(needs, componentNeed{productID: bundleItem.ComponentID, quantity: bundleItem.Quantity * quantity})
+		}
+		return needs, true, nil
+	}
+
+	recipe, err := recipeRepo.FindActiveByProductTx(tx, productID)
+	if err != nil || recipe == nil {
+		return nil, false, err
+	}
+	needs := make([]componentNeed, 0, len(recipe.Components))
+	for _, component := range recipe.Components {
+		needs = append(needs, componentNeed{productID: component.ComponentID, quantity: recipe.Consumption(component, quantity)})
+	}
+	return needs, false, nil
+}
+
+// takeStock locks a product and takes quantity of it out of stock, allowing
+// stock to go negative. The warning is set when the stock not reserved by
+// other held orders doesn't cover the quantity.
+func (s *transactionService) takeStock(tx *gorm.DB, productID uuid.UUID, quantity int, heldOrderID *uuid.UUID, movement domain.InventoryMovement) (*domain.InventoryMovement, *stockChange, *dto.StockWarning, error) {
+	// Get product with lock (prevent race condition)
+	var product domain.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("product not found: %s", productID)
+	}
+
+	// Stock reserved by other held orders isn't available to this sale
+	reserved, err := s.heldOrderRepo.ReservedQuantityTx(tx, productID, heldOrderID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	available := product.Stock - reserved
+
+	var warning *dto.StockWarning
+	if available < quantity {
+		shortage := quantity - available
+		warning = &dto.StockWarning{
+			ProductID:      product.ID.String(),
+			ProductName:    product.Name,
+			SoldQuantity:   quantity,
+			AvailableStock: available,
+			Shortage:       shortage,
+			Message:        fmt.Sprintf("Stock shortage detected for %s. Available: %d, Requested: %d, Short: %d", product.Name, available, quantity, shortage),
+		}
+	}
+
+	previousStock := product.Stock
+	recorded, err := s.inventoryService.RecordOut(tx, &product, quantity, movement)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return recorded, &stockChange{product: product, previousStock: previousStock}, warning, nil
+}
+
+// returnStock locks a product and puts quantity back into stock at totalCost
+func (s *transactionService) returnStock(tx *gorm.DB, productID uuid.UUID, quantity int, totalCost money.Money, movement domain.InventoryMovement) error {
+	var product domain.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		return fmt.Errorf("product not found: %v", err)
+	}
+
+	if _, err := s.inventoryService.RecordIn(tx, &product, quantity, totalCost, movement); err != nil {
+		return fmt.Errorf("failed to restore stock: %v", err)
+	}
+	return nil
+}
+
+// resolveStoreID books device sales to the device's store and falls back to
+// the store given in the request for sales without a device
+func (s *transactionService) resolveStoreID(req *dto.CreateTransactionRequest, actor domain.Actor) (*uuid.UUID, error) {
+	if actor.DeviceID != nil {
+		device, err := s.deviceRepo.FindByID(*actor.DeviceID)
+		if err != nil {
+			return nil, fmt.Errorf("device not found: %v", err)
+		}
+		return device.StoreID, nil
+	}
+
+	if req.StoreID == "" {
+		return nil, nil
+	}
+	storeID, err := uuid.Parse(req.StoreID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %s", req.StoreID)
+	}
+	return &storeID, nil
+}
+
+// quarantine stores a rejected offline sale for manual review instead of posting it
+func (s *transactionService) quarantine(req *dto.CreateTransactionRequest, actor domain.Actor, reason string) error {
+	// A retried upload of the same sale shouldn't create a second review entry
+	if req.ClientTransactionID != "" {
+		if existing, _ := s.quarantineRepo.FindPendingByClientTransactionID(req.ClientTransactionID); existing != nil {
+			return nil
+		}
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	entry := domain.QuarantinedTransaction{
+		DeviceID:            actor.DeviceID,
+		ClientTransactionID: req.ClientTransactionID,
+		Payload:             string(payload),
+		Signature:           req.Signature,
+		SigningKeyID:        req.SigningKeyID,
+		Reason:              reason,
+		Status:              "pending",
+	}
+	if actor.UserID != uuid.Nil {
+		userID := actor.UserID
+		entry.UserID = &userID
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.quarantineRepo.CreateTx(tx, &entry); err != nil {
+			return fmt.Errorf("failed to quarantine transaction: %v", err)
+		}
+		return s.auditService.RecordTx(tx, actor, "quarantine", "transaction", entry.ID.String(), nil, map[string]interface{}{
+			"client_transaction_id": entry.ClientTransactionID,
+			"reason":                reason,
+		})
+	})
+}
+
+// categoryOf returns the product's category, also when it has been deleted
+// since, or nil when it has none. Categories are cached for the sale.
+func (s *transactionService) categoryOf(tx *gorm.DB, product *domain.Product, cache map[uuid.UUID]*domain.Category) (*domain.Category, error) {
+	if product.CategoryID == nil {
+		return nil, nil
+	}
+	if category, ok := cache[*product.CategoryID]; ok {
+		return category, nil
+	}
+
+	var category *domain.Category
+	var found domain.Category
+	err := tx.Unscoped().Select("id", "name", "tax_class_id", "deleted_at").First(&found, *product.CategoryID).Error
+	if err == nil {
+		category = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load category of %s: %v", product.Name, err)
+	}
+	cache[*product.CategoryID] = category
+	return category, nil
+}
+
+// taxClassFor returns the tax class a product is sold under, its own or else its
+// category's, or nil when it has none. Classes are cached for the sale.
+func (s *transactionService) taxClassFor(tx *gorm.DB, product *domain.Product, category *domain.Category, cache map[uuid.UUID]*domain.TaxClass) (*domain.TaxClass, error) {
+	taxClassID := product.TaxClassID
+	if taxClassID == nil && category != nil && !category.DeletedAt.Valid {
+		taxClassID = category.TaxClassID
+	}
+	if taxClassID == nil {
+		return nil, nil
+	}
+
+	if taxClass, ok := cache[*taxClassID]; ok {
+		return taxClass, nil
+	}
+	var taxClass *domain.TaxClass
+	var found domain.TaxClass
+	err := tx.First(&found, *taxClassID).Error
+	if err == nil {
+		taxClass = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load tax class of %s: %v", product.Name, err)
+	}
+	cache[*taxClassID] = taxClass
+	return taxClass, nil
+}
+
+// applyLineTaxes spreads the discount and service charge over the lines in
+// proportion to their subtotals and computes each taxed line's tax on what is
+// left, adding the line's service charge to the base when it is taxable. It
+// returns the tax on all lines and the part of it added on top of the prices.
+func applyLineTaxes(items []domain.TransactionItem, totalAmount, discount, serviceCharge money.Money, serviceChargeTaxable bool) (money.Money, money.Money) {
+	discounts := allocateBySubtotal(items, totalAmount, discount)
+	serviceCharges := allocateBySubtotal(items, totalAmount, serviceCharge)
+
+	var totalTax, addedTax money.Money
+	for i := range items {
+		item := &items[i]
+		item.DiscountAmount = discounts[i]
+		item.ServiceChargeAmount = serviceCharges[i]
+		if item.TaxClassID == nil && item.TaxRate == 0 {
+			continue
+		}
+
+		net := item.Subtotal.Sub(item.DiscountAmount)
+		if item.TaxInclusive {
+			// net = base * (100 + rate) / 100, so the tax is net * rate / (100 + rate)
+			rate := int64(math.Round(item.TaxRate * 10000))
+			item.TaxAmount = net.MulRatio(rate, 100*10000+rate, money.RoundHalfUp)
+			item.TaxableAmount = net.Sub(item.TaxAmount)
+			// The service charge is added on top of the price, so its tax is too
+			if serviceChargeTaxable {
+				serviceChargeTax := item.ServiceChargeAmount.Percent(item.TaxRate, money.RoundHalfUp)
+				item.TaxableAmount = item.TaxableAmount.Add(item.ServiceChargeAmount)
+				item.TaxAmount = item.TaxAmount.Add(serviceChargeTax)
+				addedTax = addedTax.Add(serviceChargeTax)
+			}
+		} else {
+			item.TaxableAmount = net
+			if serviceChargeTaxable {
+				item.TaxableAmount = item.TaxableAmount.Add(item.ServiceChargeAmount)
+			}
+			item.TaxAmount = item.TaxableAmount.Percent(item.TaxRate, money.RoundHalfUp)
+			addedTax = addedTax.Add(item.TaxAmount)
+		}
+		totalTax = totalTax.Add(item.TaxAmount)
+	}
+	return totalTax, addedTax
+}
+
+// allocateBySubtotal splits amount over the lines in proportion to their
+// subtotals, the last line taking what rounding leaves over
+func allocateBySubtotal(items []domain.TransactionItem, totalAmount, amount money.Money) []money.Money {
+	shares := make([]money.Money, len(items))
+	remaining := amount
+	for i, item := range items {
+		if i == len(items)-1 {
+			shares[i] = remaining
+			break
+		}
+		shares[i] = amount.MulRatio(int64(item.Subtotal), int64(totalAmount), money.RoundHalfUp)
+		remaining = remaining.Sub(shares[i])
+	}
+	return shares
+}
+
+// allocateBundleRevenue shares a bundle line's sales after discount out to its
+// items by their list value, or by quantity when none of them has a price
+func allocateBundleRevenue(item *domain.TransactionItem) {
+	net := item.Subtotal.Sub(item.DiscountAmount)
+
+	byQuantity := true
+	var total int64
+	for _, component := range item.Components {
+		total += int64(component.ListValue)
+	}
+	if total > 0 {
+		byQuantity = false
+	} else {
+		for _, component := range item.Components {
+			total += int64(component.Quantity)
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	remaining := net
+	for i := range item.Components {
+		component := &item.Components[i]
+		if i == len(item.Components)-1 {
+			component.Revenue = remaining
+			break
+		}
+		weight := int64(component.ListValue)
+		if byQuantity {
+			weight = int64(component.Quantity)
+		}
+		component.Revenue = net.MulRatio(weight, total, money.RoundHalfUp)
+		remaining = remaining.Sub(component.Revenue)
+	}
+}
+
 func (s *transactionService) generateTransactionCode() string {
 	now := time.Now()
 	dateStr := now.Format("20060102")
@@ -319,21 +1002,38 @@ func (s *transactionService) generateTransactionCode() string {
 
 func (s *transactionService) toTransactionResponse(transaction *domain.Transaction) *dto.TransactionResponse {
 	response := &dto.TransactionResponse{
-		ID:                transaction.ID.String(),
-		TransactionCode:   transaction.TransactionCode,
-		TotalAmount:       transaction.TotalAmount,
-		DiscountAmount:    transaction.DiscountAmount,
-		TaxAmount:         transaction.TaxAmount,
-		FinalAmount:       transaction.FinalAmount,
-		PaymentMethod:     transaction.PaymentMethod,
-		PaymentStatus:     transaction.PaymentStatus,
-		CustomerName:      transaction.CustomerName,
-		Notes:             transaction.Notes,
-		HasStockIssue:     transaction.HasStockIssue,
-		StockIssueDetails: transaction.StockIssueDetails,
-		CreatedAt:         transaction.CreatedAt.Format(time.RFC3339),
+		ID:                  transaction.ID.String(),
+		TransactionCode:     transaction.TransactionCode,
+		TotalAmount:         transaction.TotalAmount,
+		DiscountAmount:      transaction.DiscountAmount,
+		TaxAmount:           transaction.TaxAmount,
+		ServiceChargeRate:   transaction.ServiceChargeRate,
+		ServiceChargeAmount: transaction.ServiceChargeAmount,
+		RoundingAmount:      transaction.RoundingAmount,
+		FinalAmount:         transaction.FinalAmount,
+		PaymentMethod:       transaction.PaymentMethod,
+		PaymentStatus:       transaction.PaymentStatus,
+		CustomerName:        transaction.CustomerName,
+		Notes:               transaction.Notes,
+		HasStockIssue:       transaction.HasStockIssue,
+		StockIssueDetails:   transaction.StockIssueDetails,
+		CreatedAt:           transaction.CreatedAt.Format(time.RFC3339),
+	}
+
+	if transaction.DeviceID != nil {
+		response.DeviceID = transaction.DeviceID.String()
 	}
 
+	if transaction.StoreID != nil {
+		response.StoreID = transaction.StoreID.String()
+	}
+
+	if transaction.CancelledBy != nil {
+		response.CancelledBy = transaction.CancelledBy.String()
+	}
+	response.CancelledAt = formatOptionalTime(transaction.CancelledAt)
+	response.CancellationReason = transaction.CancellationReason
+
 	if transaction.UserID != nil {
 		response.UserID = transaction.UserID.String()
 		if transaction.User != nil {
@@ -344,15 +1044,42 @@ func (s *transactionService) toTransactionResponse(transaction *domain.Transacti
 	// Convert items
 	for _, item := range transaction.Items {
 		itemResponse := dto.TransactionItemResponse{
-			ID:           item.ID.String(),
-			ProductName:  item.ProductName,
-			ProductPrice: item.ProductPrice,
-			Quantity:     item.Quantity,
-			Subtotal:     item.Subtotal,
+			ID:                  item.ID.String(),
+			ProductName:         item.ProductName,
+			ProductPrice:        item.ProductPrice,
+			Quantity:            item.Quantity,
+			ModifierAmount:      item.ModifierAmount,
+			Subtotal:            item.Subtotal,
+			DiscountAmount:      item.DiscountAmount,
+			ServiceChargeAmount: item.ServiceChargeAmount,
+			TaxRate:             item.TaxRate,
+			TaxInclusive:        item.TaxInclusive,
+			TaxableAmount:       item.TaxableAmount,
+			TaxAmount:           item.TaxAmount,
 		}
 		if item.ProductID != nil {
 			itemResponse.ProductID = item.ProductID.String()
 		}
+		if item.TaxClassID != nil {
+			itemResponse.TaxClassID = item.TaxClassID.String()
+		}
+		for _, modifier := range item.Modifiers {
+			itemResponse.Modifiers = append(itemResponse.Modifiers, dto.SelectedModifierResponse{
+				OptionID:   formatOptionalUUID(modifier.ModifierOptionID),
+				GroupName:  modifier.GroupName,
+				OptionName: modifier.OptionName,
+				PriceDelta: modifier.PriceDelta,
+				Quantity:   modifier.Quantity,
+			})
+		}
+		for _, component := range item.Components {
+			itemResponse.Components = append(itemResponse.Components, dto.TransactionItemComponentResponse{
+				ProductID:   component.ProductID.String(),
+				ProductName: component.ProductName,
+				Quantity:    component.Quantity,
+				Revenue:     component.Revenue,
+			})
+		}
 		response.Items = append(response.Items, itemResponse)
 	}
 
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"pos-backend/internal/domain"
+	"pos-backend/pkg/money"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAllocateBySubtotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		subtotals []string
+		amount    string
+		want      []string
+	}{
+		{"proportional with remainder on last line", []string{"100", "200", "300"}, "10", []string{"1.67", "3.33", "5.00"}},
+		{"single line takes everything", []string{"50"}, "7.77", []string{"7.77"}},
+		{"nothing to share", []string{"100", "200"}, "0", []string{"0.00", "0.00"}},
+		{"free lines leave it on the last line", []string{"0", "0"}, "5", []string{"0.00", "5.00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]domain.TransactionItem, len(tt.subtotals))
+			var total money.Money
+			for i, subtotal := range tt.subtotals {
+				items[i].Subtotal = money.MustParse(subtotal)
+				total = total.Add(items[i].Subtotal)
+			}
+
+			shares := allocateBySubtotal(items, total, money.MustParse(tt.amount))
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			var sum money.Money
+			for i, share := range shares {
+				if share.String() != tt.want[i] {
+					t.Errorf("share %d = %s, want %s", i, share, tt.want[i])
+				}
+				sum = sum.Add(share)
+			}
+			if sum != money.MustParse(tt.amount) {
+				t.Errorf("shares add up to %s, want %s", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestApplyLineTaxes(t *testing.T) {
+	taxClassID := uuid.New()
+
+	type line struct {
+		discount, serviceCharge, taxable, tax string
+	}
+	tests := []struct {
+		name                 string
+		items                []domain.TransactionItem
+		discount             string
+		serviceCharge        string
+		serviceChargeTaxable bool
+		want                 []line
+		wantTax              string
+		wantAdded            string
+	}{
+		{
+			name:      "exclusive tax is added on top",
+			items:     []domain.TransactionItem{{Subtotal: money.MustParse("100"), TaxClassID: &taxClassID, TaxRate: 11}},
+			want:      []line{{"0.00", "0.00", "100.00", "11.00"}},
+			wantTax:   "11.00",
+			wantAdded: "11.00",
+		},
+		{
+			name:      "inclusive tax is taken out of the price",
+			items:     []domain.TransactionItem{{Subtotal: money.MustParse("111"), TaxClassID: &taxClassID, TaxRate: 11, TaxInclusive: true}},
+			want:      []line{{"0.00", "0.00", "100.00", "11.00"}},
+			wantTax:   "11.00",
+			wantAdded: "0.00",
+		},
+		{
+			name: "discount lowers each line's base",
+			items: []domain.TransactionItem{
+				{Subtotal: money.MustParse("100"), TaxRate: 10},
+				{Subtotal: money.MustParse("300"), TaxRate: 10},
+			},
+			discount:  "40",
+			want:      []line{{"10.00", "0.00", "90.00", "9.00"}, {"30.00", "0.00", "270.00", "27.00"}},
+			wantTax:   "36.00",
+			wantAdded: "36.00",
+		},
+		{
+			name: "untaxed lines get their share but no tax",
+			items: []domain.TransactionItem{
+				{Subtotal: money.MustParse("100")},
+				{Subtotal: money.MustParse("100"), TaxClassID: &taxClassID, TaxRate: 10},
+			},
+			discount:  "20",
+			want:      []line{{"10.00", "0.00", "0.00", "0.00"}, {"10.00", "0.00", "90.00", "9.00"}},
+			wantTax:   "9.00",
+			wantAdded: "9.00",
+		},
+		{
+			name:                 "taxable service charge on an inclusive line adds tax",
+			items:                []domain.TransactionItem{{Subtotal: money.MustParse("111"), TaxClassID: &taxClassID, TaxRate: 11, TaxInclusive: true}},
+			serviceCharge:        "10",
+			serviceChargeTaxable: true,
+			want:                 []line{{"0.00", "10.00", "110.00", "12.10"}},
+			wantTax:              "12.10",
+			wantAdded:            "1.10",
+		},
+		{
+			name:          "untaxed service charge stays out of the base",
+			items:         []domain.TransactionItem{{Subtotal: money.MustParse("100"), TaxRate: 10}},
+			serviceCharge: "5",
+			want:          []line{{"0.00", "5.00", "100.00", "10.00"}},
+			wantTax:       "10.00",
+			wantAdded:     "10.00",
+		},
+		{
+			name:      "tax rounds half up",
+			items:     []domain.TransactionItem{{Subtotal: money.MustParse("0.05"), TaxRate: 11}},
+			want:      []line{{"0.00", "0.00", "0.05", "0.01"}},
+			wantTax:   "0.01",
+			wantAdded: "0.01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var total money.Money
+			for _, item := range tt.items {
+				total = total.Add(item.Subtotal)
+			}
+			discount, serviceCharge := money.Zero, money.Zero
+			if tt.discount != "" {
+				discount = money.MustParse(tt.discount)
+			}
+			if tt.serviceCharge != "" {
+				serviceCharge = money.MustParse(tt.serviceCharge)
+			}
+
+			tax, added := applyLineTaxes(tt.items, total, discount, serviceCharge, tt.serviceChargeTaxable)
+			if tax.String() != tt.wantTax || added.String() != tt.wantAdded {
+				t.Errorf("got tax %s added %s, want %s added %s", tax, added, tt.wantTax, tt.wantAdded)
+			}
+			for i, want := range tt.want {
+				item := tt.items[i]
+				got := line{item.DiscountAmount.String(), item.ServiceChargeAmount.String(), item.TaxableAmount.String(), item.TaxAmount.String()}
+				if got != want {
+					t.Errorf("line %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateBundleRevenue(t *testing.T) {
+	type component struct {
+		listValue string
+		quantity  int
+	}
+	tests := []struct {
+		name       string
+		subtotal   string
+		discount   string
+		components []component
+		want       []string
+	}{
+		{"by list value", "90", "0", []component{{"60", 1}, {"40", 1}}, []string{"54.00", "36.00"}},
+		{"after the line's discount", "100", "10", []component{{"30", 1}, {"30", 1}, {"40", 1}}, []string{"27.00", "27.00", "36.00"}},
+		{"rounding left on the last item", "100", "0", []component{{"1", 1}, {"1", 1}, {"1", 1}}, []string{"33.33", "33.33", "33.34"}},
+		{"by quantity when nothing has a price", "50", "0", []component{{"0", 1}, {"0", 4}}, []string{"10.00", "40.00"}},
+		{"nothing to share by", "50", "0", []component{{"0", 0}}, []string{"0.00"}},
+		{"no components", "50", "0", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &domain.TransactionItem{Subtotal: money.MustParse(tt.subtotal), DiscountAmount: money.MustParse(tt.discount)}
+			for _, c := range tt.components {
+				item.Components = append(item.Components, domain.TransactionItemComponent{ListValue: money.MustParse(c.listValue), Quantity: c.quantity})
+			}
+
+			allocateBundleRevenue(item)
+			for i, want := range tt.want {
+				if got := item.Components[i].Revenue.String(); got != want {
+					t.Errorf("component %d revenue = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// signedTransactionItem and signedTransactionPayload define the canonical content a
+// device signs. Fields are serialized as compact JSON in exactly this order, amounts
+// always have two decimals (e.g. 15000.00), and the signature fields themselves are excluded.
+type signedTransactionItem struct {
+	ProductID         string      `json:"product_id"`
+	Quantity          int         `json:"quantity"`
+	Price             money.Money `json:"price"`
+	ModifierOptionIDs []string    `json:"modifier_option_ids,omitempty"` // Left out when empty, so lines without modifiers sign as before
+}
+
+type signedTransactionPayload struct {
+	DeviceID            string                  `json:"device_id"`
+	ClientTransactionID string                  `json:"client_transaction_id"`
+	Items               []signedTransactionItem `json:"items"`
+	PaymentMethod       string                  `json:"payment_method"`
+	CustomerName        string                  `json:"customer_name"`
+	DiscountAmount      money.Money             `json:"discount_amount"`
+	TaxAmount           money.Money             `json:"tax_amount"`
+	Notes               string                  `json:"notes"`
+	FinalAmount         *money.Money            `json:"final_amount,omitempty"` // Left out when not sent, so older payloads sign as before
+}
+
+// canonicalTransactionPayload returns the bytes a device must sign for req
+func canonicalTransactionPayload(req *dto.CreateTransactionRequest, deviceID uuid.UUID) ([]byte, error) {
+	payload := signedTransactionPayload{
+		DeviceID:            deviceID.String(),
+		ClientTransactionID: req.ClientTransactionID,
+		Items:               make([]signedTransactionItem, 0, len(req.Items)),
+		PaymentMethod:       req.PaymentMethod,
+		CustomerName:        req.CustomerName,
+		DiscountAmount:      req.DiscountAmount,
+		TaxAmount:           req.TaxAmount,
+		Notes:               req.Notes,
+		FinalAmount:         req.FinalAmount,
+	}
+	for _, item := range req.Items {
+		payload.Items = append(payload.Items, signedTransactionItem{
+			ProductID:         item.ProductID,
+			Quantity:          item.Quantity,
+			Price:             item.Price,
+			ModifierOptionIDs: item.ModifierOptionIDs,
+		})
+	}
+
+	return json.Marshal(payload)
+}
+
+// verifyTransactionSignature checks req was signed by an active key of the device
+func verifyTransactionSignature(req *dto.CreateTransactionRequest, deviceID uuid.UUID, keyRepo domain.DeviceSigningKeyRepository) error {
+	if req.Signature == "" || req.SigningKeyID == "" {
+		return errors.New("missing signature")
+	}
+	if req.ClientTransactionID == "" {
+		return errors.New("signed transactions require a client transaction ID")
+	}
+
+	keyID, err := uuid.Parse(req.SigningKeyID)
+	if err != nil {
+		return errors.New("invalid signing key ID")
+	}
+
+	key, err := keyRepo.FindByID(keyID)
+	if err != nil {
+		return errors.New("unknown signing key")
+	}
+	if key.DeviceID != deviceID {
+		return errors.New("signing key does not belong to this device")
+	}
+	if key.Status != "active" {
+		return errors.New("signing key has been retired")
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("stored signing key is invalid")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return errors.New("signature is not valid base64")
+	}
+
+	payload, err := canonicalTransactionPayload(req, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), payload, signature) {
+		return errors.New("signature does not match transaction content")
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"pos-backend/internal/domain"
+	"pos-backend/internal/dto"
+	"pos-backend/pkg/money"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// signingKeys is a key store holding the keys of one test
+type signingKeys struct {
+	domain.DeviceSigningKeyRepository
+	keys map[uuid.UUID]*domain.DeviceSigningKey
+}
+
+func (r *signingKeys) FindByID(id uuid.UUID) (*domain.DeviceSigningKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return key, nil
+}
+
+func TestCanonicalTransactionPayload(t *testing.T) {
+	deviceID := uuid.MustParse("6f1c2b3a-0000-4000-8000-000000000001")
+	finalAmount := money.MustParse("27750")
+
+	tests := []struct {
+		name string
+		req  dto.CreateTransactionRequest
+		want string
+	}{
+		{
+			name: "plain sale",
+			req: dto.CreateTransactionRequest{
+				ClientTransactionID: "pos-1",
+				Items:               []dto.TransactionItemRequest{{ProductID: "p-1", Quantity: 2, Price: money.MustParse("15000")}},
+				PaymentMethod:       "cash",
+				Notes:               "table 4",
+			},
+			want: `{"device_id":"6f1c2b3a-0000-4000-8000-000000000001","client_transaction_id":"pos-1",` +
+				`"items":[{"product_id":"p-1","quantity":2,"price":15000.00}],` +
+				`"payment_method":"cash","customer_name":"","discount_amount":0.00,"tax_amount":0.00,"notes":"table 4"}`,
+		},
+		{
+			name: "modifiers and final amount",
+			req: dto.CreateTransactionRequest{
+				ClientTransactionID: "pos-2",
+				Items:               []dto.TransactionItemRequest{{ProductID: "p-1", Quantity: 1, Price: money.MustParse("25000.5"), ModifierOptionIDs: []string{"m-1", "m-1"}}},
+				PaymentMethod:       "qris",
+				CustomerName:        "Ayu",
+				DiscountAmount:      money.MustParse("500"),
+				FinalAmount:         &finalAmount,
+				Signature:           "ignored",
+				SigningKeyID:        "ignored",
+			},
+			want: `{"device_id":"6f1c2b3a-0000-4000-8000-000000000001","client_transaction_id":"pos-2",` +
+				`"items":[{"product_id":"p-1","quantity":1,"price":25000.50,"modifier_option_ids":["m-1","m-1"]}],` +
+				`"payment_method":"qris","customer_name":"Ayu","discount_amount":500.00,"tax_amount":0.00,"notes":"","final_amount":27750.00}`,
+		},
+		{
+			name: "no items",
+			req:  dto.CreateTransactionRequest{ClientTransactionID: "pos-3", PaymentMethod: "card"},
+			want: `{"device_id":"6f1c2b3a-0000-4000-8000-000000000001","client_transaction_id":"pos-3",` +
+				`"items":[],"payment_method":"card","customer_name":"","discount_amount":0.00,"tax_amount":0.00,"notes":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := canonicalTransactionPayload(&tt.req, deviceID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(payload) != tt.want {
+				t.Errorf("payload\n got %s\nwant %s", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTransactionSignature(t *testing.T) {
+	deviceID := uuid.New()
+	privateKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	otherKey := ed25519.NewKeyFromSeed([]byte(strings.Repeat("x", ed25519.SeedSize)))
+
+	sign := func(t *testing.T, req *dto.CreateTransactionRequest, key ed25519.PrivateKey) {
+		t.Helper()
+		payload, err := canonicalTransactionPayload(req, deviceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	}
+
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey)
+		wantErr string
+	}{
+		{name: "valid signature"},
+		{
+			name: "no signature",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.Signature = ""
+			},
+			wantErr: "missing signature",
+		},
+		{
+			name: "no client transaction ID",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.ClientTransactionID = ""
+			},
+			wantErr: "require a client transaction ID",
+		},
+		{
+			name: "malformed key ID",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.SigningKeyID = "key-1"
+			},
+			wantErr: "invalid signing key ID",
+		},
+		{
+			name: "unknown key",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.SigningKeyID = uuid.NewString()
+			},
+			wantErr: "unknown signing key",
+		},
+		{
+			name: "key of another device",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				key.DeviceID = uuid.New()
+			},
+			wantErr: "does not belong to this device",
+		},
+		{
+			name: "retired key",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				key.Status = "retired"
+			},
+			wantErr: "has been retired",
+		},
+		{
+			name: "corrupt stored key",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				key.PublicKey = "c2hvcnQ="
+			},
+			wantErr: "stored signing key is invalid",
+		},
+		{
+			name: "signature not base64",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.Signature = "not base64!"
+			},
+			wantErr: "not valid base64",
+		},
+		{
+			name: "price changed after signing",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.Items[0].Price = money.MustParse("1")
+			},
+			wantErr: "does not match transaction content",
+		},
+		{
+			name: "modifier added after signing",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				req.Items[0].ModifierOptionIDs = append(req.Items[0].ModifierOptionIDs, uuid.NewString())
+			},
+			wantErr: "does not match transaction content",
+		},
+		{
+			name: "final amount changed after signing",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				lower := money.MustParse("1000")
+				req.FinalAmount = &lower
+			},
+			wantErr: "does not match transaction content",
+		},
+		{
+			name: "signed by another key",
+			change: func(t *testing.T, req *dto.CreateTransactionRequest, key *domain.DeviceSigningKey) {
+				sign(t, req, otherKey)
+			},
+			wantErr: "does not match transaction content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &domain.DeviceSigningKey{
+				ID:        uuid.New(),
+				DeviceID:  deviceID,
+				Algorithm: "ed25519",
+				PublicKey: base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)),
+				Status:    "active",
+			}
+			finalAmount := money.MustParse("30000")
+			req := &dto.CreateTransactionRequest{
+				ClientTransactionID: "pos-1",
+				Items:               []dto.TransactionItemRequest{{ProductID: uuid.NewString(), Quantity: 2, Price: money.MustParse("15000")}},
+				PaymentMethod:       "cash",
+				FinalAmount:         &finalAmount,
+				SigningKeyID:        key.ID.String(),
+			}
+			sign(t, req, privateKey)
+			if tt.change != nil {
+				tt.change(t, req, key)
+			}
+
+			err := verifyTransactionSignature(req, deviceID, &signingKeys{keys: map[uuid.UUID]*domain.DeviceSigningKey{key.ID: key}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
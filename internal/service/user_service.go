@@ -13,18 +13,22 @@ import (
 type UserService interface {
 	GetAll(page, limit int) ([]dto.UserResponse, int64, error)
 	GetByID(id string) (*dto.UserResponse, error)
-	Update(id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
-	Delete(id string) error
-	ChangePassword(id string, req *dto.ChangePasswordRequest) error
+	Update(id string, req *dto.UpdateUserRequest, actor domain.Actor) (*dto.UserResponse, error)
+	Delete(id string, actor domain.Actor) error
+	ChangePassword(id string, req *dto.ChangePasswordRequest, actor domain.Actor) error
 }
 
 type userService struct {
-	userRepo domain.UserRepository
+	userRepo     domain.UserRepository
+	auditService AuditService
+	db           *gorm.DB
 }
 
-func NewUserService(userRepo domain.UserRepository) UserService {
+func NewUserService(userRepo domain.UserRepository, auditService AuditService, db *gorm.DB) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		db:           db,
 	}
 }
 
@@ -84,7 +88,7 @@ func (s *userService) GetByID(id string) (*dto.UserResponse, error) {
 	}, nil
 }
 
-func (s *userService) Update(id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (s *userService) Update(id string, req *dto.UpdateUserRequest, actor domain.Actor) (*dto.UserResponse, error) {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, errors.New("invalid user ID format")
@@ -98,6 +102,7 @@ func (s *userService) Update(id string, req *dto.UpdateUserRequest) (*dto.UserRe
 		}
 		return nil, err
 	}
+	before := *user
 
 	// Update fields if provided
 	if req.Email != "" {
@@ -122,7 +127,12 @@ func (s *userService) Update(id string, req *dto.UpdateUserRequest) (*dto.UserRe
 	}
 
 	// Save updates
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).Update(user); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "update", "user", user.ID.String(), before, user)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -137,14 +147,14 @@ func (s *userService) Update(id string, req *dto.UpdateUserRequest) (*dto.UserRe
 	}, nil
 }
 
-func (s *userService) Delete(id string) error {
+func (s *userService) Delete(id string, actor domain.Actor) error {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid user ID format")
 	}
 
 	// Check if user exists
-	_, err = s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("user not found")
@@ -152,10 +162,19 @@ func (s *userService) Delete(id string) error {
 		return err
 	}
 
-	return s.userRepo.Delete(userID)
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).Delete(userID); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "delete", "user", user.ID.String(), user, nil)
+	}); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (s *userService) ChangePassword(id string, req *dto.ChangePasswordRequest) error {
+func (s *userService) ChangePassword(id string, req *dto.ChangePasswordRequest, actor domain.Actor) error {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid user ID format")
@@ -183,5 +202,11 @@ func (s *userService) ChangePassword(id string, req *dto.ChangePasswordRequest)
 
 	user.Password = hashedPassword
 
-	return s.userRepo.Update(user)
+	// Password hashes are never written to the audit log, only the fact it changed
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).Update(user); err != nil {
+			return err
+		}
+		return s.auditService.RecordTx(tx, actor, "change_password", "user", user.ID.String(), nil, nil)
+	})
 }
@@ -0,0 +1,133 @@
+package businessday
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// timezoneName guards the zone name before it is inlined into SQL
+var timezoneName = regexp.MustCompile(`^[A-Za-z0-9_+\-/]+$`)
+
+// Calendar maps instants to store business days. A business day starts at the
+// cutoff in the store timezone, so with a 04:00 cutoff a sale at 01:30 on the
+// 2nd still belongs to the 1st.
+type Calendar struct {
+	Location *time.Location
+	Cutoff   time.Duration
+}
+
+// New builds a calendar from an IANA timezone name and an "HH:MM" cutoff
+func New(timezone, cutoff string) (Calendar, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if !timezoneName.MatchString(timezone) {
+		return Calendar{}, fmt.Errorf("invalid timezone %q", timezone)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("invalid timezone %q", timezone)
+	}
+
+	cutoffDuration, err := ParseCutoff(cutoff)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	return Calendar{Location: location, Cutoff: cutoffDuration}, nil
+}
+
+// ParseCutoff reads "HH:MM" between 00:00 and 23:59, empty means midnight
+func ParseCutoff(cutoff string) (time.Duration, error) {
+	if cutoff == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business day cutoff %q, use HH:MM", cutoff)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// DayStart returns the instant the business day for date begins
+func (c Calendar) DayStart(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, c.location()).Add(c.Cutoff)
+}
+
+// BusinessDate returns the business day t falls in, as midnight of that date in the store timezone
+func (c Calendar) BusinessDate(t time.Time) time.Time {
+	local := t.In(c.location()).Add(-c.Cutoff)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location())
+}
+
+// Day returns the [start, end) instants of the business day containing t
+func (c Calendar) Day(t time.Time) (time.Time, time.Time) {
+	date := c.BusinessDate(t)
+	start := c.DayStart(date.Year(), date.Month(), date.Day())
+	end := c.DayStart(date.Year(), date.Month(), date.Day()+1)
+	return start, end
+}
+
+// Today returns the [start, end) instants of the current business day
+func (c Calendar) Today() (time.Time, time.Time) {
+	return c.Day(time.Now())
+}
+
+// Range turns inclusive YYYY-MM-DD business dates into [start, end) instants.
+// Either side may be empty, in which case the matching pointer is nil.
+func (c Calendar) Range(startDate, endDate string) (*time.Time, *time.Time, error) {
+	var start, end *time.Time
+
+	if startDate != "" {
+		date, err := time.Parse(dateLayout, startDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid start_date %q, use YYYY-MM-DD", startDate)
+		}
+		s := c.DayStart(date.Year(), date.Month(), date.Day())
+		start = &s
+	}
+	if endDate != "" {
+		date, err := time.Parse(dateLayout, endDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid end_date %q, use YYYY-MM-DD", endDate)
+		}
+		e := c.DayStart(date.Year(), date.Month(), date.Day()+1)
+		end = &e
+	}
+
+	return start, end, nil
+}
+
+// DateSQL returns a Postgres expression giving the business date of a timestamptz column
+func (c Calendar) DateSQL(column string) string {
+	return fmt.Sprintf("DATE((%s AT TIME ZONE '%s') - INTERVAL '%d minutes')", column, c.zoneName(), int(c.Cutoff.Minutes()))
+}
+
+// LocalTimeSQL returns a Postgres expression giving the wall-clock time of a timestamptz column in the store timezone
+func (c Calendar) LocalTimeSQL(column string) string {
+	return fmt.Sprintf("(%s AT TIME ZONE '%s')", column, c.zoneName())
+}
+
+// Format returns the YYYY-MM-DD business date of t
+func (c Calendar) Format(t time.Time) string {
+	return c.BusinessDate(t).Format(dateLayout)
+}
+
+func (c Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+func (c Calendar) zoneName() string {
+	name := c.location().String()
+	if name == "Local" || !timezoneName.MatchString(name) {
+		return "UTC"
+	}
+	return strings.ReplaceAll(name, "'", "")
+}
@@ -0,0 +1,152 @@
+package businessday
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func TestCalendarBusinessDate(t *testing.T) {
+	utc := Calendar{Location: time.UTC}
+	utcCutoff := Calendar{Location: time.UTC, Cutoff: 4 * time.Hour}
+	wibCutoff := Calendar{Location: wib, Cutoff: 4 * time.Hour}
+
+	tests := []struct {
+		name     string
+		calendar Calendar
+		at       time.Time
+		want     string
+	}{
+		{"midnight cutoff keeps the calendar date", utc, time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC), "2024-03-01"},
+		{"no location means UTC", Calendar{}, time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC), "2024-03-01"},
+		{"before the cutoff belongs to the previous day", utcCutoff, time.Date(2024, 3, 2, 1, 30, 0, 0, time.UTC), "2024-03-01"},
+		{"just before the cutoff", utcCutoff, time.Date(2024, 3, 2, 3, 59, 59, 0, time.UTC), "2024-03-01"},
+		{"at the cutoff starts the new day", utcCutoff, time.Date(2024, 3, 2, 4, 0, 0, 0, time.UTC), "2024-03-02"},
+		{"after the cutoff", utcCutoff, time.Date(2024, 3, 2, 15, 0, 0, 0, time.UTC), "2024-03-02"},
+		{"before the cutoff across a month end", utcCutoff, time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC), "2024-02-29"},
+		{"store zone is ahead of UTC", Calendar{Location: wib}, time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC), "2024-03-02"},
+		{"before the cutoff in the store zone", wibCutoff, time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC), "2024-03-01"},
+		{"at the cutoff in the store zone", wibCutoff, time.Date(2024, 3, 1, 21, 0, 0, 0, time.UTC), "2024-03-02"},
+		{"instant given in another zone", wibCutoff, time.Date(2024, 3, 2, 3, 30, 0, 0, wib), "2024-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.calendar.BusinessDate(tt.at)
+			if got.Format(dateLayout) != tt.want {
+				t.Errorf("BusinessDate = %s, want %s", got.Format(dateLayout), tt.want)
+			}
+			if got.Hour() != 0 || got.Minute() != 0 || got.Location() != tt.calendar.location() {
+				t.Errorf("BusinessDate = %v, want midnight in %v", got, tt.calendar.location())
+			}
+			if format := tt.calendar.Format(tt.at); format != tt.want {
+				t.Errorf("Format = %s, want %s", format, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarDay(t *testing.T) {
+	tests := []struct {
+		name      string
+		calendar  Calendar
+		at        time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "midnight cutoff",
+			calendar:  Calendar{Location: time.UTC},
+			at:        time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "sale before the cutoff",
+			calendar:  Calendar{Location: time.UTC, Cutoff: 4 * time.Hour},
+			at:        time.Date(2024, 3, 2, 1, 30, 0, 0, time.UTC),
+			wantStart: time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 2, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "sale after the cutoff",
+			calendar:  Calendar{Location: time.UTC, Cutoff: 4 * time.Hour},
+			at:        time.Date(2024, 3, 2, 4, 30, 0, 0, time.UTC),
+			wantStart: time.Date(2024, 3, 2, 4, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 3, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "store zone with a cutoff",
+			calendar:  Calendar{Location: wib, Cutoff: 4 * time.Hour},
+			at:        time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC),
+			wantStart: time.Date(2024, 2, 29, 21, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 21, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.calendar.Day(tt.at)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("Day = [%v, %v), want [%v, %v)", start.UTC(), end.UTC(), tt.wantStart, tt.wantEnd)
+			}
+			if tt.at.Before(start) || !tt.at.Before(end) {
+				t.Errorf("Day [%v, %v) does not contain %v", start.UTC(), end.UTC(), tt.at)
+			}
+		})
+	}
+}
+
+func TestCalendarRange(t *testing.T) {
+	calendar := Calendar{Location: wib, Cutoff: 4 * time.Hour}
+	instant := func(year int, month time.Month, day, hour int) *time.Time {
+		t := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		wantStart *time.Time
+		wantEnd   *time.Time
+		wantErr   string
+	}{
+		{name: "open on both sides"},
+		{name: "start only", startDate: "2024-03-01", wantStart: instant(2024, 2, 29, 21)},
+		{name: "end only", endDate: "2024-03-01", wantEnd: instant(2024, 3, 1, 21)},
+		{name: "end equal to start covers that one day", startDate: "2024-03-01", endDate: "2024-03-01", wantStart: instant(2024, 2, 29, 21), wantEnd: instant(2024, 3, 1, 21)},
+		{name: "end is inclusive across a month end", startDate: "2024-02-28", endDate: "2024-02-29", wantStart: instant(2024, 2, 27, 21), wantEnd: instant(2024, 2, 29, 21)},
+		{name: "start not a date", startDate: "03/01/2024", wantErr: "invalid start_date"},
+		{name: "start month out of range", startDate: "2024-13-01", endDate: "2024-03-01", wantErr: "invalid start_date"},
+		{name: "end day out of range", startDate: "2024-02-01", endDate: "2024-02-30", wantErr: "invalid end_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := calendar.Range(tt.startDate, tt.endDate)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sameInstant(start, tt.wantStart) || !sameInstant(end, tt.wantEnd) {
+				t.Errorf("Range = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// sameInstant compares optional instants, nil only matching nil
+func sameInstant(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
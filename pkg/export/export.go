@@ -0,0 +1,150 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"pos-backend/pkg/money"
+	"strconv"
+	"time"
+)
+
+// Format is a spreadsheet file format a report can be downloaded in
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat accepts "csv" or "xlsx"
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case CSV, XLSX:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("unsupported export format %q, use csv or xlsx", value)
+}
+
+// FormatFromContentType maps an Accept header media type to a format
+func FormatFromContentType(contentType string) (Format, bool) {
+	switch contentType {
+	case "text/csv":
+		return CSV, true
+	case xlsxContentType:
+		return XLSX, true
+	}
+	return "", false
+}
+
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return xlsxContentType
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+type cellKind int
+
+const (
+	textCell cellKind = iota
+	numberCell
+	moneyCell
+	dateCell
+	dateTimeCell
+)
+
+// Cell is one typed spreadsheet value. XLSX keeps the type, CSV writes it as text.
+type Cell struct {
+	kind  cellKind
+	text  string
+	value string
+	time  time.Time
+}
+
+func Text(s string) Cell {
+	return Cell{kind: textCell, text: s}
+}
+
+func Int(n int64) Cell {
+	value := strconv.FormatInt(n, 10)
+	return Cell{kind: numberCell, text: value, value: value}
+}
+
+func Float(f float64) Cell {
+	value := strconv.FormatFloat(f, 'f', 2, 64)
+	return Cell{kind: numberCell, text: value, value: value}
+}
+
+// Money keeps the exact two-decimal value and a currency number format
+func Money(m money.Money) Cell {
+	value := m.String()
+	return Cell{kind: moneyCell, text: value, value: value}
+}
+
+func Date(t time.Time) Cell {
+	return Cell{kind: dateCell, text: t.Format("2006-01-02"), time: t}
+}
+
+func DateTime(t time.Time) Cell {
+	return Cell{kind: dateTimeCell, text: t.Format("2006-01-02 15:04:05"), time: t}
+}
+
+// Writer streams rows to the client as they are produced
+type Writer interface {
+	// WriteHeader writes a row of column titles
+	WriteHeader(columns ...string) error
+	WriteRow(cells ...Cell) error
+	// Close flushes buffered rows and finishes the file
+	Close() error
+}
+
+// NewWriter returns a streaming writer for format. sheetName is only used by XLSX.
+func NewWriter(format Format, w io.Writer, sheetName string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w, sheetName)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// HeaderBlock describes the report at the top of every export
+type HeaderBlock struct {
+	StoreName   string
+	Title       string
+	StartDate   string
+	EndDate     string
+	GeneratedAt time.Time
+}
+
+// WriteHeaderBlock writes the store, report title, period and generation time followed by a blank row
+func WriteHeaderBlock(w Writer, block HeaderBlock) error {
+	period := "All time"
+	switch {
+	case block.StartDate != "" && block.EndDate != "":
+		period = block.StartDate + " to " + block.EndDate
+	case block.StartDate != "":
+		period = "From " + block.StartDate
+	case block.EndDate != "":
+		period = "Until " + block.EndDate
+	}
+
+	rows := [][]Cell{
+		{Text("Store"), Text(block.StoreName)},
+		{Text("Report"), Text(block.Title)},
+		{Text("Period"), Text(period)},
+		{Text("Generated"), DateTime(block.GeneratedAt)},
+		{},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
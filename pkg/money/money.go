@@ -0,0 +1,370 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount stored as hundredths of the currency unit, matching
+// the decimal(15,2) columns. Use it instead of float64 for prices and totals.
+type Money int64
+
+// Scale is the number of Money units in one whole currency unit
+const Scale = 100
+
+// RoundingMode decides what happens to a remainder when an amount can't be represented exactly
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // Ties go away from zero (2.5 -> 3, -2.5 -> -3)
+	RoundHalfEven                     // Banker's rounding, ties go to the even neighbour (2.5 -> 2, 3.5 -> 4)
+	RoundDown                         // Toward zero
+	RoundUp                           // Away from zero
+)
+
+// Zero is the zero amount
+const Zero Money = 0
+
+// IDRCashDenomination is the smallest coin in everyday circulation (Rp100)
+const IDRCashDenomination Money = 100 * Scale
+
+var errInvalidAmount = errors.New("invalid money amount")
+
+// FromInt returns a whole-unit amount, e.g. FromInt(15000) is 15000.00
+func FromInt(units int64) Money {
+	return Money(units * Scale)
+}
+
+// FromFloat converts a float using its shortest decimal representation, rounding half-up
+func FromFloat(f float64) Money {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	m, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return m
+}
+
+// Parse reads a decimal string such as "15000", "-12.5" or "99.999" exactly,
+// rounding anything beyond two decimal places half-up
+func Parse(s string) (Money, error) {
+	return ParseRounded(s, RoundHalfUp)
+}
+
+// ParseRounded is Parse with an explicit rounding mode for extra decimal places
+func ParseRounded(s string, mode RoundingMode) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, errInvalidAmount
+	}
+
+	// Database drivers may return exponent notation for very small averages
+	if strings.ContainsAny(s, "eE") {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return Zero, errInvalidAmount
+		}
+		return fromRat(r, mode)
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Zero, errInvalidAmount
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return Zero, errInvalidAmount
+			}
+		}
+	}
+
+	// Keep two decimals and round the rest
+	kept := fracPart
+	rest := ""
+	if len(kept) > 2 {
+		kept, rest = fracPart[:2], fracPart[2:]
+	}
+	for len(kept) < 2 {
+		kept += "0"
+	}
+
+	units, err := strconv.ParseInt(intPart+kept, 10, 64)
+	if err != nil {
+		return Zero, errInvalidAmount
+	}
+
+	if rest != "" && strings.Trim(rest, "0") != "" {
+		firstDigit := rest[0] - '0'
+		tail := strings.Trim(rest[1:], "0") != ""
+		if roundAway(mode, firstDigit, tail, units%2 != 0) {
+			units++
+		}
+	}
+
+	if negative {
+		units = -units
+	}
+	return Money(units), nil
+}
+
+// MustParse is Parse for constants known to be valid
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: %q is not a valid amount", s))
+	}
+	return m
+}
+
+// Sum adds all amounts
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+func (m Money) Add(other Money) Money { return m + other }
+
+func (m Money) Sub(other Money) Money { return m - other }
+
+// Mul multiplies by a whole quantity, which is always exact
+func (m Money) Mul(quantity int64) Money { return m * Money(quantity) }
+
+func (m Money) Neg() Money { return -m }
+
+func (m Money) IsZero() bool { return m == 0 }
+
+func (m Money) IsNegative() bool { return m < 0 }
+
+// Cmp returns -1, 0 or 1
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m < other:
+		return -1
+	case m > other:
+		return 1
+	}
+	return 0
+}
+
+// MulRatio returns m * numerator / denominator rounded with mode
+func (m Money) MulRatio(numerator, denominator int64, mode RoundingMode) Money {
+	if denominator == 0 {
+		return Zero
+	}
+	r := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(numerator)),
+		new(big.Int).Mul(big.NewInt(Scale), big.NewInt(denominator)),
+	)
+	result, _ := fromRat(r, mode)
+	return result
+}
+
+// Percent returns percent% of m (e.g. Percent(11, RoundHalfUp) for PPN), accurate to 4 decimal places of percent
+func (m Money) Percent(percent float64, mode RoundingMode) Money {
+	const percentScale = 10000
+	return m.MulRatio(int64(math.Round(percent*percentScale)), 100*percentScale, mode)
+}
+
+// Div splits m into n parts rounded with mode, e.g. for averages
+func (m Money) Div(n int64, mode RoundingMode) Money {
+	if n == 0 {
+		return Zero
+	}
+	return m.MulRatio(1, n, mode)
+}
+
+// Round rounds m to a multiple of unit, e.g. Round(IDRCashDenomination, RoundHalfUp) for cash
+func (m Money) Round(unit Money, mode RoundingMode) Money {
+	if unit <= 0 {
+		return m
+	}
+	quotient := int64(m / unit)
+	remainder := int64(m % unit)
+	if remainder == 0 {
+		return m
+	}
+
+	negative := remainder < 0
+	if negative {
+		remainder = -remainder
+	}
+
+	// Compare the remainder with half a unit without losing precision
+	var firstDigit byte
+	twice := 2 * remainder
+	switch {
+	case twice > int64(unit):
+		firstDigit = 6
+	case twice == int64(unit):
+		firstDigit = 5
+	default:
+		firstDigit = 4
+	}
+	tail := false
+	if mode == RoundDown || mode == RoundUp {
+		firstDigit, tail = 0, true
+	}
+
+	if roundAway(mode, firstDigit, tail, quotient%2 != 0) {
+		if negative {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return Money(quotient) * unit
+}
+
+// Float64 is for ratios and percentages only, never feed it back into amounts
+func (m Money) Float64() float64 {
+	return float64(m) / Scale
+}
+
+// String formats with exactly two decimals, e.g. "15000.50" or "-0.05"
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/Scale, units%Scale)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string without going through float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		*m = Zero
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	parsed, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("money: cannot parse %s", string(data))
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as an exact numeric string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads numeric columns and aggregates such as SUM and AVG
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Zero
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case int64:
+		*m = FromInt(v)
+	case float64:
+		*m = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", value)
+	}
+	return nil
+}
+
+// Helper functions
+
+// roundAway decides whether to move away from zero given the first dropped
+// digit, whether any non-zero digits follow it and whether the kept value is odd
+func roundAway(mode RoundingMode, firstDigit byte, tail, odd bool) bool {
+	dropped := firstDigit != 0 || tail
+	switch mode {
+	case RoundDown:
+		return false
+	case RoundUp:
+		return dropped
+	case RoundHalfEven:
+		if firstDigit > 5 || (firstDigit == 5 && tail) {
+			return true
+		}
+		if firstDigit == 5 {
+			return odd
+		}
+		return false
+	default: // RoundHalfUp
+		return firstDigit >= 5
+	}
+}
+
+func fromRat(r *big.Rat, mode RoundingMode) (Money, error) {
+	scaled := new(big.Rat).Mul(r, big.NewRat(Scale, 1))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if !quotient.IsInt64() {
+		return Zero, errInvalidAmount
+	}
+
+	units := quotient.Int64()
+	if remainder.Sign() != 0 {
+		// Compare 2*|remainder| with the denominator to find which side of half we're on
+		twice := new(big.Int).Abs(remainder)
+		twice.Lsh(twice, 1)
+		var firstDigit byte
+		switch twice.Cmp(scaled.Denom()) {
+		case 1:
+			firstDigit = 6
+		case 0:
+			firstDigit = 5
+		default:
+			firstDigit = 4
+		}
+		tail := false
+		if mode == RoundDown || mode == RoundUp {
+			firstDigit, tail = 0, true
+		}
+		if roundAway(mode, firstDigit, tail, units%2 != 0) {
+			if remainder.Sign() < 0 {
+				units--
+			} else {
+				units++
+			}
+		}
+	}
+
+	return Money(units), nil
+}
@@ -0,0 +1,137 @@
+package money
+
+import "testing"
+
+func TestParseRounded(t *testing.T) {
+	tests := []struct {
+		input   string
+		mode    RoundingMode
+		want    Money
+		wantErr bool
+	}{
+		{input: "15000", mode: RoundHalfUp, want: 1500000},
+		{input: "-12.5", mode: RoundHalfUp, want: -1250},
+		{input: "+0.1", mode: RoundHalfUp, want: 10},
+		{input: ".75", mode: RoundHalfUp, want: 75},
+		{input: "99.999", mode: RoundHalfUp, want: 10000},
+		{input: "0.005", mode: RoundHalfUp, want: 1},
+		{input: "-0.005", mode: RoundHalfUp, want: -1},
+		{input: "0.004999", mode: RoundHalfUp, want: 0},
+		{input: "0.125", mode: RoundHalfEven, want: 12},
+		{input: "0.135", mode: RoundHalfEven, want: 14},
+		{input: "0.1251", mode: RoundHalfEven, want: 13},
+		{input: "0.129", mode: RoundDown, want: 12},
+		{input: "0.121", mode: RoundUp, want: 13},
+		{input: "1.5e-2", mode: RoundHalfUp, want: 2},
+		{input: "", wantErr: true},
+		{input: ".", wantErr: true},
+		{input: "1.2.3", wantErr: true},
+		{input: "12a", wantErr: true},
+		{input: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRounded(tt.input, tt.mode)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRounded(%q) = %s, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRounded(%q, %d) = %s, %v, want %s", tt.input, tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		m                      Money
+		numerator, denominator int64
+		mode                   RoundingMode
+		want                   Money
+	}{
+		{MustParse("10"), 1, 3, RoundHalfUp, MustParse("3.33")},
+		{MustParse("20"), 1, 3, RoundHalfUp, MustParse("6.67")},
+		{MustParse("0.05"), 1, 2, RoundHalfUp, MustParse("0.03")},
+		{MustParse("0.05"), 1, 2, RoundHalfEven, MustParse("0.02")},
+		{MustParse("0.07"), 1, 2, RoundHalfEven, MustParse("0.04")},
+		{MustParse("0.05"), 1, 2, RoundDown, MustParse("0.02")},
+		{MustParse("0.05"), 1, 2, RoundUp, MustParse("0.03")},
+		{MustParse("-0.05"), 1, 2, RoundHalfUp, MustParse("-0.03")},
+		{MustParse("-0.05"), 1, 2, RoundDown, MustParse("-0.02")},
+		{MustParse("10"), 1, 0, RoundHalfUp, Zero},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.MulRatio(tt.numerator, tt.denominator, tt.mode); got != tt.want {
+			t.Errorf("%s.MulRatio(%d, %d, %d) = %s, want %s", tt.m, tt.numerator, tt.denominator, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		m       Money
+		percent float64
+		mode    RoundingMode
+		want    Money
+	}{
+		{MustParse("100"), 11, RoundHalfUp, MustParse("11")},
+		{MustParse("0.05"), 11, RoundHalfUp, MustParse("0.01")},
+		{MustParse("123.45"), 10, RoundHalfUp, MustParse("12.35")},
+		{MustParse("123.45"), 10, RoundHalfEven, MustParse("12.34")},
+		{MustParse("1000"), 2.5, RoundHalfUp, MustParse("25")},
+		{MustParse("1000"), 0.0001, RoundHalfUp, MustParse("0")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.Percent(tt.percent, tt.mode); got != tt.want {
+			t.Errorf("%s.Percent(%v, %d) = %s, want %s", tt.m, tt.percent, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		m    Money
+		unit Money
+		mode RoundingMode
+		want Money
+	}{
+		{MustParse("12345"), IDRCashDenomination, RoundHalfUp, MustParse("12300")},
+		{MustParse("12350"), IDRCashDenomination, RoundHalfUp, MustParse("12400")},
+		{MustParse("-12350"), IDRCashDenomination, RoundHalfUp, MustParse("-12400")},
+		{MustParse("12250"), IDRCashDenomination, RoundHalfEven, MustParse("12200")},
+		{MustParse("12350"), IDRCashDenomination, RoundHalfEven, MustParse("12400")},
+		{MustParse("12301"), IDRCashDenomination, RoundUp, MustParse("12400")},
+		{MustParse("12399.99"), IDRCashDenomination, RoundDown, MustParse("12300")},
+		{MustParse("-12301"), IDRCashDenomination, RoundUp, MustParse("-12400")},
+		{MustParse("12300"), IDRCashDenomination, RoundUp, MustParse("12300")},
+		{MustParse("12345.67"), Zero, RoundHalfUp, MustParse("12345.67")},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.Round(tt.unit, tt.mode); got != tt.want {
+			t.Errorf("%s.Round(%s, %d) = %s, want %s", tt.m, tt.unit, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{-5, "-0.05"},
+		{150050, "1500.50"},
+		{-1250, "-12.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.m), got, tt.want)
+		}
+	}
+}